@@ -39,7 +39,7 @@ for platform in "${PLATFORMS[@]}"; do
     echo "Building $output_name..."
     
     CGO_ENABLED=0 GOOS=$GOOS GOARCH=$GOARCH go build \
-        -ldflags="-s -w -X main.version=$VERSION" \
+        -ldflags="-s -w -X rq/version.Version=$VERSION" \
         -o "$BUILD_DIR/$output_name" \
         .
 done
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"rq/dock"
+	"rq/request/network"
+	"strings"
+
+	"github.com/marcomit/args"
+)
+
+// ErrUnknownCommand is returned by the root action when the first word is
+// not a command.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Run runs a command line with app. The flags that apply to every command
+// (--no-color and --dock) are taken out first, since the parser only accepts
+// a flag on the command that declares it, and they only last for this run.
+func Run(app *args.Parser, arguments []string) error {
+	noColor, override := network.NoColor, dock.Override
+	defer func() {
+		network.NoColor, dock.Override = noColor, override
+	}()
+
+	rest, err := globalFlags(arguments)
+	if err != nil {
+		return err
+	}
+	return app.Run(rest)
+}
+
+func globalFlags(arguments []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		switch {
+		case arg == "--no-color":
+			network.NoColor = true
+		case arg == "--dock":
+			if i+1 == len(arguments) {
+				return nil, fmt.Errorf("--dock needs a path or a registered dock name")
+			}
+			dock.Override = arguments[i+1]
+			i++
+		case strings.HasPrefix(arg, "--dock="):
+			dock.Override = strings.TrimPrefix(arg, "--dock=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, nil
+}
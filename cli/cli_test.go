@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package cli
+
+import (
+	"rq/dock"
+	"rq/request/network"
+	"slices"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func TestRunNoColor(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []string
+		wantRest  []string
+		wantColor bool
+	}{
+		{name: "before the command", arguments: []string{"--no-color", "run", "users"}, wantRest: []string{"run", "users"}, wantColor: false},
+		{name: "after the command", arguments: []string{"run", "users", "--no-color", "--brief"}, wantRest: []string{"run", "users", "--brief"}, wantColor: false},
+		{name: "absent", arguments: []string{"run", "users"}, wantRest: []string{"run", "users"}, wantColor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rest []string
+			var noColor bool
+			app := args.New("rq")
+			app.Command("run", "").Positional("name").Flag("brief", "b", "").Action(func(r *args.Result) error {
+				rest = append([]string{"run"}, r.Positionals...)
+				if r.Flag("brief") {
+					rest = append(rest, "--brief")
+				}
+				noColor = network.NoColor
+				return nil
+			})
+
+			if err := Run(app, tt.arguments); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("command saw %q, want %q", rest, tt.wantRest)
+			}
+			if noColor == tt.wantColor {
+				t.Errorf("NoColor during the run = %v, want %v", noColor, !tt.wantColor)
+			}
+			if network.NoColor {
+				t.Error("NoColor should be restored after the run")
+			}
+		})
+	}
+}
+
+func TestRunDockFlag(t *testing.T) {
+	tests := []struct {
+		name         string
+		arguments    []string
+		wantRest     []string
+		wantOverride string
+		wantErr      string
+	}{
+		{name: "before the command", arguments: []string{"--dock", "/srv/shop", "run", "users"}, wantRest: []string{"run", "users"}, wantOverride: "/srv/shop"},
+		{name: "after the command", arguments: []string{"run", "users", "--dock=shop-api"}, wantRest: []string{"run", "users"}, wantOverride: "shop-api"},
+		{name: "with --no-color", arguments: []string{"--no-color", "--dock", "shop", "run", "users"}, wantRest: []string{"run", "users"}, wantOverride: "shop"},
+		{name: "absent", arguments: []string{"run", "users"}, wantRest: []string{"run", "users"}},
+		{name: "missing value", arguments: []string{"run", "users", "--dock"}, wantErr: "--dock needs a path or a registered dock name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rest []string
+			var override string
+			app := args.New("rq")
+			app.Command("run", "").Positional("name").Action(func(r *args.Result) error {
+				rest = append([]string{"run"}, r.Positionals...)
+				override = dock.Override
+				return nil
+			})
+
+			err := Run(app, tt.arguments)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("command saw %q, want %q", rest, tt.wantRest)
+			}
+			if override != tt.wantOverride {
+				t.Errorf("dock.Override during the run = %q, want %q", override, tt.wantOverride)
+			}
+			if dock.Override != "" {
+				t.Errorf("dock.Override = %q after the run, want it restored", dock.Override)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package directive
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Directive is an "@name args" line before the request line, such as
+// "@retry count=3" or "@set id = {{uuid()}}".
+type Directive struct {
+	Line int    // Line number, starting at 1
+	Name string // Name without the @
+	Args string // Rest of the line, trimmed
+}
+
+var pattern = regexp.MustCompile(`^\s*@([A-Za-z][A-Za-z0-9-]*)(?:\s+(.*?))?\s*$`)
+
+// Parse reads a directive line.
+func Parse(line string) (Directive, bool) {
+	match := pattern.FindStringSubmatch(line)
+	if match == nil {
+		return Directive{}, false
+	}
+	return Directive{Name: match[1], Args: match[2]}, true
+}
+
+// IsPreamble reports whether a line may precede the request line: a blank
+// line, a comment or a directive.
+func IsPreamble(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "///") {
+		return true
+	}
+	_, ok := Parse(line)
+	return ok
+}
+
+// Preamble returns the directives before the request line and the index of
+// the request line, which is len(lines) when there is none.
+func Preamble(lines []string) ([]Directive, int) {
+	var directives []Directive
+	for i, line := range lines {
+		if !IsPreamble(line) {
+			return directives, i
+		}
+		if d, ok := Parse(line); ok {
+			d.Line = i + 1
+			directives = append(directives, d)
+		}
+	}
+	return directives, len(lines)
+}
+
+// Extract blanks the directives called name in the preamble of content and
+// returns them in order. Blanking keeps the line numbers of the rest.
+func Extract(content, name string) (string, []Directive) {
+	lines := strings.Split(content, "\n")
+	directives, _ := Preamble(lines)
+
+	var found []Directive
+	for _, d := range directives {
+		if d.Name == name {
+			found = append(found, d)
+			lines[d.Line-1] = ""
+		}
+	}
+	return strings.Join(lines, "\n"), found
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type ArchiveOptions struct {
+	IncludeEnv     bool // Include .env files in the archive
+	ExcludeSecrets bool // Drop files that look like keys, certificates or local secrets
+}
+
+var secretFilePatterns = []string{
+	"*.pem", "*.key", "*.p12", "*.pfx", "*.local",
+	"id_rsa*", "*secret*", "*credential*",
+}
+
+func isEnvFile(name string) bool {
+	return name == ".env" || strings.HasPrefix(name, ".env.")
+}
+
+func isSecretFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range secretFilePatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isCache reports whether a path belongs to the .rq cache directory,
+// which is never archived except for the config schema and the manifest.
+func isCache(rel string, info os.FileInfo) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if parts[0] != ".rq" {
+		return false
+	}
+	if len(parts) == 1 {
+		return false
+	}
+	kept := len(parts) == 2 && parts[1] == "schema" || filepath.ToSlash(rel) == ManifestFile
+	return !(kept && !info.IsDir())
+}
+
+func ExportDock(dockPath, output string, options ArchiveOptions) (int, error) {
+	file, err := os.Create(output)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	outputPath, _ := filepath.Abs(output)
+	root := filepath.Base(dockPath)
+	count := 0
+
+	err = filepath.Walk(dockPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dockPath, path)
+		if err != nil {
+			return err
+		}
+
+		if isCache(rel, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if abs, _ := filepath.Abs(path); abs == outputPath {
+			return nil
+		}
+
+		if !info.IsDir() {
+			if isEnvFile(info.Name()) && !options.IncludeEnv {
+				return nil
+			}
+			if options.ExcludeSecrets && isSecretFile(info.Name()) {
+				return nil
+			}
+		}
+
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(root, rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		if _, err := io.Copy(tw, src); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+
+	// The trailers are written on close, so each close must succeed for the
+	// archive to be complete.
+	for _, closeErr := range []error{tw.Close(), gz.Close(), file.Close()} {
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		os.Remove(output)
+		return 0, fmt.Errorf("failed to archive dock: %w", err)
+	}
+
+	return count, nil
+}
+
+// ImportDock unpacks an archive created by ExportDock into destDir and
+// returns the path of the extracted dock. The dock directory is removed
+// again when the archive turns out to be invalid.
+func ImportDock(archive, destDir string) (_ string, err error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return "", fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	destDir, err = filepath.Abs(destDir)
+	if err != nil {
+		return "", err
+	}
+
+	tr := tar.NewReader(gz)
+	dockRoot := ""
+	created := false
+	defer func() {
+		if err != nil && created {
+			os.RemoveAll(dockRoot)
+		}
+	}()
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		name := path.Clean(filepath.ToSlash(header.Name))
+		top := strings.SplitN(name, "/", 2)[0]
+		if top == "." || top == ".." || top == "" || path.IsAbs(name) {
+			return "", fmt.Errorf("archive entry outside of the dock directory: %s", header.Name)
+		}
+
+		if dockRoot == "" {
+			dockRoot = filepath.Join(destDir, top)
+			if exists(dockRoot) {
+				return "", fmt.Errorf("directory '%s' already exists", dockRoot)
+			}
+			created = true
+		}
+
+		// Every entry must live under the single dock directory, so nothing
+		// is written next to it in destDir.
+		target := filepath.Join(destDir, filepath.FromSlash(name))
+		if target != dockRoot && !strings.HasPrefix(target, dockRoot+string(os.PathSeparator)) {
+			return "", fmt.Errorf("archive entry outside of the dock directory: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return "", err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return "", err
+			}
+			dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return "", err
+			}
+			if _, err := io.Copy(dst, tr); err != nil {
+				dst.Close()
+				return "", err
+			}
+			if err := dst.Close(); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if dockRoot == "" || !exists(filepath.Join(dockRoot, ".dock")) {
+		return "", fmt.Errorf("archive does not contain a valid dock (missing .dock file)")
+	}
+
+	return dockRoot, nil
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func listFiles(t *testing.T, root string) []string {
+	t.Helper()
+	var files []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ArchiveOptions
+		want    []string
+	}{
+		{
+			"default",
+			ArchiveOptions{},
+			[]string{".dock", ".rq/manifest", ".rq/schema", "README.md", "users/get.http", "users/server.pem"},
+		},
+		{
+			"with env",
+			ArchiveOptions{IncludeEnv: true},
+			[]string{".dock", ".env", ".env.dev", ".env.local", ".rq/manifest", ".rq/schema", "README.md", "users/get.http", "users/server.pem"},
+		},
+		{
+			"without secrets",
+			ArchiveOptions{IncludeEnv: true, ExcludeSecrets: true},
+			[]string{".dock", ".env", ".env.dev", ".rq/manifest", ".rq/schema", "README.md", "users/get.http"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockPath := filepath.Join(t.TempDir(), "shop")
+			writeFiles(t, dockPath, map[string]string{
+				".dock":                   "shop",
+				".env":                    "BASE_URL=http://localhost\n",
+				".env.dev":                "BASE_URL=http://dev\n",
+				".env.local":              "TOKEN=secret\n",
+				"README.md":               "# Shop\n",
+				"users/get.http":          "GET {{BASE_URL}}/users\n",
+				"users/server.pem":        "-----BEGIN-----\n",
+				".rq/schema":              "BASE_URL url required\n",
+				".rq/manifest":            "users/get\n",
+				".rq/snapshots/get.json":  "{}",
+				".rq/captures/local.json": "{}",
+			})
+
+			archive := filepath.Join(t.TempDir(), "shop.tar.gz")
+			count, err := ExportDock(dockPath, archive, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("exported %d files, want %d", count, len(tt.want))
+			}
+
+			dest := t.TempDir()
+			imported, err := ImportDock(archive, dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if imported != filepath.Join(dest, "shop") {
+				t.Errorf("imported to %s", imported)
+			}
+			if got := listFiles(t, imported); !slices.Equal(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+
+			content, _ := os.ReadFile(filepath.Join(imported, "users", "get.http"))
+			if string(content) != "GET {{BASE_URL}}/users\n" {
+				t.Errorf("get.http = %q", content)
+			}
+		})
+	}
+}
+
+func TestExportSkipsTheArchiveItself(t *testing.T) {
+	dockPath := filepath.Join(t.TempDir(), "shop")
+	writeFiles(t, dockPath, map[string]string{".dock": "shop"})
+
+	archive := filepath.Join(dockPath, "shop.tar.gz")
+	count, err := ExportDock(dockPath, archive, ArchiveOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("exported %d files, want only .dock", count)
+	}
+}
+
+type entry struct {
+	name    string
+	content string
+	dir     bool
+}
+
+func writeArchive(t *testing.T, entries []entry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dock.tar.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if e.dir {
+			header = &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e.content))
+	}
+	tw.Close()
+	gz.Close()
+	file.Close()
+	return path
+}
+
+func TestImportDockRejectsInvalidArchives(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		wantErr string
+	}{
+		{"parent escape", []entry{{name: "shop/.dock", content: "shop"}, {name: "shop/../evil", content: "x"}}, "outside of the dock directory"},
+		{"leading parent", []entry{{name: "../evil", content: "x"}}, "outside of the dock directory"},
+		{"absolute", []entry{{name: "/etc/evil", content: "x"}}, "outside of the dock directory"},
+		{"second root", []entry{{name: "shop/.dock", content: "shop"}, {name: "other/file", content: "x"}}, "outside of the dock directory"},
+		{"file as root", []entry{{name: "./.dock", content: "x"}}, "missing .dock file"},
+		{"no dock file", []entry{{name: "shop/", dir: true}, {name: "shop/get.http", content: "GET /"}}, "missing .dock file"},
+		{"empty", nil, "missing .dock file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := writeArchive(t, tt.entries)
+			dest := t.TempDir()
+
+			_, err := ImportDock(archive, dest)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+
+			if entries, _ := os.ReadDir(dest); len(entries) != 0 {
+				t.Errorf("import left %v behind", entries)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "evil")); err == nil {
+				t.Error("wrote outside of the destination")
+			}
+		})
+	}
+}
+
+func TestImportDockRefusesExistingDirectory(t *testing.T) {
+	archive := writeArchive(t, []entry{{name: "shop/.dock", content: "shop"}})
+	dest := t.TempDir()
+	writeFiles(t, dest, map[string]string{"shop/keep": "mine"})
+
+	if _, err := ImportDock(archive, dest); err == nil {
+		t.Fatal("expected an error")
+	}
+	if content, _ := os.ReadFile(filepath.Join(dest, "shop", "keep")); string(content) != "mine" {
+		t.Error("existing directory was touched")
+	}
+}
+
+func TestDockImportKeepsWorkspaceEntries(t *testing.T) {
+	t.Setenv(ConfigHomeEnv, t.TempDir())
+	root := t.TempDir()
+	existing := newDock(t, root, "shop")
+	if err := AddDock("shop", existing); err != nil {
+		t.Fatal(err)
+	}
+
+	source := newDock(t, t.TempDir(), "shop")
+	archive := filepath.Join(t.TempDir(), "shop.tar.gz")
+	if _, err := ExportDock(source, archive, ArchiveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dest     string
+		wantName string
+	}{
+		{dest: "first", wantName: "shop-2"},
+		{dest: "second", wantName: "shop-3"},
+	}
+
+	for _, tt := range tests {
+		app := args.New("rq")
+		Setup(app)
+		dest := filepath.Join(root, tt.dest)
+		if err := app.Run([]string{"dock", "import", archive, dest}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, _ := lookupWorkspace(tt.wantName); got != filepath.Join(dest, "shop") {
+			t.Errorf("%s = %q, want the dock imported into %s", tt.wantName, got, tt.dest)
+		}
+	}
+
+	if got, _ := lookupWorkspace("shop"); got != existing {
+		t.Errorf("shop = %q, want the dock registered before the imports", got)
+	}
+}
+
+func TestFreeDockName(t *testing.T) {
+	t.Setenv(ConfigHomeEnv, t.TempDir())
+	root := t.TempDir()
+	shop := newDock(t, root, "shop")
+	other := newDock(t, root, "other")
+	for name, path := range map[string]string{"shop": shop, "shop-2": other} {
+		if err := AddDock(name, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name, path string
+		want       string
+	}{
+		{name: "billing", path: other, want: "billing"},
+		{name: "shop", path: shop, want: "shop"},
+		{name: "shop", path: other, want: "shop-2"},
+		{name: "shop", path: filepath.Join(root, "new"), want: "shop-3"},
+	}
+
+	for _, tt := range tests {
+		got, err := freeDockName(tt.name, tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("freeDockName(%q, %s) = %q, want %q", tt.name, tt.path, got, tt.want)
+		}
+	}
+}
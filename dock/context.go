@@ -4,10 +4,14 @@
 package dock
 
 import (
+	"errors"
 	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -62,8 +66,54 @@ func (ctx *RqContext) GetDockRoot() (string, error) {
 	return res[0], nil
 }
 
+// enclosingDocks returns the dock roots that contain path, nearest first.
+func enclosingDocks(path string) []string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	return validatePath(abs, func(curr string) bool {
+		return exists(filepath.Join(curr, ".dock"))
+	})
+}
+
+// validKey matches the keys that can be referenced as {{KEY}}.
+var validKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LenientKeys disables key validation for existing files that rely on
+// keys outside the identifier pattern.
+var LenientKeys = false
+
 func loadConfig(path string) (map[string]string, error) {
+	return parseConfig(path, true)
+}
+
+// IncludeDirective pulls the keys of another config file into the one that
+// contains it: "@env-include ../common/shared.env". Paths are relative to the
+// including file, and the including file's own keys win over included ones.
+const IncludeDirective = "@env-include"
+
+var ErrIncludeCycle = errors.New("include cycle")
+
+// parseConfig reads a key=value file, decrypting enc: values when decrypt is set.
+func parseConfig(path string, decrypt bool) (map[string]string, error) {
+	return parseConfigIncluding(path, decrypt, true, nil)
+}
+
+// parseConfigIncluding reads a key=value file, following its includes when
+// follow is set and skipping them otherwise.
+func parseConfigIncluding(path string, decrypt, follow bool, stack []string) (map[string]string, error) {
 	res := make(map[string]string)
+	included := make(map[string]string)
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return res, err
+	}
+	if slices.Contains(stack, abs) {
+		return res, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(append(stack, abs), " -> "))
+	}
+	stack = append(stack, abs)
 
 	file, err := os.ReadFile(path)
 	if err != nil {
@@ -82,6 +132,32 @@ func loadConfig(path string) (map[string]string, error) {
 			continue
 		}
 
+		if target, ok := strings.CutPrefix(line, IncludeDirective); ok && (target == "" || target[0] == ' ' || target[0] == '\t') {
+			target = strings.TrimSpace(target)
+			if target == "" {
+				return res, fmt.Errorf("missing path after %s at line %d", IncludeDirective, lineNum+1)
+			}
+			if !follow {
+				continue
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(abs), target)
+			}
+			if !exists(target) {
+				return res, fmt.Errorf("included file not found at line %d: %s", lineNum+1, target)
+			}
+
+			values, err := parseConfigIncluding(target, decrypt, follow, stack)
+			if errors.Is(err, ErrIncludeCycle) {
+				return res, err
+			}
+			if err != nil {
+				return res, fmt.Errorf("in %s: %w", target, err)
+			}
+			maps.Copy(included, values)
+			continue
+		}
+
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
 			return res, fmt.Errorf("invalid format at line %d: missing '=' character", lineNum+1)
@@ -94,88 +170,222 @@ func loadConfig(path string) (map[string]string, error) {
 			return res, fmt.Errorf("empty key at line %d", lineNum+1)
 		}
 
+		if !LenientKeys && !validKey.MatchString(key) {
+			return res, fmt.Errorf("invalid key '%s' at line %d: use letters, digits and underscores, not starting with a digit", key, lineNum+1)
+		}
+
+		if decrypt && IsEncrypted(value) {
+			decrypted, err := DecryptValue(value)
+			if err != nil {
+				return res, fmt.Errorf("failed to decrypt %s at line %d: %w", key, lineNum+1, err)
+			}
+			value = decrypted
+		}
+
 		res[key] = value
 	}
 
-	return res, nil
+	maps.Copy(included, res)
+	return included, nil
 }
 
 func (ctx *RqContext) GetConfig(relpath string) (map[string]string, error) {
-	configs := make(map[string]string)
+	return ctx.GetConfigForEnv(relpath, "")
+}
 
-	rootConfigPath := filepath.Join(ctx.Dock, ".env")
-	rootConfig, err := loadConfig(rootConfigPath)
+// ErrNoDock is returned by GetContext outside of a dock.
+var ErrNoDock = errors.New("not inside an rq dock")
+
+func (ctx *RqContext) setDockRoot() error {
+	root, err := ctx.GetDockRoot()
 	if err != nil {
-		return configs, fmt.Errorf("failed to load root config: %w", err)
+		return fmt.Errorf("%w: no .dock file found in %s or its parents\nRun 'rq dock init <name>' to create a dock, or cd into an existing one", ErrNoDock, ctx.Path)
 	}
-	maps.Copy(configs, rootConfig)
+	ctx.Dock = root
+	return nil
+}
+
+// Override is the dock given with the global --dock flag, a path or a name
+// registered in the workspace. When set, GetContext uses it instead of
+// looking around the working directory.
+var Override string
 
-	if relpath == "" {
-		return configs, nil
+// GetContext locates the dock containing the working directory, or the
+// Override dock.
+func GetContext() (*RqContext, error) {
+	if Override != "" {
+		return overrideContext(Override)
 	}
 
-	currentPath := ctx.Dock
-	pathSegments := strings.Split(strings.Trim(relpath, string(os.PathSeparator)), string(os.PathSeparator))
+	path, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return ContextAt(path)
+}
 
-	for _, segment := range pathSegments {
-		if segment == "" {
-			continue
-		}
+// ContextAt locates the dock containing path, for callers that do not run
+// from inside the dock.
+func ContextAt(path string) (*RqContext, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
 
-		currentPath = filepath.Join(currentPath, segment)
-		configPath := filepath.Join(currentPath, ".env")
+	ctx := &RqContext{Path: filepath.Clean(path)}
+	if err := ctx.setDockRoot(); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
 
-		segmentConfig, err := loadConfig(configPath)
+func overrideContext(target string) (*RqContext, error) {
+	path := target
+	if !exists(path) {
+		registered, err := lookupWorkspace(target)
 		if err != nil {
-			if !os.IsNotExist(err) {
-				return configs, fmt.Errorf("failed to load config at %s: %w", configPath, err)
-			}
+			return nil, err
+		}
+		if registered == "" {
+			return nil, fmt.Errorf("--dock %s: no such directory or registered dock", target)
 		}
+		path = registered
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	if !exists(filepath.Join(abs, ".dock")) {
+		return nil, fmt.Errorf("--dock %s: not a dock (missing .dock file)", target)
+	}
 
-		maps.Copy(configs, segmentConfig)
+	abs = filepath.Clean(abs)
+	return &RqContext{Path: abs, Dock: abs}, nil
+}
+
+// GetConfigForEnv merges the config files returned by ConfigFiles, so an
+// environment-specific value (e.g. BASE_URL in .env.staging) always shadows
+// the plain .env ones and .env.local overrides win over everything.
+func (ctx *RqContext) GetConfigForEnv(relpath, env string) (map[string]string, error) {
+	configs := make(map[string]string)
+
+	for _, configPath := range ctx.ConfigFiles(relpath, env) {
+		config, err := loadConfig(configPath)
+		if err != nil {
+			return configs, fmt.Errorf("failed to load config at %s: %w", configPath, err)
+		}
+		maps.Copy(configs, config)
 	}
 
 	return configs, nil
 }
 
-func (ctx *RqContext) setDockRoot() {
-	root, err := ctx.GetDockRoot()
-	if err != nil {
-		fmt.Printf("Error: %s is not a valid RQ environment\n", ctx.Path)
-		os.Exit(1)
+// ConfigFiles lists, in merge order, the config files that may apply to
+// relpath: .env from the dock root down, then .env.<env>, then the
+// gitignored .env.local and .env.<env>.local overrides. Missing files are
+// included and simply contribute nothing.
+func (ctx *RqContext) ConfigFiles(relpath, env string) []string {
+	env = ctx.ResolveEnv(relpath, env)
+	if env == "local" {
+		env = ""
 	}
-	ctx.Dock = root
+
+	names := []string{".env"}
+	if env != "" {
+		names = append(names, ".env."+env)
+	}
+	names = append(names, ".env.local")
+	if env != "" {
+		names = append(names, ".env."+env+".local")
+	}
+
+	dirs := ctx.configDirs(relpath)
+	var files []string
+	for _, name := range names {
+		for _, dir := range dirs {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files
 }
 
-func GetContext() *RqContext {
-	path, err := os.Getwd()
+// ResolveEnv maps an environment alias to its real name through the
+// ENV_ALIAS_<alias>=<name> keys of the config. Other names are returned as is.
+func (ctx *RqContext) ResolveEnv(relpath, env string) string {
+	if env == "" {
+		return env
+	}
+
+	config, err := ctx.GetConfig(relpath)
 	if err != nil {
-		fmt.Printf("Error: failed to get current directory: %v\n", err)
-		os.Exit(1)
+		return env
+	}
+	if target := strings.TrimSpace(config[EnvAliasPrefix+env]); target != "" {
+		return target
 	}
+	return env
+}
 
-	path = filepath.Clean(path)
-	ctx := &RqContext{Path: path, Dock: ""}
-	ctx.setDockRoot()
+// configDirs lists the directories whose config applies to relpath, from the
+// dock root down.
+func (ctx *RqContext) configDirs(relpath string) []string {
+	currentPath := ctx.Dock
+	dirs := []string{currentPath}
+	for _, segment := range strings.Split(strings.Trim(relpath, string(os.PathSeparator)), string(os.PathSeparator)) {
+		if segment == "" || segment == "." {
+			continue
+		}
+		currentPath = filepath.Join(currentPath, segment)
+		dirs = append(dirs, currentPath)
+	}
+	return dirs
+}
 
-	return ctx
+type ConfigSource struct {
+	Path string
+	Keys []string // Keys defined by this file, sorted
 }
-func (ctx *RqContext) GetConfigForEnv(relpath, env string) (map[string]string, error) {
-	configs := make(map[string]string)
 
-	baseConfig, err := ctx.GetConfig(relpath)
+// GetConfigSources returns the config files that exist for relpath and env,
+// in the order GetConfigForEnv merges them.
+func (ctx *RqContext) GetConfigSources(relpath, env string) ([]ConfigSource, error) {
+	paths := ctx.ConfigFiles(relpath, env)
+
+	var sources []ConfigSource
+	for _, path := range paths {
+		if !exists(path) {
+			continue
+		}
+		values, err := LoadRawConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source := ConfigSource{Path: path}
+		for key := range values {
+			source.Keys = append(source.Keys, key)
+		}
+		sort.Strings(source.Keys)
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// GetConfigProvenance maps every key of the merged config for relpath and env
+// to the file its final value came from.
+func (ctx *RqContext) GetConfigProvenance(relpath, env string) (map[string]string, error) {
+	sources, err := ctx.GetConfigSources(relpath, env)
 	if err != nil {
-		return configs, err
+		return nil, err
 	}
-	maps.Copy(configs, baseConfig)
-	if env != "" {
-		envConfigPath := filepath.Join(ctx.Dock, relpath, ".env."+env)
-		envConfig, err := loadConfig(envConfigPath)
-		if err != nil && !os.IsNotExist(err) {
-			return configs, fmt.Errorf("failed to load environment config %s: %w", envConfigPath, err)
+
+	provenance := make(map[string]string)
+	for _, source := range sources {
+		for _, key := range source.Keys {
+			provenance[key] = source.Path
 		}
-		maps.Copy(configs, envConfig)
 	}
-
-	return configs, nil
+	return provenance, nil
 }
@@ -0,0 +1,419 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"errors"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetConfigProvenance(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".dock":               "shop",
+		".env":                "A=root\nB=root\nC=root\nD=root\n",
+		".env.staging":        "C=staging\n",
+		".env.local":          "E=local\n",
+		"users/.env":          "B=users\nC=users\n",
+		"users/.env.staging":  "D=users-staging\n",
+		"orders/.env.staging": "A=orders\n",
+	})
+	ctx, err := ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		relpath string
+		env     string
+		want    map[string]string // Key to the file, relative to the dock, and its value
+	}{
+		{
+			name:    "root",
+			relpath: ".",
+			want:    map[string]string{"A": ".env=root", "B": ".env=root", "C": ".env=root", "D": ".env=root", "E": ".env.local=local"},
+		},
+		{
+			name:    "subfolder",
+			relpath: "users",
+			want:    map[string]string{"A": ".env=root", "B": "users/.env=users", "C": "users/.env=users", "D": ".env=root", "E": ".env.local=local"},
+		},
+		{
+			name:    "environment over subfolder",
+			relpath: "users",
+			env:     "staging",
+			want: map[string]string{
+				"A": ".env=root",
+				"B": "users/.env=users",
+				"C": ".env.staging=staging",
+				"D": "users/.env.staging=users-staging",
+				"E": ".env.local=local",
+			},
+		},
+		{
+			name:    "other subfolder is ignored",
+			relpath: ".",
+			env:     "staging",
+			want:    map[string]string{"A": ".env=root", "B": ".env=root", "C": ".env.staging=staging", "D": ".env=root", "E": ".env.local=local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provenance, err := ctx.GetConfigProvenance(tt.relpath, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, err := ctx.GetConfigForEnv(tt.relpath, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]string)
+			for key, path := range provenance {
+				rel, _ := filepath.Rel(root, path)
+				got[key] = filepath.ToSlash(rel) + "=" + config[key]
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("provenance = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalOverrides(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".dock":                    "shop",
+		".env":                     "A=root\nB=root\nC=root\nD=root\n",
+		".env.staging":             "A=staging\nB=staging\nC=staging\n",
+		".env.local":               "A=local\nD=local\n",
+		".env.staging.local":       "A=staging-local\nB=staging-local\n",
+		"users/.env":               "C=users\nD=users\n",
+		"users/.env.local":         "C=users-local\n",
+		"users/.env.staging.local": "E=users-staging-local\n",
+	})
+	ctx, err := ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		relpath string
+		env     string
+		want    map[string]string
+	}{
+		{
+			name:    "local over root",
+			relpath: ".",
+			want:    map[string]string{"A": "local", "B": "root", "C": "root", "D": "local"},
+		},
+		{
+			name:    "local over environment",
+			relpath: ".",
+			env:     "staging",
+			want:    map[string]string{"A": "staging-local", "B": "staging-local", "C": "staging", "D": "local"},
+		},
+		{
+			name:    "local over subfolder",
+			relpath: "users",
+			want:    map[string]string{"A": "local", "B": "root", "C": "users-local", "D": "local"},
+		},
+		{
+			name:    "every level",
+			relpath: "users",
+			env:     "staging",
+			want:    map[string]string{"A": "staging-local", "B": "staging-local", "C": "users-local", "D": "local", "E": "users-staging-local"},
+		},
+		{
+			name:    "local is not an environment",
+			relpath: ".",
+			env:     "local",
+			want:    map[string]string{"A": "local", "B": "root", "C": "root", "D": "local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ctx.GetConfigForEnv(tt.relpath, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("GetConfigForEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lenient bool
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "valid keys",
+			content: "# comment\nBASE_URL=http://x\n_private=1\napiKey2 = abc\nEMPTY=\nURL=http://x?a=b\n",
+			want:    map[string]string{"BASE_URL": "http://x", "_private": "1", "apiKey2": "abc", "EMPTY": "", "URL": "http://x?a=b"},
+		},
+		{name: "space in key", content: "A=1\nAPI KEY=abc\n", wantErr: "invalid key 'API KEY' at line 2"},
+		{name: "leading digit", content: "2FA=on\n", wantErr: "invalid key '2FA' at line 1"},
+		{name: "symbol", content: "\n\napi-key=abc\n", wantErr: "invalid key 'api-key' at line 3"},
+		{name: "empty key", content: "=abc\n", wantErr: "empty key at line 1"},
+		{name: "missing equals", content: "A=1\nJUSTTEXT\n", wantErr: "invalid format at line 2: missing '=' character"},
+		{
+			name:    "lenient accepts odd keys",
+			content: "API KEY=abc\napi-key=def\n",
+			lenient: true,
+			want:    map[string]string{"API KEY": "abc", "api-key": "def"},
+		},
+		{name: "lenient still needs a key", content: "=abc\n", lenient: true, wantErr: "empty key at line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(original bool) { LenientKeys = original }(LenientKeys)
+			LenientKeys = tt.lenient
+
+			path := filepath.Join(t.TempDir(), ".env")
+			writeFiles(t, filepath.Dir(path), map[string]string{".env": tt.content})
+
+			got, err := LoadConfigFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfigFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("LoadConfigFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvAliases(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".dock":            "shop",
+		".env":             "ENV_ALIAS_prod=production\nENV_ALIAS_blank= \nBASE_URL=http://localhost\n",
+		".env.production":  "BASE_URL=https://api.shop.test\n",
+		".env.staging":     "BASE_URL=https://staging.shop.test\n",
+		"users/.env":       "ENV_ALIAS_stg=staging\n",
+		"orders/.env.prod": "BASE_URL=https://never.test\n",
+	})
+	ctx, err := ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		relpath string
+		env     string
+		want    string
+		wantURL string
+	}{
+		{name: "alias", relpath: ".", env: "prod", want: "production", wantURL: "https://api.shop.test"},
+		{name: "real name", relpath: ".", env: "production", want: "production", wantURL: "https://api.shop.test"},
+		{name: "unknown name is literal", relpath: ".", env: "staging", want: "staging", wantURL: "https://staging.shop.test"},
+		{name: "empty alias is ignored", relpath: ".", env: "blank", want: "blank", wantURL: "http://localhost"},
+		{name: "alias from a subfolder", relpath: "users", env: "stg", want: "staging", wantURL: "https://staging.shop.test"},
+		{name: "subfolder alias does not leak", relpath: ".", env: "stg", want: "stg", wantURL: "http://localhost"},
+		{name: "alias wins over a file with its name", relpath: "orders", env: "prod", want: "production", wantURL: "https://api.shop.test"},
+		{name: "no environment", relpath: ".", want: "", wantURL: "http://localhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ctx.ResolveEnv(tt.relpath, tt.env); got != tt.want {
+				t.Errorf("ResolveEnv(%q) = %q, want %q", tt.env, got, tt.want)
+			}
+
+			config, err := ctx.GetConfigForEnv(tt.relpath, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config["BASE_URL"] != tt.wantURL {
+				t.Errorf("BASE_URL = %q, want %q", config["BASE_URL"], tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestGetContext(t *testing.T) {
+	base := t.TempDir()
+	writeFiles(t, base, map[string]string{
+		"shop/.dock":          "shop",
+		"shop/users/.gitkeep": "",
+		"outside/.gitkeep":    "",
+	})
+	root := filepath.Join(base, "shop")
+	t.Setenv(ConfigHomeEnv, t.TempDir())
+	if err := AddDock("shop-api", root); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		dir      string
+		override string
+		wantDock string
+		wantPath string
+		wantErr  string
+	}{
+		{name: "dock root", dir: "shop", wantDock: root, wantPath: root},
+		{name: "subfolder", dir: "shop/users", wantDock: root, wantPath: filepath.Join(root, "users")},
+		{name: "outside a dock", dir: "outside", wantErr: "not inside an rq dock"},
+		{name: "override", dir: "outside", override: root, wantDock: root, wantPath: root},
+		{name: "override is not a dock", dir: "shop", override: filepath.Join(base, "outside"), wantErr: "not a dock (missing .dock file)"},
+		{name: "relative override", dir: "outside", override: "../shop", wantDock: root, wantPath: root},
+		{name: "registered name", dir: "outside", override: "shop-api", wantDock: root, wantPath: root},
+		{name: "unknown override", dir: "outside", override: "billing", wantErr: "--dock billing: no such directory or registered dock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(filepath.Join(base, tt.dir))
+			defer func(original string) { Override = original }(Override)
+			Override = tt.override
+
+			ctx, err := GetContext()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetContext() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ctx.Dock != tt.wantDock || ctx.Path != tt.wantPath {
+				t.Errorf("GetContext() = {Dock: %s, Path: %s}, want {Dock: %s, Path: %s}", ctx.Dock, ctx.Path, tt.wantDock, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestContextAtOutsideDock(t *testing.T) {
+	dir := t.TempDir()
+	_, err := ContextAt(dir)
+	if !errors.Is(err, ErrNoDock) {
+		t.Fatalf("ContextAt() error = %v, want ErrNoDock", err)
+	}
+	if !strings.Contains(err.Error(), dir) || !strings.Contains(err.Error(), "rq dock init") {
+		t.Errorf("error %q should name the directory and suggest 'rq dock init'", err)
+	}
+}
+
+func TestEnvInclude(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string // Relative to a directory holding the dock "shop"
+		want    map[string]string
+		wantErr string
+		cycle   bool
+	}{
+		{
+			name: "shared fragment",
+			files: map[string]string{
+				"common/shared.env": "API_KEY=shared\nREGION=eu\n",
+				"shop/.env":         "@env-include ../common/shared.env\nBASE_URL=http://x\n",
+			},
+			want: map[string]string{"API_KEY": "shared", "REGION": "eu", "BASE_URL": "http://x"},
+		},
+		{
+			name: "own keys win wherever the include is",
+			files: map[string]string{
+				"common/shared.env": "REGION=eu\nTEAM=core\n",
+				"shop/.env":         "REGION=us\n@env-include ../common/shared.env\n",
+			},
+			want: map[string]string{"REGION": "us", "TEAM": "core"},
+		},
+		{
+			name: "nested and diamond includes",
+			files: map[string]string{
+				"common/base.env":  "A=base\nB=base\n",
+				"common/left.env":  "@env-include base.env\nB=left\n",
+				"common/right.env": "@env-include base.env\nC=right\n",
+				"shop/.env":        "@env-include ../common/left.env\n@env-include ../common/right.env\n",
+			},
+			want: map[string]string{"A": "base", "B": "base", "C": "right"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"common/a.env": "@env-include b.env\n",
+				"common/b.env": "@env-include a.env\n",
+				"shop/.env":    "@env-include ../common/a.env\n",
+			},
+			cycle:   true,
+			wantErr: filepath.Join("common", "a.env") + " -> ",
+		},
+		{
+			name:    "self include",
+			files:   map[string]string{"shop/.env": "@env-include .env\n"},
+			cycle:   true,
+			wantErr: "include cycle",
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{"shop/.env": "A=1\n@env-include ../nope.env\n"},
+			wantErr: "included file not found at line 2",
+		},
+		{
+			name:    "missing path",
+			files:   map[string]string{"shop/.env": "@env-include\n"},
+			wantErr: "missing path after @env-include at line 1",
+		},
+		{
+			name: "error inside the fragment",
+			files: map[string]string{
+				"common/broken.env": "NOT A PAIR\n",
+				"shop/.env":         "@env-include ../common/broken.env\n",
+			},
+			wantErr: "invalid format at line 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			tt.files["shop/.dock"] = "shop"
+			writeFiles(t, base, tt.files)
+			ctx, err := ContextAt(filepath.Join(base, "shop"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ctx.GetConfig(".")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				if errors.Is(err, ErrIncludeCycle) != tt.cycle {
+					t.Errorf("errors.Is(err, ErrIncludeCycle) = %v, want %v", !tt.cycle, tt.cycle)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("GetConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultEnvKey     = "DEFAULT_ENV"
+	DefaultTimeoutKey = "DEFAULT_TIMEOUT"
+
+	// EnvAliasPrefix declares environment aliases: ENV_ALIAS_prod=production
+	// makes --env prod load .env.production.
+	EnvAliasPrefix = "ENV_ALIAS_"
+)
+
+const FallbackTimeout = 30 * time.Second
+
+// DefaultTimeout reads DEFAULT_TIMEOUT from the config, either as seconds
+// ("10") or as a Go duration ("1m30s").
+func DefaultTimeout(config map[string]string) (time.Duration, error) {
+	value, ok := config[DefaultTimeoutKey]
+	if !ok || value == "" {
+		return FallbackTimeout, nil
+	}
+
+	timeout, err := ParseTimeout(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", DefaultTimeoutKey, err)
+	}
+	return timeout, nil
+}
+
+// ParseTimeout accepts a bare number of seconds ("30") for backward
+// compatibility, or a Go duration ("500ms", "1m30s").
+func ParseTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+		return duration, nil
+	}
+
+	return 0, fmt.Errorf("'%s' is not a positive number of seconds or a duration like 500ms or 1m30s", value)
+}
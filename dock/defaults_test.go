@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "10", want: 10 * time.Second},
+		{value: " 5 ", want: 5 * time.Second},
+		{value: "500ms", want: 500 * time.Millisecond},
+		{value: "1m30s", want: 90 * time.Second},
+		{value: "0", wantErr: true},
+		{value: "-3", wantErr: true},
+		{value: "-1s", wantErr: true},
+		{value: "soon", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTimeout(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeout(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTimeout(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset", config: map[string]string{}, want: FallbackTimeout},
+		{name: "empty", config: map[string]string{DefaultTimeoutKey: ""}, want: FallbackTimeout},
+		{name: "seconds", config: map[string]string{DefaultTimeoutKey: "10"}, want: 10 * time.Second},
+		{name: "invalid", config: map[string]string{DefaultTimeoutKey: "ten"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DefaultTimeout(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DefaultTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
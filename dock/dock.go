@@ -17,114 +17,181 @@ func Setup(app *args.Parser) {
 	dock := app.Command("dock", "Dock command")
 
 	dock.Command("init", "Initialize an rq dock").Positional("name").
+		Flag("no-gitignore", "ng", "Do not generate a .gitignore").
+		Flag("ignore-env", "ie", "Also ignore environment-specific .env.* files").
+		Flag("nested", "n", "Allow creating the dock inside another dock").
 		Action(func(r *args.Result) error {
 			if len(r.Positionals) == 0 {
 				return errors.New("Expected one positional argument")
 			}
-			CreateDock(r.Positionals[0])
+			return CreateDock(r.Positionals[0], InitOptions{
+				NoGitignore: r.Flag("no-gitignore"),
+				IgnoreEnv:   r.Flag("ignore-env"),
+				Nested:      r.Flag("nested"),
+			})
+		})
+
+	dock.Command("add", "Register a dock in the workspace under a friendly name").
+		Positional("name").
+		Positional("path").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) < 2 {
+				return errors.New("Expected a name and a path")
+			}
+			if err := AddDock(r.Positionals[0], r.Positionals[1]); err != nil {
+				return err
+			}
+			fmt.Printf("Registered dock '%s'\n", r.Positionals[0])
 			return nil
 		})
 
+	dock.Command("ls", "Lists the docks registered in the workspace").
+		Action(func(r *args.Result) error {
+			return ListWorkspace()
+		})
+
 	dock.Command("use", "Change the active dock").Positional("name").
 		Action(func(r *args.Result) error {
 			if len(r.Positionals) == 0 {
 				return errors.New("Expected one positional argument")
 			}
-			SetCurrentDock(r.Positionals[0])
-			return nil
+			return SetCurrentDock(r.Positionals[0])
 		})
 
 	dock.Command("list", "Lists all rq docks").
 		Action(func(r *args.Result) error {
-			List()
-			return nil
+			return List()
 		})
 
 	dock.Command("status", "Check the status of the dock").
 		Action(func(r *args.Result) error {
-			ShowStatus()
+			return ShowStatus()
+		})
+
+	dock.Command("export", "Bundle the current dock into a .tar.gz archive").
+		Positional("archive").
+		Flag("include-env", "ie", "Include .env files in the archive").
+		Flag("exclude-secrets", "es", "Skip files that look like keys, certificates or local secrets").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Expected one positional argument")
+			}
+
+			ctx, err := GetContext()
+			if err != nil {
+				return err
+			}
+			options := ArchiveOptions{
+				IncludeEnv:     r.Flag("include-env"),
+				ExcludeSecrets: r.Flag("exclude-secrets"),
+			}
+
+			count, err := ExportDock(ctx.Dock, r.Positionals[0], options)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Exported %d files to %s\n", count, r.Positionals[0])
 			return nil
 		})
 
+	dock.Command("import", "Unpack a dock archive and make it the active dock").
+		Positional("archive").
+		Positional("dir").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Expected one positional argument")
+			}
+
+			dest := "."
+			if len(r.Positionals) > 1 {
+				dest = r.Positionals[1]
+			}
+
+			dockPath, err := ImportDock(r.Positionals[0], dest)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Imported dock to %s\n", dockPath)
+			name, err := freeDockName(dockName(dockPath), dockPath)
+			if err != nil {
+				return err
+			}
+			if err := AddDock(name, dockPath); err != nil {
+				return err
+			}
+			fmt.Printf("Added to the workspace as %s\n", name)
+			return SetCurrentDock(dockPath)
+		})
+
 }
 
-func SetCurrentDock(name string) {
-	if _, err := os.Stat(name); os.IsNotExist(err) {
-		fmt.Printf("Error: dock '%s' does not exist\n", name)
-		os.Exit(1)
+func SetCurrentDock(name string) error {
+	label := name
+	if path, err := lookupWorkspace(name); err == nil && path != "" {
+		name = path
 	}
 
-	dockFile := filepath.Join(name, ".dock")
-	if _, err := os.Stat(dockFile); os.IsNotExist(err) {
-		fmt.Printf("Error: '%s' is not a valid dock (missing .dock file)\n", name)
-		os.Exit(1)
+	if _, err := os.Stat(name); os.IsNotExist(err) {
+		return fmt.Errorf("dock '%s' does not exist", name)
 	}
 
-	dir, err := os.UserConfigDir()
-	if err != nil {
-		fmt.Printf("Error: failed to get config directory: %v\n", err)
-		os.Exit(1)
+	if !exists(filepath.Join(name, ".dock")) {
+		return fmt.Errorf("'%s' is not a valid dock (missing .dock file)", name)
 	}
 
-	configDir := filepath.Join(dir, "rq")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		fmt.Printf("Error: failed to create config directory: %v\n", err)
-		os.Exit(1)
+	configDir, err := ConfigDir()
+	if err != nil {
+		return err
 	}
 
-	configFile := filepath.Join(configDir, "current_dock")
 	absPath, err := filepath.Abs(name)
 	if err != nil {
-		fmt.Printf("Error: failed to get absolute path: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
+	configFile := filepath.Join(configDir, "current_dock")
 	if err := os.WriteFile(configFile, []byte(absPath), 0644); err != nil {
-		fmt.Printf("Error: failed to set current dock: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to set current dock: %w", err)
 	}
 
-	fmt.Printf("Switched to dock: %s\n", name)
+	fmt.Printf("Switched to dock: %s\n", label)
+	return nil
 }
 
-func CreateDock(name string) {
-	fmt.Printf("Creating dock '%s'...\n", name)
+type InitOptions struct {
+	NoGitignore bool // Skip generating the .gitignore
+	IgnoreEnv   bool // Ignore .env.* files in addition to caches and local overrides
+	Nested      bool // Allow the dock to live inside another dock
+}
 
+func CreateDock(name string, options InitOptions) error {
 	if _, err := os.Stat(name); err == nil {
-		fmt.Printf("Error: directory '%s' already exists\n", name)
-		os.Exit(1)
+		return fmt.Errorf("directory '%s' already exists", name)
 	}
 
-	if err := os.Mkdir(name, 0755); err != nil {
-		fmt.Printf("Error: failed to create dock directory: %v\n", err)
-		os.Exit(1)
+	if parents := enclosingDocks(filepath.Dir(name)); len(parents) > 0 && !options.Nested {
+		return fmt.Errorf("'%s' would be nested inside the dock at %s\nRequests there would resolve to the inner dock; pass --nested if this is intended", name, parents[0])
 	}
 
-	dockFile := filepath.Join(name, ".dock")
-	dock, err := os.Create(dockFile)
-	if err != nil {
-		fmt.Printf("Error: failed to create .dock file: %v\n", err)
-		os.RemoveAll(name)
-		os.Exit(1)
-	}
-	defer dock.Close()
+	fmt.Printf("Creating dock '%s'...\n", name)
 
-	if _, err := dock.WriteString(name); err != nil {
-		fmt.Printf("Error: failed to write dock name: %v\n", err)
-		os.RemoveAll(name)
-		os.Exit(1)
+	if err := os.Mkdir(name, 0755); err != nil {
+		return fmt.Errorf("failed to create dock directory: %w", err)
 	}
 
-	envFile := filepath.Join(name, ".env")
-	env, err := os.Create(envFile)
-	if err != nil {
-		fmt.Printf("Error: failed to create environment file: %v\n", err)
+	if err := populateDock(name, options); err != nil {
 		os.RemoveAll(name)
-		os.Exit(1)
+		return err
 	}
-	defer env.Close()
 
-	defaultEnv := `# RQ Environment Configuration
+	fmt.Printf("Successfully created dock '%s'\n", name)
+	fmt.Println("Edit the .env file to configure your environment variables")
+	return nil
+}
+
+const defaultEnv = `# RQ Environment Configuration
 # Base URL for your API
 BASE_URL=https://api.example.com
 
@@ -136,28 +203,60 @@ HTTP_VERSION=HTTP/1.1
 # JWT_TOKEN=your_jwt_token_here
 `
 
-	if _, err := env.WriteString(defaultEnv); err != nil {
-		fmt.Printf("Error: failed to write default environment: %v\n", err)
-		os.RemoveAll(name)
-		os.Exit(1)
+func populateDock(dir string, options InitOptions) error {
+	if err := os.WriteFile(filepath.Join(dir, ".dock"), []byte(dir), 0644); err != nil {
+		return fmt.Errorf("failed to create .dock file: %w", err)
 	}
 
-	fmt.Printf("Successfully created dock '%s'\n", name)
-	fmt.Println("Edit the .env file to configure your environment variables")
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(defaultEnv), 0644); err != nil {
+		return fmt.Errorf("failed to create environment file: %w", err)
+	}
+
+	if !options.NoGitignore {
+		if err := writeGitignore(dir, options.IgnoreEnv); err != nil {
+			return fmt.Errorf("failed to create .gitignore: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func writeGitignore(dir string, ignoreEnv bool) error {
+	path := filepath.Join(dir, ".gitignore")
+	if exists(path) {
+		return nil
+	}
+
+	content := `# rq caches (recorded responses), keeping the config schema and manifest
+.rq/*
+!.rq/schema
+!.rq/manifest
+
+# Local overrides and secrets
+*.local
+`
+
+	if ignoreEnv {
+		content += `
+# Environment-specific configuration
+.env.*
+`
+	}
+
+	return os.WriteFile(path, []byte(content), 0644)
 }
 
-func List() {
+func List() error {
 	wd, err := os.Getwd()
 	if err != nil {
-		fmt.Printf("Error: failed to get working directory: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to get working directory: %w", err)
 	}
 
 	docks := findDocks(wd)
 
 	if len(docks) == 0 {
 		fmt.Println("No docks found in current directory and subdirectories")
-		return
+		return nil
 	}
 
 	fmt.Println("Available docks:")
@@ -176,6 +275,7 @@ func List() {
 
 		fmt.Printf("  %s (%s)\n", name, dock)
 	}
+	return nil
 }
 
 func findDocks(root string) []string {
@@ -205,31 +305,16 @@ func findDocks(root string) []string {
 	return docks
 }
 
-func ShowStatus() {
-	wd, err := os.Getwd()
+func ShowStatus() error {
+	ctx, err := GetContext()
 	if err != nil {
-		fmt.Printf("Error: failed to get working directory: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	ctx := &RqContext{Path: wd}
-
-	if !ctx.IsValidDock() {
-		fmt.Printf("Current directory is not a valid dock: %s\n", wd)
-		fmt.Println("Run 'rq dock init <name>' to create a new dock")
-		return
-	}
-
-	root, err := ctx.GetDockRoot()
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
-	}
-	dockFile := filepath.Join(root, ".dock")
-	content, err := os.ReadFile(dockFile)
+	wd, root := ctx.Path, ctx.Dock
+	content, err := os.ReadFile(filepath.Join(root, ".dock"))
 	if err != nil {
-		fmt.Printf("Error reading dock file: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to read dock file: %w", err)
 	}
 
 	name := strings.TrimSpace(string(content))
@@ -241,18 +326,30 @@ func ShowStatus() {
 	fmt.Printf("Dock path: %s\n", root)
 	fmt.Printf("Working directory: %s\n", wd)
 
+	if parents := enclosingDocks(filepath.Dir(root)); len(parents) > 0 {
+		fmt.Printf("Warning: this dock is nested inside the dock at %s\n", parents[0])
+	}
+	if nested := findDocks(root); len(nested) > 0 {
+		fmt.Println("Warning: this dock contains nested docks, whose requests resolve to the inner dock:")
+		for _, dock := range nested {
+			relPath, _ := filepath.Rel(root, dock)
+			fmt.Printf("  %s\n", relPath)
+		}
+	}
+
 	requests := findRequests(wd)
 	if len(requests) > 0 {
 		fmt.Println("Available requests:")
 		for _, req := range requests {
 			relPath, _ := filepath.Rel(root, req)
-			reqName := strings.TrimSuffix(filepath.Base(req), ".http")
+			reqName := strings.TrimSuffix(filepath.Base(req), filepath.Ext(req))
 			fmt.Printf("  %s (%s)\n", reqName, relPath)
 		}
 	} else {
 		fmt.Println("No requests found")
 		fmt.Println("Run 'rq new <name>' to create a new request")
 	}
+	return nil
 }
 
 func findRequests(root string) []string {
@@ -263,7 +360,7 @@ func findRequests(root string) []string {
 			return nil
 		}
 
-		if !info.IsDir() && strings.HasSuffix(path, ".http") {
+		if !info.IsDir() && IsRequestFile(path) {
 			requests = append(requests, path)
 		}
 
@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCreateDockGitignore(t *testing.T) {
+	tests := []struct {
+		name    string
+		options InitOptions
+		want    []string
+		notWant []string
+	}{
+		{"default", InitOptions{}, []string{".rq/*", "!.rq/schema", "!.rq/manifest", "*.local"}, []string{".env.*"}},
+		{"ignore env", InitOptions{IgnoreEnv: true}, []string{".rq/*", "*.local", ".env.*"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "shop")
+			if err := CreateDock(path, tt.options); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(path, ".gitignore"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(string(content), "\n")
+			for _, want := range tt.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf(".gitignore missing %q:\n%s", want, content)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if slices.Contains(lines, notWant) {
+					t.Errorf(".gitignore has %q:\n%s", notWant, content)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateDockWithoutGitignore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shop")
+	if err := CreateDock(path, InitOptions{NoGitignore: true}); err != nil {
+		t.Fatal(err)
+	}
+	if exists(filepath.Join(path, ".gitignore")) {
+		t.Error(".gitignore was written")
+	}
+	for _, file := range []string{".dock", ".env"} {
+		if !exists(filepath.Join(path, file)) {
+			t.Errorf("%s is missing", file)
+		}
+	}
+}
+
+func TestWriteGitignoreKeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".gitignore")
+	os.WriteFile(path, []byte("mine\n"), 0644)
+
+	if err := writeGitignore(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "mine\n" {
+		t.Errorf(".gitignore = %q", content)
+	}
+}
+
+func TestCreateDockRefusesExistingDirectory(t *testing.T) {
+	path := t.TempDir()
+	if err := CreateDock(path, InitOptions{}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestCreateDockNested(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string // Relative to a directory holding the dock "outer"
+		nested  bool
+		wantErr bool
+	}{
+		{name: "beside the dock", path: "sibling"},
+		{name: "inside the dock", path: "outer/inner", wantErr: true},
+		{name: "deep inside the dock", path: "outer/api/v2/inner", wantErr: true},
+		{name: "inside with --nested", path: "outer/inner", nested: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			outer := newDock(t, root, "outer")
+			path := filepath.Join(root, filepath.FromSlash(tt.path))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := CreateDock(path, InitOptions{Nested: tt.nested})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "nested inside the dock at "+outer) {
+					t.Fatalf("CreateDock() error = %v, want a nesting error", err)
+				}
+				if exists(path) {
+					t.Error("refused dock was created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !exists(filepath.Join(path, ".dock")) {
+				t.Error(".dock is missing")
+			}
+		})
+	}
+}
+
+func TestFindNestedDocks(t *testing.T) {
+	root := t.TempDir()
+	outer := newDock(t, root, "outer")
+	inner := newDock(t, outer, "inner")
+	deepest := newDock(t, filepath.Join(inner, "api"), "deepest")
+
+	if got, want := findDocks(outer), []string{inner, deepest}; !slices.Equal(got, want) {
+		t.Errorf("findDocks() = %q, want %q", got, want)
+	}
+	if got, want := enclosingDocks(filepath.Dir(deepest)), []string{inner, outer}; !slices.Equal(got, want) {
+		t.Errorf("enclosingDocks() = %q, want %q", got, want)
+	}
+	if got := enclosingDocks(root); len(got) != 0 {
+		t.Errorf("enclosingDocks() outside any dock = %q", got)
+	}
+}
+
+func TestDockOperationErrors(t *testing.T) {
+	base := t.TempDir()
+	writeFiles(t, base, map[string]string{
+		"shop/.dock":     "shop",
+		"plain/.gitkeep": "",
+		"config-file":    "not a directory",
+	})
+	shop := filepath.Join(base, "shop")
+
+	tests := []struct {
+		name       string
+		configHome string
+		dir        string
+		run        func() error
+		wantErr    string
+	}{
+		{
+			name:    "switch to a missing dock",
+			run:     func() error { return SetCurrentDock(filepath.Join(base, "missing")) },
+			wantErr: "does not exist",
+		},
+		{
+			name:    "switch to a plain directory",
+			run:     func() error { return SetCurrentDock(filepath.Join(base, "plain")) },
+			wantErr: "is not a valid dock (missing .dock file)",
+		},
+		{
+			name:       "config directory cannot be created",
+			configHome: filepath.Join(base, "config-file", "rq"),
+			run:        func() error { return SetCurrentDock(shop) },
+			wantErr:    "failed to create config directory",
+		},
+		{
+			name:    "create over an existing directory",
+			run:     func() error { return CreateDock(filepath.Join(base, "plain"), InitOptions{}) },
+			wantErr: "already exists",
+		},
+		{
+			name:    "create inside a dock",
+			run:     func() error { return CreateDock(filepath.Join(shop, "inner"), InitOptions{}) },
+			wantErr: "would be nested inside the dock at " + shop,
+		},
+		{
+			name:    "create under a missing parent",
+			run:     func() error { return CreateDock(filepath.Join(base, "missing", "shop"), InitOptions{}) },
+			wantErr: "failed to create dock directory",
+		},
+		{
+			name:    "status outside a dock",
+			dir:     "plain",
+			run:     ShowStatus,
+			wantErr: "not inside an rq dock",
+		},
+		{name: "status inside a dock", dir: "shop", run: ShowStatus},
+		{name: "list without docks", dir: "plain", run: List},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.configHome == "" {
+				tt.configHome = t.TempDir()
+			}
+			t.Setenv(ConfigHomeEnv, tt.configHome)
+			if tt.dir != "" {
+				t.Chdir(filepath.Join(base, tt.dir))
+			}
+
+			err := tt.run()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+func LoadConfigFile(path string) (map[string]string, error) {
+	return loadConfig(path)
+}
+
+// LoadRawConfigFile reads a config file without decrypting enc: values.
+func LoadRawConfigFile(path string) (map[string]string, error) {
+	return parseConfig(path, false)
+}
+
+// LoadOwnConfigFile reads the keys a config file sets itself, without
+// decrypting enc: values or following @env-include, for commands that
+// rewrite the file with WriteConfigValues.
+func LoadOwnConfigFile(path string) (map[string]string, error) {
+	return parseConfigIncluding(path, false, false, nil)
+}
+
+// WriteConfigValues sets keys in a config file, updating existing lines in
+// place and appending new keys at the end, so comments and ordering survive.
+func WriteConfigValues(path string, values map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	}
+
+	pending := make(map[string]string, len(values))
+	for key, value := range values {
+		pending[key] = value
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(trimmed, "=", 2)
+		key := strings.TrimSpace(parts[0])
+
+		if value, ok := pending[key]; ok {
+			lines[i] = fmt.Sprintf("%s=%s", key, value)
+			delete(pending, key)
+		}
+	}
+
+	keys := make([]string, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, pending[key]))
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
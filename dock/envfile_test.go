@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteConfigValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		values   map[string]string
+		want     string
+	}{
+		{
+			name:   "new file",
+			values: map[string]string{"TOKEN": "abc", "BASE_URL": "http://localhost"},
+			want:   "BASE_URL=http://localhost\nTOKEN=abc\n",
+		},
+		{
+			name:     "updates in place and keeps comments",
+			existing: "# API\nBASE_URL = http://old\n\nTOKEN=abc",
+			values:   map[string]string{"BASE_URL": "http://new", "DEBUG": "true"},
+			want:     "# API\nBASE_URL=http://new\n\nTOKEN=abc\nDEBUG=true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := WriteConfigValues(path, tt.values); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadOwnConfigFile(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"shared.env": "TOKEN=enc:v2:abc\nREGION=eu\n",
+		".env":       "@env-include shared.env\n@env-include missing.env\nREGION=us\nSECRET=enc:v2:def\n",
+	})
+
+	got, err := LoadOwnConfigFile(filepath.Join(root, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"REGION": "us", "SECRET": "enc:v2:def"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadOwnConfigFile() = %v, want %v", got, want)
+	}
+
+	writeFiles(t, root, map[string]string{"bad.env": "@env-include\n"})
+	if _, err := LoadOwnConfigFile(filepath.Join(root, "bad.env")); err == nil || err.Error() != "missing path after @env-include at line 1" {
+		t.Errorf("LoadOwnConfigFile() of an include without a path error = %v", err)
+	}
+}
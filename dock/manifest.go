@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ManifestFile lists requests in display order, under optional group
+// titles:
+//
+//	[Users]
+//	users/list
+//	users/create
+//	[Authentication]
+//	login
+//
+// Requests it does not mention keep the default directory grouping and
+// alphabetical order, after the listed ones.
+const ManifestFile = ".rq/manifest"
+
+type ManifestEntry struct {
+	Group string // Title of the group, empty before the first [title]
+	Order int    // Position in the manifest, starting at 0
+}
+
+type Manifest struct {
+	Groups  []string // Group titles in manifest order
+	entries map[string]ManifestEntry
+}
+
+// LoadManifest reads the manifest of the dock. A dock without one gets a nil
+// manifest, on which Lookup finds nothing.
+func LoadManifest(dockPath string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dockPath, ManifestFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest := &Manifest{entries: make(map[string]ManifestEntry)}
+	group := ""
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.TrimSpace(line[1:len(line)-1]) == "" {
+				return nil, fmt.Errorf("%s:%d: invalid group title %s", ManifestFile, i+1, line)
+			}
+			group = strings.TrimSpace(line[1 : len(line)-1])
+			manifest.Groups = append(manifest.Groups, group)
+			continue
+		}
+
+		key := manifestKey(line)
+		if _, ok := manifest.entries[key]; ok {
+			return nil, fmt.Errorf("%s:%d: %s is listed twice", ManifestFile, i+1, line)
+		}
+		manifest.entries[key] = ManifestEntry{Group: group, Order: len(manifest.entries)}
+	}
+
+	return manifest, nil
+}
+
+// Lookup finds a request by its path relative to the dock, with or without
+// extension.
+func (manifest *Manifest) Lookup(relPath string) (ManifestEntry, bool) {
+	if manifest == nil {
+		return ManifestEntry{}, false
+	}
+	entry, ok := manifest.entries[manifestKey(relPath)]
+	return entry, ok
+}
+
+// Group returns the manifest group of a request, or fallback when the
+// manifest does not give it one.
+func (manifest *Manifest) Group(relPath, fallback string) string {
+	if entry, ok := manifest.Lookup(relPath); ok && entry.Group != "" {
+		return entry.Group
+	}
+	return fallback
+}
+
+// Compare orders listed requests before the others, in manifest order. It
+// returns 0 when neither is listed, so the caller can fall back to its own
+// order.
+func (manifest *Manifest) Compare(a, b string) int {
+	entryA, listedA := manifest.Lookup(a)
+	entryB, listedB := manifest.Lookup(b)
+	switch {
+	case listedA && listedB:
+		return entryA.Order - entryB.Order
+	case listedA:
+		return -1
+	case listedB:
+		return 1
+	}
+	return 0
+}
+
+// SortGroups puts the manifest groups first, in manifest order, and the
+// others after them alphabetically.
+func (manifest *Manifest) SortGroups(names []string) {
+	position := func(name string) int {
+		if manifest != nil {
+			if i := slices.Index(manifest.Groups, name); i != -1 {
+				return i
+			}
+		}
+		return -1
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		posA, posB := position(a), position(b)
+		switch {
+		case posA != -1 && posB != -1:
+			return posA - posB
+		case posA != -1:
+			return -1
+		case posB != -1:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+func manifestKey(path string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if IsRequestFile(path) {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	return path
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		missing    bool
+		wantGroups []string
+		want       map[string]ManifestEntry
+		wantErr    string
+	}{
+		{name: "no manifest", missing: true},
+		{
+			name:       "groups and order",
+			content:    "# display order\nhealth\n\n[Users]\nusers/list.http\n users/create \n[Authentication]\nlogin\n",
+			wantGroups: []string{"Users", "Authentication"},
+			want: map[string]ManifestEntry{
+				"health":       {Order: 0},
+				"users/list":   {Group: "Users", Order: 1},
+				"users/create": {Group: "Users", Order: 2},
+				"login.http":   {Group: "Authentication", Order: 3},
+			},
+		},
+		{name: "listed twice", content: "login\nlogin.http\n", wantErr: ".rq/manifest:2: login.http is listed twice"},
+		{name: "unclosed title", content: "[Users\n", wantErr: ".rq/manifest:1: invalid group title [Users"},
+		{name: "empty title", content: "[ ]\n", wantErr: ".rq/manifest:1: invalid group title [ ]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if !tt.missing {
+				writeFiles(t, root, map[string]string{ManifestFile: tt.content})
+			}
+
+			manifest, err := LoadManifest(root)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("LoadManifest() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.missing {
+				if manifest != nil {
+					t.Fatalf("LoadManifest() without a file = %+v, want nil", manifest)
+				}
+				if _, ok := manifest.Lookup("login"); ok {
+					t.Error("Lookup() on a nil manifest found an entry")
+				}
+				return
+			}
+
+			if !slices.Equal(manifest.Groups, tt.wantGroups) {
+				t.Errorf("Groups = %q, want %q", manifest.Groups, tt.wantGroups)
+			}
+			for path, want := range tt.want {
+				if got, ok := manifest.Lookup(path); !ok || got != want {
+					t.Errorf("Lookup(%q) = %+v, %v, want %+v", path, got, ok, want)
+				}
+			}
+			if _, ok := manifest.Lookup("orders/list"); ok {
+				t.Error("Lookup() found a request missing from the manifest")
+			}
+		})
+	}
+}
+
+func TestManifestOrdering(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{ManifestFile: "[Users]\nusers/create\nusers/list\n[Authentication]\nlogin\n"})
+	manifest, err := LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"health", "users/list", "orders/list", "login", "users/create"}
+	slices.SortStableFunc(names, manifest.Compare)
+	if want := []string{"users/create", "users/list", "login", "health", "orders/list"}; !slices.Equal(names, want) {
+		t.Errorf("sorted by Compare = %q, want %q", names, want)
+	}
+
+	groups := []string{"orders", "Root", "Authentication", "Users"}
+	manifest.SortGroups(groups)
+	if want := []string{"Users", "Authentication", "Root", "orders"}; !slices.Equal(groups, want) {
+		t.Errorf("SortGroups() = %q, want %q", groups, want)
+	}
+
+	var none *Manifest
+	groups = []string{"users", "Root", "orders"}
+	none.SortGroups(groups)
+	if want := []string{"Root", "orders", "users"}; !slices.Equal(groups, want) {
+		t.Errorf("SortGroups() without a manifest = %q, want %q", groups, want)
+	}
+	if got := none.Group("users/list", "users"); got != "users" {
+		t.Errorf("Group() without a manifest = %q, want the fallback", got)
+	}
+}
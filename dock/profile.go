@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GlobalConfigFile is read from the rq configuration directory. It holds
+// named profiles, bundles of run options given by their long names:
+//
+//	[profile debug]
+//	verbose
+//	timeout=5s
+//	redirect-strip-auth=never
+//
+// A line without a value turns a flag on.
+const GlobalConfigFile = "config"
+
+// LoadProfile returns the options of a profile, mapping each name to its
+// value. Flags have an empty value.
+func LoadProfile(name string) (map[string]string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(configDir, GlobalConfigFile)
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("profile %q not found, %s does not exist", name, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global config: %w", err)
+	}
+
+	var profile map[string]string
+	current := ""
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			section, ok := strings.CutSuffix(line[1:], "]")
+			fields := strings.Fields(section)
+			if !ok || len(fields) != 2 || fields[0] != "profile" {
+				return nil, fmt.Errorf("%s:%d: expected [profile <name>], got %s", GlobalConfigFile, i+1, line)
+			}
+			current = fields[1]
+			if current == name {
+				profile = make(map[string]string)
+			}
+			continue
+		}
+
+		if current == "" {
+			return nil, fmt.Errorf("%s:%d: option outside of a [profile <name>] section", GlobalConfigFile, i+1)
+		}
+		if current != name {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.TrimLeft(strings.TrimSpace(key), "-")
+		if key == "" {
+			return nil, fmt.Errorf("%s:%d: option without a name", GlobalConfigFile, i+1)
+		}
+		profile[key] = strings.TrimSpace(value)
+	}
+
+	if profile == nil {
+		return nil, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	return profile, nil
+}
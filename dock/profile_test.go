@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfile(t *testing.T) {
+	const config = "# shared options\n[profile debug]\nverbose\n--timeout = 5s\nredirect-strip-auth=never\n\n[profile ci]\nbrief\nretry=3\n"
+
+	tests := []struct {
+		name    string
+		config  string
+		missing bool
+		profile string
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "first profile", config: config, profile: "debug", want: map[string]string{"verbose": "", "timeout": "5s", "redirect-strip-auth": "never"}},
+		{name: "last profile", config: config, profile: "ci", want: map[string]string{"brief": "", "retry": "3"}},
+		{name: "empty profile", config: "[profile quiet]\n", profile: "quiet", want: map[string]string{}},
+		{name: "unknown profile", config: config, profile: "prod", wantErr: `profile "prod" not found in `},
+		{name: "no config file", missing: true, profile: "debug", wantErr: `profile "debug" not found, `},
+		{name: "bad section", config: "[debug]\nverbose\n", profile: "debug", wantErr: "config:1: expected [profile <name>], got [debug]"},
+		{name: "option outside a section", config: "verbose\n[profile debug]\n", profile: "debug", wantErr: "config:1: option outside of a [profile <name>] section"},
+		{name: "option without a name", config: "[profile debug]\n=5s\n", profile: "debug", wantErr: "config:2: option without a name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv(ConfigHomeEnv, home)
+			if !tt.missing {
+				writeFiles(t, home, map[string]string{GlobalConfigFile: tt.config})
+			}
+
+			got, err := LoadProfile(tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadProfile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
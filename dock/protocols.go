@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Protocols lists the supported request types. A request file is named
+// <name>.<protocol>, and a bare request name resolves to the first
+// protocol in this order whose file exists.
+var Protocols = []string{"http", "tcp", "ws", "grpc"}
+
+var protocolAliases = map[string]string{
+	"websocket": "ws",
+}
+
+// NormalizeProtocol maps a protocol name or alias to its canonical form.
+func NormalizeProtocol(protocol string) (string, bool) {
+	protocol = strings.ToLower(protocol)
+	if alias, ok := protocolAliases[protocol]; ok {
+		protocol = alias
+	}
+	for _, p := range Protocols {
+		if p == protocol {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func RequestExtensions() []string {
+	extensions := make([]string, len(Protocols))
+	for i, protocol := range Protocols {
+		extensions[i] = "." + protocol
+	}
+	return extensions
+}
+
+func IsRequestFile(path string) bool {
+	return slices.Contains(RequestExtensions(), filepath.Ext(path))
+}
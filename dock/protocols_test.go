@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import "testing"
+
+func TestNormalizeProtocol(t *testing.T) {
+	tests := []struct {
+		protocol string
+		want     string
+		ok       bool
+	}{
+		{"http", "http", true},
+		{"HTTP", "http", true},
+		{"tcp", "tcp", true},
+		{"ws", "ws", true},
+		{"websocket", "ws", true},
+		{"grpc", "grpc", true},
+		{"ftp", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeProtocol(tt.protocol)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeProtocol(%q) = %q, %v, want %q, %v", tt.protocol, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIsRequestFile(t *testing.T) {
+	tests := map[string]bool{
+		"users.http":       true,
+		"echo.tcp":         true,
+		"chat.ws":          true,
+		"api/service.grpc": true,
+		".env":             false,
+		"notes.txt":        false,
+		"users.http.bak":   false,
+		"http":             false,
+	}
+
+	for path, want := range tests {
+		if got := IsRequestFile(path); got != want {
+			t.Errorf("IsRequestFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaField describes a config key declared in .rq/schema, one per line:
+//
+//	BASE_URL=url,required
+//	RETRIES=int
+type SchemaField struct {
+	Key      string
+	Type     string
+	Required bool
+}
+
+var schemaTypes = map[string]func(string) error{
+	"string": func(string) error { return nil },
+	"int": func(value string) error {
+		_, err := strconv.Atoi(value)
+		return err
+	},
+	"number": func(value string) error {
+		_, err := strconv.ParseFloat(value, 64)
+		return err
+	},
+	"bool": func(value string) error {
+		_, err := strconv.ParseBool(value)
+		return err
+	},
+	"duration": func(value string) error {
+		_, err := time.ParseDuration(value)
+		return err
+	},
+	"url": func(value string) error {
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("missing scheme or host")
+		}
+		return nil
+	},
+}
+
+func SchemaPath(dockPath string) string {
+	return filepath.Join(dockPath, ".rq", "schema")
+}
+
+func LoadSchema(dockPath string) ([]SchemaField, error) {
+	raw, err := loadConfig(SchemaPath(dockPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	var fields []SchemaField
+	for key, spec := range raw {
+		field := SchemaField{Key: key, Type: "string"}
+
+		for i, part := range strings.Split(spec, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			switch {
+			case part == "required":
+				field.Required = true
+			case part == "optional" || part == "":
+			case i == 0:
+				if _, ok := schemaTypes[part]; !ok {
+					return nil, fmt.Errorf("unknown type '%s' for key %s", part, key)
+				}
+				field.Type = part
+			default:
+				return nil, fmt.Errorf("unknown modifier '%s' for key %s", part, key)
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+
+	return fields, nil
+}
+
+// ValidateConfig checks a merged config against the schema and returns one
+// message per problem found.
+func ValidateConfig(schema []SchemaField, config map[string]string) []string {
+	var problems []string
+
+	for _, field := range schema {
+		value, ok := config[field.Key]
+		if !ok || value == "" {
+			if field.Required {
+				problems = append(problems, fmt.Sprintf("%s is required but not set", field.Key))
+			}
+			continue
+		}
+
+		if err := schemaTypes[field.Type](value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a valid %s, got %q", field.Key, field.Type, value))
+		}
+	}
+
+	return problems
+}
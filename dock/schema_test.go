@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeSchema(t *testing.T, content string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".rq"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(SchemaPath(root), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestLoadSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []SchemaField
+		wantErr string
+	}{
+		{
+			name:    "types and modifiers",
+			content: "BASE_URL=url,required\nRETRIES=int\nTOKEN=\nDEBUG=BOOL, optional\n",
+			want: []SchemaField{
+				{Key: "BASE_URL", Type: "url", Required: true},
+				{Key: "DEBUG", Type: "bool"},
+				{Key: "RETRIES", Type: "int"},
+				{Key: "TOKEN", Type: "string"},
+			},
+		},
+		{
+			name:    "required without type",
+			content: "TOKEN=required\n",
+			want:    []SchemaField{{Key: "TOKEN", Type: "string", Required: true}},
+		},
+		{name: "unknown type", content: "PORT=integer\n", wantErr: "unknown type 'integer' for key PORT"},
+		{name: "unknown modifier", content: "PORT=int,secret\n", wantErr: "unknown modifier 'secret' for key PORT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadSchema(writeSchema(t, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadSchema() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadSchema() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	schema := []SchemaField{
+		{Key: "BASE_URL", Type: "url", Required: true},
+		{Key: "DEBUG", Type: "bool"},
+		{Key: "RATIO", Type: "number"},
+		{Key: "RETRIES", Type: "int"},
+		{Key: "TIMEOUT", Type: "duration"},
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   []string
+	}{
+		{
+			name: "valid",
+			config: map[string]string{
+				"BASE_URL": "https://api.example.com",
+				"DEBUG":    "true",
+				"RATIO":    "0.5",
+				"RETRIES":  "3",
+				"TIMEOUT":  "5s",
+			},
+		},
+		{
+			name:   "optional keys may be missing",
+			config: map[string]string{"BASE_URL": "http://localhost:8080"},
+		},
+		{
+			name:   "required key empty",
+			config: map[string]string{"BASE_URL": ""},
+			want:   []string{"BASE_URL is required but not set"},
+		},
+		{
+			name: "wrong types",
+			config: map[string]string{
+				"BASE_URL": "localhost",
+				"DEBUG":    "maybe",
+				"RATIO":    "half",
+				"RETRIES":  "3.5",
+				"TIMEOUT":  "5",
+			},
+			want: []string{
+				`BASE_URL must be a valid url, got "localhost"`,
+				`DEBUG must be a valid bool, got "maybe"`,
+				`RATIO must be a valid number, got "half"`,
+				`RETRIES must be a valid int, got "3.5"`,
+				`TIMEOUT must be a valid duration, got "5"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateConfig(schema, tt.config)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
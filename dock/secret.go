@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedPrefix marks an encrypted value. Values are written as
+//
+//	enc:v2:<N>:<r>:<p>:<salt>:<nonce and ciphertext>
+//
+// where the key is derived from the master key with scrypt, using the
+// parameters and base64 salt stored in the value.
+const EncryptedPrefix = "enc:"
+
+const encryptedVersion = "v2"
+
+// scrypt parameters for new values, as recommended for interactive use.
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	scryptSaltLen = 16
+	scryptMaxN    = 1 << 20 // Refuse values that would take too long to open
+)
+
+type scryptParams struct {
+	n, r, p int
+	salt    string
+}
+
+// derivedKeys caches scrypt keys by master key and parameters, so a config
+// with many values sharing a salt is derived once.
+var derivedKeys sync.Map
+
+var ErrNoMasterKey = errors.New("no master key found: set RQ_MASTER_KEY or store one in the OS keychain under the service 'rq'")
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
+
+// masterKey reads the master key from RQ_MASTER_KEY, falling back to the OS keychain
+// (macOS Keychain via `security`, Linux Secret Service via `secret-tool`).
+func masterKey() (string, error) {
+	secret := os.Getenv("RQ_MASTER_KEY")
+	if secret == "" {
+		secret = keychainKey()
+	}
+
+	if secret == "" {
+		return "", ErrNoMasterKey
+	}
+	return secret, nil
+}
+
+// keychainKey asks the OS keychain for the master key once per process, as
+// every encrypted value of every config load needs it.
+var keychainKey = sync.OnceValue(func() string {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", "rq", "-a", "master", "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", "rq", "account", "master")
+	}
+
+	if cmd == nil {
+		return ""
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+})
+
+// deriveKey stretches the master key with scrypt.
+func deriveKey(secret string, params scryptParams) ([]byte, error) {
+	cacheKey := fmt.Sprintf("%x:%d:%d:%d:%s", sha256.Sum256([]byte(secret)), params.n, params.r, params.p, params.salt)
+	if key, ok := derivedKeys.Load(cacheKey); ok {
+		return key.([]byte), nil
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(params.salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value salt: %w", err)
+	}
+	key, err := scrypt.Key([]byte(secret), salt, params.n, params.r, params.p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	derivedKeys.Store(cacheKey, key)
+	return key, nil
+}
+
+func newGCM(params scryptParams) (cipher.AEAD, error) {
+	secret, err := masterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(secret, params)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func EncryptValue(plaintext string) (string, error) {
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	params := scryptParams{n: scryptN, r: scryptR, p: scryptP, salt: base64.StdEncoding.EncodeToString(salt)}
+
+	gcm, err := newGCM(params)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return fmt.Sprintf("%s%s:%d:%d:%d:%s:%s", EncryptedPrefix, encryptedVersion, params.n, params.r, params.p,
+		params.salt, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// parseEncrypted splits an encrypted value into its key parameters and the
+// sealed nonce and ciphertext.
+func parseEncrypted(value string) (scryptParams, []byte, error) {
+	var params scryptParams
+	rest, ok := strings.CutPrefix(value, EncryptedPrefix+encryptedVersion+":")
+	if !ok {
+		return params, nil, fmt.Errorf("invalid encrypted value: expected %s%s:N:r:p:salt:data", EncryptedPrefix, encryptedVersion)
+	}
+
+	fields := strings.Split(rest, ":")
+	if len(fields) != 5 {
+		return params, nil, errors.New("invalid encrypted value: expected N:r:p:salt:data after v2")
+	}
+	params.salt = fields[3]
+	for i, target := range []*int{&params.n, &params.r, &params.p} {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil || n <= 0 {
+			return params, nil, fmt.Errorf("invalid encrypted value: bad scrypt parameter %q", fields[i])
+		}
+		*target = n
+	}
+	if params.n > scryptMaxN || params.r*params.p >= 1<<30 {
+		return params, nil, errors.New("invalid encrypted value: scrypt parameters too large")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return params, nil, fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	return params, sealed, nil
+}
+
+func DecryptValue(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	params, sealed, err := parseEncrypted(value)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(params)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("decryption failed: wrong master key or corrupted value")
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	t.Setenv("RQ_MASTER_KEY", "correct horse")
+
+	for _, plaintext := range []string{"s3cret", "", "with:colons=and spaces", "ünïcode"} {
+		encrypted, err := EncryptValue(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(encrypted, EncryptedPrefix+encryptedVersion+":") {
+			t.Errorf("EncryptValue(%q) = %q, want a v2 value", plaintext, encrypted)
+		}
+		if strings.Contains(encrypted, plaintext) && plaintext != "" {
+			t.Errorf("EncryptValue(%q) leaks the plaintext: %q", plaintext, encrypted)
+		}
+
+		decrypted, err := DecryptValue(encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decrypted != plaintext {
+			t.Errorf("round trip of %q = %q", plaintext, decrypted)
+		}
+	}
+}
+
+func TestEncryptValueUsesFreshSalt(t *testing.T) {
+	t.Setenv("RQ_MASTER_KEY", "correct horse")
+
+	first, err := EncryptValue("same")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := EncryptValue("same")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Error("encrypting the same value twice gave identical output")
+	}
+}
+
+func TestDecryptValue(t *testing.T) {
+	t.Setenv("RQ_MASTER_KEY", "correct horse")
+
+	current, err := EncryptValue("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		key     string
+		want    string
+		wantErr string
+	}{
+		{name: "plain value passes through", value: "not encrypted", want: "not encrypted"},
+		{name: "v2", value: current, want: "s3cret"},
+		{name: "wrong key", value: current, key: "wrong", wantErr: "wrong master key"},
+		{name: "unversioned", value: "enc:" + base64.StdEncoding.EncodeToString(make([]byte, 40)), wantErr: "expected enc:v2:N:r:p:salt:data"},
+		{name: "unknown version", value: "enc:v3:32768:8:1:c2FsdA==:data", wantErr: "expected enc:v2:N:r:p:salt:data"},
+		{name: "missing fields", value: "enc:v2:32768:8:1:data", wantErr: "expected N:r:p:salt:data"},
+		{name: "bad parameter", value: "enc:v2:x:8:1:c2FsdA==:data", wantErr: `bad scrypt parameter "x"`},
+		{name: "parameters too large", value: "enc:v2:2097152:8:1:c2FsdA==:data", wantErr: "too large"},
+		{name: "bad base64", value: "enc:v2:32768:8:1:c2FsdA==:!!!", wantErr: "invalid encrypted value"},
+		{name: "too short", value: "enc:v2:1024:8:1:c2FsdA==:" + base64.StdEncoding.EncodeToString([]byte("x")), wantErr: "too short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.key != "" {
+				t.Setenv("RQ_MASTER_KEY", tt.key)
+			}
+
+			got, err := DecryptValue(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DecryptValue() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DecryptValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDecryptsValues(t *testing.T) {
+	t.Setenv("RQ_MASTER_KEY", "correct horse")
+
+	encrypted, err := EncryptValue("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{".env": "TOKEN=" + encrypted + "\nUSER=admin\n"})
+	path := filepath.Join(root, ".env")
+
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config["TOKEN"] != "s3cret" || config["USER"] != "admin" {
+		t.Errorf("LoadConfigFile() = %v", config)
+	}
+
+	raw, err := LoadRawConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw["TOKEN"] != encrypted {
+		t.Errorf("LoadRawConfigFile() TOKEN = %q, want it left encrypted", raw["TOKEN"])
+	}
+}
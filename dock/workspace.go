@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConfigHomeEnv names the variable that relocates the rq configuration
+// directory, e.g. for tests or non-standard setups.
+const ConfigHomeEnv = "RQ_CONFIG_HOME"
+
+// ConfigDir returns the rq configuration directory, creating it if needed.
+// It is $RQ_CONFIG_HOME when set, otherwise rq inside the user config dir.
+func ConfigDir() (string, error) {
+	configDir := os.Getenv(ConfigHomeEnv)
+	if configDir == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get config directory: %w", err)
+		}
+		configDir = filepath.Join(dir, "rq")
+	}
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return configDir, nil
+}
+
+func workspaceFile() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "workspace"), nil
+}
+
+// LoadWorkspace reads the registered docks, mapping friendly names to paths.
+func LoadWorkspace() (map[string]string, error) {
+	path, err := workspaceFile()
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := parseWorkspace(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// parseWorkspace reads the name=path lines of the registry. Dock names are
+// not config keys, so unlike env files any name without '=' is accepted.
+func parseWorkspace(path string) (map[string]string, error) {
+	workspace := make(map[string]string)
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return workspace, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, dockPath, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid entry at line %d: expected name=path", i+1)
+		}
+		workspace[strings.TrimSpace(name)] = strings.TrimSpace(dockPath)
+	}
+	return workspace, nil
+}
+
+func saveWorkspace(workspace map[string]string) error {
+	path, err := workspaceFile()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(workspace))
+	for name := range workspace {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("# Docks registered with 'rq dock add'\n")
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("%s=%s\n", name, workspace[name]))
+	}
+
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+func lookupWorkspace(name string) (string, error) {
+	workspace, err := LoadWorkspace()
+	if err != nil {
+		return "", err
+	}
+	return workspace[name], nil
+}
+
+func AddDock(name, path string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "=\n") {
+		return fmt.Errorf("invalid dock name: %q", name)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	if !exists(filepath.Join(absPath, ".dock")) {
+		return fmt.Errorf("'%s' is not a valid dock (missing .dock file)", path)
+	}
+
+	workspace, err := LoadWorkspace()
+	if err != nil {
+		return err
+	}
+
+	workspace[name] = absPath
+	return saveWorkspace(workspace)
+}
+
+func CurrentDock() string {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	content, err := os.ReadFile(filepath.Join(configDir, "current_dock"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func ListWorkspace() error {
+	workspace, err := LoadWorkspace()
+	if err != nil {
+		return err
+	}
+
+	if len(workspace) == 0 {
+		fmt.Println("No docks registered")
+		fmt.Println("Run 'rq dock add <name> <path>' to register one")
+		return nil
+	}
+
+	names := make([]string, 0, len(workspace))
+	for name := range workspace {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	current := CurrentDock()
+
+	fmt.Println("Registered docks:")
+	for _, name := range names {
+		marker := " "
+		if workspace[name] == current {
+			marker = "*"
+		}
+
+		status := ""
+		if !exists(filepath.Join(workspace[name], ".dock")) {
+			status = " (missing)"
+		}
+
+		fmt.Printf("%s %s (%s)%s\n", marker, name, workspace[name], status)
+	}
+	return nil
+}
+
+// freeDockName returns name, or name-2, name-3 and so on when the workspace
+// already uses it for another dock, so adding path never replaces an entry.
+func freeDockName(name, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	workspace, err := LoadWorkspace()
+	if err != nil {
+		return "", err
+	}
+
+	candidate := name
+	for i := 2; ; i++ {
+		if existing, ok := workspace[candidate]; !ok || existing == absPath {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+func dockName(path string) string {
+	content, err := os.ReadFile(filepath.Join(path, ".dock"))
+	if err == nil {
+		if name := strings.TrimSpace(string(content)); name != "" {
+			return name
+		}
+	}
+	return filepath.Base(path)
+}
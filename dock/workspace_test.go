@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package dock
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newDock(t *testing.T, parent, name string) string {
+	t.Helper()
+	path := filepath.Join(parent, name)
+	writeFiles(t, path, map[string]string{".dock": name, ".env": "BASE_URL=http://" + name + "\n"})
+	return path
+}
+
+func TestWorkspaceAddAndUse(t *testing.T) {
+	t.Setenv(ConfigHomeEnv, t.TempDir())
+	root := t.TempDir()
+	shop := newDock(t, root, "shop")
+	billing := newDock(t, root, "billing")
+
+	tests := []struct {
+		name, dock string
+		path       string
+	}{
+		{"my-api", shop, shop},
+		{"billing.v2", billing, billing},
+		{"my-api", billing, billing},
+	}
+	for _, tt := range tests {
+		if err := AddDock(tt.name, tt.dock); err != nil {
+			t.Fatalf("AddDock(%q): %v", tt.name, err)
+		}
+		if got, _ := lookupWorkspace(tt.name); got != tt.path {
+			t.Errorf("lookupWorkspace(%q) = %q, want %q", tt.name, got, tt.path)
+		}
+	}
+
+	workspace, err := LoadWorkspace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"my-api": billing, "billing.v2": billing}; !maps.Equal(workspace, want) {
+		t.Errorf("workspace = %v, want %v", workspace, want)
+	}
+
+	if err := SetCurrentDock("billing.v2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := CurrentDock(); got != billing {
+		t.Errorf("CurrentDock = %q, want %q", got, billing)
+	}
+}
+
+func TestAddDockRejects(t *testing.T) {
+	t.Setenv(ConfigHomeEnv, t.TempDir())
+	root := t.TempDir()
+	shop := newDock(t, root, "shop")
+
+	tests := []struct {
+		name, path string
+	}{
+		{"", shop},
+		{"a=b", shop},
+		{"ok", filepath.Join(root, "missing")},
+		{"ok", root},
+	}
+	for _, tt := range tests {
+		if err := AddDock(tt.name, tt.path); err == nil {
+			t.Errorf("AddDock(%q, %q) succeeded", tt.name, tt.path)
+		}
+	}
+}
+
+func TestParseWorkspace(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"comments and blanks", "# docks\n\nshop=/a\n  my-api = /b \n", map[string]string{"shop": "/a", "my-api": "/b"}, false},
+		{"names that are not identifiers", "billing.v2=/c\n2fa=/d\n", map[string]string{"billing.v2": "/c", "2fa": "/d"}, false},
+		{"missing separator", "shop\n", nil, true},
+		{"empty name", "=/a\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "workspace")
+			os.WriteFile(path, []byte(tt.content), 0644)
+
+			got, err := parseWorkspace(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWorkspaceMissingFile(t *testing.T) {
+	got, err := parseWorkspace(filepath.Join(t.TempDir(), "none"))
+	if err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v", got, err)
+	}
+}
+
+func TestSetCurrentDockRejectsNonDock(t *testing.T) {
+	t.Setenv(ConfigHomeEnv, t.TempDir())
+	if err := SetCurrentDock(t.TempDir()); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		override bool
+	}{
+		{name: "RQ_CONFIG_HOME", override: true},
+		{name: "user config dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+			t.Setenv("AppData", filepath.Join(home, "appdata"))
+
+			want := filepath.Join(home, "custom", "rq-config")
+			if tt.override {
+				t.Setenv(ConfigHomeEnv, want)
+			} else {
+				t.Setenv(ConfigHomeEnv, "")
+				userDir, err := os.UserConfigDir()
+				if err != nil {
+					t.Skip(err)
+				}
+				want = filepath.Join(userDir, "rq")
+			}
+
+			got, err := ConfigDir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("ConfigDir() = %q, want %q", got, want)
+			}
+			if info, err := os.Stat(want); err != nil || !info.IsDir() {
+				t.Errorf("ConfigDir() did not create %s", want)
+			}
+
+			shop := newDock(t, t.TempDir(), "shop")
+			if err := AddDock("shop", shop); err != nil {
+				t.Fatal(err)
+			}
+			if err := SetCurrentDock("shop"); err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range []string{"workspace", "current_dock"} {
+				if !exists(filepath.Join(want, file)) {
+					t.Errorf("%s was not written to %s", file, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"fmt"
+	"strings"
+)
+
+func generateAsciiDocDocs(dockDocs *DockDocs) string {
+	var doc strings.Builder
+
+	doc.WriteString(fmt.Sprintf("= %s API Documentation\n", dockDocs.Name))
+	doc.WriteString(":toc:\n")
+	doc.WriteString(":toclevels: 3\n\n")
+
+	if dockDocs.Description != "" {
+		doc.WriteString(fmt.Sprintf("%s\n\n", dockDocs.Description))
+	}
+
+	if dockDocs.BaseURL != "" {
+		doc.WriteString(fmt.Sprintf("*Base URL:* `%s`\n\n", dockDocs.BaseURL))
+	}
+
+	if dockDocs.Version != "" {
+		doc.WriteString(fmt.Sprintf("*Version:* %s\n\n", dockDocs.Version))
+	}
+
+	doc.WriteString(fmt.Sprintf("*Generated:* %s\n\n", dockDocs.GeneratedAt.Format("2006-01-02 15:04:05")))
+
+	for _, groupName := range sortedGroupNames(dockDocs) {
+		doc.WriteString(fmt.Sprintf("== %s\n\n", groupName))
+
+		for _, req := range dockDocs.Groups[groupName] {
+			doc.WriteString(generateRequestAsciiDoc(req))
+		}
+	}
+
+	return doc.String()
+}
+
+func generateRequestAsciiDoc(req RequestDoc) string {
+	var doc strings.Builder
+
+	doc.WriteString(fmt.Sprintf("=== %s\n\n", req.Name))
+
+	if req.Method != "" && req.URL != "" {
+		doc.WriteString(fmt.Sprintf("*`%s %s`*\n\n", req.Method, req.URL))
+	}
+
+	if req.Description != "" {
+		doc.WriteString(fmt.Sprintf("%s\n\n", req.Description))
+	}
+
+	if req.Deprecated {
+		doc.WriteString("WARNING: This request is deprecated.\n\n")
+	}
+
+	if len(req.Tags) > 0 {
+		doc.WriteString(fmt.Sprintf("*Tags:* %s\n\n", strings.Join(req.Tags, ", ")))
+	}
+
+	if len(req.Parameters) > 0 {
+		doc.WriteString(".Parameters\n")
+		doc.WriteString("[options=\"header\"]\n")
+		doc.WriteString("|===\n")
+		doc.WriteString("|Name |Type |Required |Description |Example\n\n")
+		for _, param := range req.Parameters {
+			required := "No"
+			if param.Required {
+				required = "Yes"
+			}
+			doc.WriteString(fmt.Sprintf("|%s |%s |%s |%s |%s\n",
+				escapeAsciiDocCell(param.Name), escapeAsciiDocCell(param.Type), required,
+				escapeAsciiDocCell(param.Description), escapeAsciiDocCell(param.Example)))
+		}
+		doc.WriteString("|===\n\n")
+	}
+
+	if len(req.Headers) > 0 {
+		doc.WriteString(".Headers\n")
+		doc.WriteString("[options=\"header\"]\n")
+		doc.WriteString("|===\n")
+		doc.WriteString("|Name |Required |Description |Example\n\n")
+		for _, header := range req.Headers {
+			required := "No"
+			if header.Required {
+				required = "Yes"
+			}
+			doc.WriteString(fmt.Sprintf("|%s |%s |%s |%s\n",
+				escapeAsciiDocCell(header.Name), required,
+				escapeAsciiDocCell(header.Description), escapeAsciiDocCell(header.Example)))
+		}
+		doc.WriteString("|===\n\n")
+	}
+
+	if len(req.Responses) > 0 {
+		doc.WriteString(".Responses\n")
+		for _, resp := range req.Responses {
+			doc.WriteString(fmt.Sprintf("* *%s*: %s\n", resp.Status, resp.Description))
+			if resp.Example != "" {
+				doc.WriteString("+\n")
+				doc.WriteString(asciiDocSource("json", resp.Example))
+			}
+		}
+		doc.WriteString("\n")
+	}
+
+	if req.RequestBody != "" {
+		doc.WriteString(".Request Body\n")
+		doc.WriteString(asciiDocSource("json", req.RequestBody))
+		doc.WriteString("\n")
+	}
+
+	if len(req.Examples) > 0 {
+		doc.WriteString(".Examples\n")
+		for _, example := range req.Examples {
+			if example.Title != "" {
+				doc.WriteString(fmt.Sprintf("==== %s\n\n", example.Title))
+			}
+			if example.Description != "" {
+				doc.WriteString(fmt.Sprintf("%s\n\n", example.Description))
+			}
+			if example.Code != "" {
+				doc.WriteString(asciiDocSource("bash", example.Code))
+				doc.WriteString("\n")
+			}
+			if example.Output != "" {
+				doc.WriteString(asciiDocSource("json", example.Output))
+				doc.WriteString("\n")
+			}
+		}
+	}
+
+	doc.WriteString("'''\n\n")
+
+	return doc.String()
+}
+
+func asciiDocSource(language, code string) string {
+	return fmt.Sprintf("[source,%s]\n----\n%s\n----\n", language, code)
+}
+
+func escapeAsciiDocCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
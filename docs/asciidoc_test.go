@@ -0,0 +1,25 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import "testing"
+
+func TestGenerateAsciiDocDocs(t *testing.T) {
+	checkGolden(t, "shop.adoc", generateAsciiDocDocs(sampleDocs()))
+}
+
+func TestEscapeAsciiDocCell(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"plain", "plain"},
+		{"a|b", `a\|b`},
+		{"|", `\|`},
+	}
+	for _, tt := range tests {
+		if got := escapeAsciiDocCell(tt.value); got != tt.want {
+			t.Errorf("escapeAsciiDocCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func generateChangelog(dockDocs *DockDocs) string {
+	var md strings.Builder
+
+	md.WriteString(fmt.Sprintf("# %s Changelog\n\n", dockDocs.Name))
+
+	versions := make(map[string][]RequestDoc)
+	var unversioned []RequestDoc
+	var deprecated []RequestDoc
+
+	for _, req := range dockDocs.Requests {
+		if req.Since != "" {
+			versions[req.Since] = append(versions[req.Since], req)
+		} else {
+			unversioned = append(unversioned, req)
+		}
+
+		if req.Deprecated {
+			deprecated = append(deprecated, req)
+		}
+	}
+
+	names := make([]string, 0, len(versions))
+	for version := range versions {
+		names = append(names, version)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return compareVersions(names[i], names[j]) > 0
+	})
+
+	if len(deprecated) > 0 {
+		md.WriteString("## Deprecated\n\n")
+		for _, req := range deprecated {
+			md.WriteString(changelogEntry(req))
+		}
+		md.WriteString("\n")
+	}
+
+	for _, version := range names {
+		md.WriteString(fmt.Sprintf("## %s\n\n", version))
+		for _, req := range versions[version] {
+			md.WriteString(changelogEntry(req))
+		}
+		md.WriteString("\n")
+	}
+
+	if len(unversioned) > 0 {
+		md.WriteString("## Unversioned\n\n")
+		for _, req := range unversioned {
+			md.WriteString(changelogEntry(req))
+		}
+		md.WriteString("\n")
+	}
+
+	return md.String()
+}
+
+func changelogEntry(req RequestDoc) string {
+	entry := fmt.Sprintf("- **%s**", req.Name)
+	if req.Method != "" && req.URL != "" {
+		entry += fmt.Sprintf(" `%s %s`", req.Method, req.URL)
+	}
+	if req.Description != "" {
+		entry += " - " + req.Description
+	}
+	return entry + "\n"
+}
+
+// compareVersions orders semantic versions numerically, so 1.10.0 sorts after 1.9.0.
+// Pre-release versions (1.0.0-beta) sort before their release and among
+// themselves as comparePrerelease says.
+func compareVersions(a, b string) int {
+	a = strings.TrimPrefix(strings.TrimSpace(a), "v")
+	b = strings.TrimPrefix(strings.TrimSpace(b), "v")
+
+	// Build metadata does not take part in the order.
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+
+	partsA := strings.Split(coreA, ".")
+	partsB := strings.Split(coreB, ".")
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var segA, segB string
+		if i < len(partsA) {
+			segA = partsA[i]
+		}
+		if i < len(partsB) {
+			segB = partsB[i]
+		}
+
+		numA, errA := strconv.Atoi(segA)
+		numB, errB := strconv.Atoi(segB)
+
+		if segA == "" {
+			numA, errA = 0, nil
+		}
+		if segB == "" {
+			numB, errB = 0, nil
+		}
+
+		if errA == nil && errB == nil {
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	default:
+		return comparePrerelease(preA, preB)
+	}
+}
+
+// comparePrerelease orders pre-release tags as semver does: identifier by
+// identifier, numeric ones numerically and below alphanumeric ones, and a
+// shorter tag first when all its identifiers are equal, so
+// alpha < alpha.1 < alpha.beta < beta.2 < beta.11 < rc.1.
+func comparePrerelease(a, b string) int {
+	idsA := strings.Split(a, ".")
+	idsB := strings.Split(b, ".")
+
+	for i := 0; i < len(idsA) && i < len(idsB); i++ {
+		numA, errA := strconv.ParseUint(idsA[i], 10, 64)
+		numB, errB := strconv.ParseUint(idsB[i], 10, 64)
+
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(idsA[i], idsB[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case len(idsA) < len(idsB):
+		return -1
+	case len(idsA) > len(idsB):
+		return 1
+	}
+	return 0
+}
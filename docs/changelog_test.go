@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2", "1.2.0", 0},
+		{"2.0.0", "10.0.0", -1},
+		{"1.0.0-beta", "1.0.0", -1},
+		{"1.0.0+build.5", "1.0.0+build.7", 0},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-1", "1.0.0-a", -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestGenerateChangelog(t *testing.T) {
+	dockDocs := &DockDocs{
+		Name: "shop",
+		Requests: []RequestDoc{
+			{Name: "orders", Method: "GET", URL: "/orders", Since: "1.9.0"},
+			{Name: "refunds", Since: "1.10.0", Description: "Refund an order"},
+			{Name: "legacy", Since: "1.0.0", Deprecated: true},
+			{Name: "health"},
+		},
+	}
+
+	got := generateChangelog(dockDocs)
+
+	order := []string{"## Deprecated", "- **legacy**", "## 1.10.0", "- **refunds** - Refund an order", "## 1.9.0", "- **orders** `GET /orders`", "## 1.0.0", "## Unversioned", "- **health**"}
+	last := -1
+	for _, want := range order {
+		i := strings.Index(got, want)
+		if i < 0 {
+			t.Fatalf("changelog missing %q:\n%s", want, got)
+		}
+		if i < last {
+			t.Errorf("%q is out of order:\n%s", want, got)
+		}
+		last = i
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+// runDocs runs an rq command line with the docs commands registered,
+// returning what it printed.
+func runDocs(t *testing.T, arguments ...string) (string, error) {
+	t.Helper()
+	app := args.New("rq")
+	Setup(app)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+
+	err = app.Run(arguments)
+	w.Close()
+	return string(<-done), err
+}
+
+// docsDock creates a dock with one documented request and moves into it.
+func docsDock(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".dock"), "shop")
+	writeFile(t, filepath.Join(dir, ".env"), "BASE_URL=https://api.shop.test\n")
+	writeFile(t, filepath.Join(dir, "users", "list.http"), "/// List every user\nGET {{BASE_URL}}/users\n")
+	t.Chdir(dir)
+	return dir
+}
+
+func TestDocsGenerateCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		file    string
+		outside bool
+		wantErr string
+	}{
+		{name: "to a file", args: []string{"-o", "out.md"}, file: "out.md"},
+		{name: "long option", args: []string{"--output", "api.md"}, file: "api.md"},
+		{name: "to stdout"},
+		{name: "outside a dock", outside: true, wantErr: "not inside an rq dock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := docsDock(t)
+			if tt.outside {
+				t.Chdir(t.TempDir())
+			}
+
+			out, err := runDocs(t, append([]string{"docs", "generate"}, tt.args...)...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("docs generate error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content := out
+			if tt.file != "" {
+				data, err := os.ReadFile(filepath.Join(dir, tt.file))
+				if err != nil {
+					t.Fatalf("documentation not written: %v", err)
+				}
+				content = string(data)
+				if want := "Documentation generated: " + tt.file; !strings.Contains(out, want) {
+					t.Errorf("output %q missing %q", out, want)
+				}
+			}
+			for _, want := range []string{"shop", "List every user", "/users"} {
+				if !strings.Contains(content, want) {
+					t.Errorf("documentation missing %q:\n%s", want, content)
+				}
+			}
+		})
+	}
+}
+
+func TestDocsExportCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{name: "default is html", args: []string{"-o", "api.html"}, file: "api.html", want: "<html"},
+		{name: "html to stdout", args: []string{"--format", "html"}, want: "<html"},
+		{name: "markdown", args: []string{"-f", "markdown", "-o", "api.md"}, file: "api.md", want: "# shop API Documentation"},
+		{name: "md alias", args: []string{"-f", "md"}, want: "# shop API Documentation"},
+		{name: "asciidoc", args: []string{"-f", "asciidoc", "-o", "api.adoc"}, file: "api.adoc", want: "= shop API Documentation"},
+		{name: "adoc alias", args: []string{"-f", "adoc"}, want: "= shop API Documentation"},
+		{name: "insomnia", args: []string{"-f", "insomnia", "-o", "insomnia.json"}, file: "insomnia.json", want: `"__export_format": 4`},
+		{name: "unknown format", args: []string{"-f", "docx"}, wantErr: true},
+		{name: "format argument", args: []string{"md"}, want: "# shop API Documentation"},
+		{name: "format and output arguments", args: []string{"markdown", "api.md"}, file: "api.md", want: "# shop API Documentation"},
+		{name: "arguments win over options", args: []string{"adoc", "api.adoc", "-f", "md", "-o", "api.md"}, file: "api.adoc", want: "= shop API Documentation"},
+		{name: "output option with a format argument", args: []string{"insomnia", "-o", "insomnia.json"}, file: "insomnia.json", want: `"__export_format": 4`},
+		{name: "unknown format argument", args: []string{"docx", "api.docx"}, wantErr: true},
+		{name: "too many arguments", args: []string{"md", "api.md", "extra"}, wantErr: true},
+		{name: "template for markdown", args: []string{"md", "--template", "brand.html"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := docsDock(t)
+
+			out, err := runDocs(t, append([]string{"docs", "export"}, tt.args...)...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content := out
+			if tt.file != "" {
+				data, err := os.ReadFile(filepath.Join(dir, tt.file))
+				if err != nil {
+					t.Fatalf("documentation not written: %v", err)
+				}
+				content = string(data)
+				if want := "Documentation exported: " + tt.file; !strings.Contains(out, want) {
+					t.Errorf("output %q missing %q", out, want)
+				}
+			}
+			if !strings.Contains(content, tt.want) {
+				t.Errorf("export missing %q:\n%.300s", tt.want, content)
+			}
+		})
+	}
+}
+
+func TestDocsServeAddress(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	_, port, _ := net.SplitHostPort(busy.Addr().String())
+	docsDock(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "loopback by default", args: []string{"docs", "serve", "--port", port}, want: "127.0.0.1:" + port},
+		{name: "--host", args: []string{"docs", "serve", "--port", port, "--host", "0.0.0.0"}, want: "0.0.0.0:" + port},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The port is taken, so serving fails right after printing the
+			// address it chose.
+			out, err := runDocs(t, tt.args...)
+			if !strings.Contains(out, "Serving documentation on http://"+tt.want+"\n") {
+				t.Errorf("output = %q, want the %s address", out, tt.want)
+			}
+			if err == nil || !strings.Contains(err.Error(), "listen tcp "+tt.want+": ") {
+				t.Errorf("error = %v, want a failure to listen on %s", err, tt.want)
+			}
+		})
+	}
+}
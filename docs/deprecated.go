@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var replacementPattern = regexp.MustCompile(`(?i)(?:use|replaced by|superseded by|see)\s+` + "`?" + `([^\s` + "`" + `,;]+)`)
+
+func deprecatedRequests(dockDocs *DockDocs) []RequestDoc {
+	var res []RequestDoc
+	for _, req := range dockDocs.Requests {
+		if req.Deprecated {
+			res = append(res, req)
+		}
+	}
+	return res
+}
+
+// findReplacement looks for hints like "use v2/users instead" in the
+// description and deprecation notes of a request.
+func findReplacement(req RequestDoc) string {
+	candidates := []string{req.Description}
+	for _, comment := range req.Comments {
+		if comment.Type == "deprecated" && comment.Content != "" {
+			candidates = append([]string{comment.Content}, candidates...)
+		}
+	}
+
+	for _, text := range candidates {
+		if matches := replacementPattern.FindStringSubmatch(text); len(matches) > 1 {
+			return strings.TrimRight(matches[1], ".")
+		}
+	}
+	return ""
+}
+
+func printDeprecatedReport(requests []RequestDoc) {
+	if len(requests) == 0 {
+		fmt.Println("No deprecated requests found")
+		return
+	}
+
+	fmt.Printf("Deprecated requests (%d):\n\n", len(requests))
+	for _, req := range requests {
+		fmt.Printf("  %s (%s)\n", req.Name, req.RelativePath)
+		if req.Method != "" {
+			fmt.Printf("    Endpoint:    %s %s\n", req.Method, req.URL)
+		}
+		if req.Since != "" {
+			fmt.Printf("    Since:       %s\n", req.Since)
+		}
+		if replacement := findReplacement(req); replacement != "" {
+			fmt.Printf("    Replacement: %s\n", replacement)
+		}
+		if req.Description != "" {
+			fmt.Printf("    Description: %s\n", req.Description)
+		}
+		fmt.Println()
+	}
+}
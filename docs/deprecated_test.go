@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFindReplacement(t *testing.T) {
+	tests := []struct {
+		name string
+		req  RequestDoc
+		want string
+	}{
+		{"none", RequestDoc{Description: "List users"}, ""},
+		{"use", RequestDoc{Description: "Old listing, use v2/users instead."}, "v2/users"},
+		{"replaced by", RequestDoc{Description: "Replaced by `users/search`."}, "users/search"},
+		{"trailing dot", RequestDoc{Description: "See users/list."}, "users/list"},
+		{
+			"note wins over description",
+			RequestDoc{
+				Description: "use from-description",
+				Comments:    []DocComment{{Type: "deprecated", Content: "superseded by from-note"}},
+			},
+			"from-note",
+		},
+	}
+	for _, tt := range tests {
+		if got := findReplacement(tt.req); got != tt.want {
+			t.Errorf("%s: findReplacement = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeprecatedRequests(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "users.http")
+	writeFile(t, path, "/// List users\n/// @deprecated use v2/users\nGET {{BASE_URL}}/users\n")
+
+	req, err := extractRequestDoc(path, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !req.Deprecated {
+		t.Fatal("@deprecated not parsed")
+	}
+	if got := findReplacement(req); got != "v2/users" {
+		t.Errorf("replacement = %q", got)
+	}
+
+	deprecated := deprecatedRequests(&DockDocs{Requests: []RequestDoc{{Name: "other"}, req}})
+	if len(deprecated) != 1 || deprecated[0].Name != req.Name {
+		t.Errorf("deprecatedRequests = %v", deprecated)
+	}
+}
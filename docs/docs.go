@@ -1,7 +1,11 @@
 package docs
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -14,6 +18,13 @@ import (
 	"github.com/marcomit/args"
 )
 
+// Errors returned by docs deprecated and docs lint after printing their
+// report, so that the command exits with a non-zero status.
+var (
+	ErrDeprecatedRequests = errors.New("deprecated requests found")
+	ErrMalformedDocs      = errors.New("malformed doc comments found")
+)
+
 type DocComment struct {
 	Type       string            // @doc, @param, @response, @example, etc.
 	Content    string            // The main content
@@ -22,21 +33,27 @@ type DocComment struct {
 }
 
 type RequestDoc struct {
-	Name         string        // Request name (filename without extension)
-	FilePath     string        // Full file path
-	RelativePath string        // Path relative to dock
-	Method       string        // HTTP method
-	URL          string        // Request URL pattern
-	Description  string        // Main description
-	Parameters   []ParamDoc    // Request parameters
-	Headers      []HeaderDoc   // Request headers
-	Responses    []ResponseDoc // Response documentation
-	Examples     []ExampleDoc  // Usage examples
-	Tags         []string      // Categories/tags
-	Since        string        // Version since when available
-	Deprecated   bool          // Whether deprecated
-	Comments     []DocComment  // All parsed comments
-	RequestBody  string        // Example request body
+	Name         string          // Request name (filename without extension)
+	FilePath     string          // Full file path
+	RelativePath string          // Path relative to dock
+	Method       string          // HTTP method
+	URL          string          // Request URL pattern
+	Description  string          // Main description
+	Parameters   []ParamDoc      // Request parameters
+	Headers      []HeaderDoc     // Request headers
+	Responses    []ResponseDoc   // Response documentation
+	Examples     []ExampleDoc    // Usage examples
+	Tags         []string        // Categories/tags
+	Since        string          // Version since when available
+	Deprecated   bool            // Whether deprecated
+	Comments     []DocComment    // All parsed comments
+	RequestBody  string          // Example request body
+	Diagnostics  []DocDiagnostic // Problems found while parsing doc comments
+}
+
+type DocDiagnostic struct {
+	Line    int    // Line number in file
+	Message string // Description of the problem
 }
 
 type ParamDoc struct {
@@ -70,15 +87,18 @@ type ExampleDoc struct {
 	Output      string `json:"output"`
 }
 
+// DockDocs is the data passed to HTML templates, so custom templates
+// passed with --template can reference any of these fields (e.g. {{.Name}}).
 type DockDocs struct {
-	Name        string                  `json:"name"`
-	Description string                  `json:"description"`
-	Version     string                  `json:"version"`
-	BaseURL     string                  `json:"base_url"`
-	Requests    []RequestDoc            `json:"requests"`
-	Groups      map[string][]RequestDoc `json:"groups"`
-	GeneratedAt time.Time               `json:"generated_at"`
-	DockPath    string                  `json:"dock_path"`
+	Name        string                  `json:"name"`         // Dock name from the .dock file
+	Description string                  `json:"description"`  // First paragraph of the dock README.md
+	Version     string                  `json:"version"`      // API_VERSION from the root config
+	BaseURL     string                  `json:"base_url"`     // BASE_URL from the root config
+	Requests    []RequestDoc            `json:"requests"`     // All requests, manifest order first, then by name
+	Groups      map[string][]RequestDoc `json:"groups"`       // Requests grouped by directory ("Root" for the dock root) or manifest group
+	GroupOrder  []string                `json:"group_order"`  // Group names, manifest groups first, then alphabetically
+	GeneratedAt time.Time               `json:"generated_at"` // Time the documentation was generated
+	DockPath    string                  `json:"dock_path"`    // Absolute path of the dock
 }
 
 func Setup(app *args.Parser) {
@@ -86,101 +106,177 @@ func Setup(app *args.Parser) {
 
 	docs.
 		Command("generate", "Generate the documentation").
-		Option("output", "o", "Output path of the documentation")
+		Option("output", "o", "Output path of the documentation").
+		Flag("with-examples", "we", "Include the last response recorded with rq run --record for each request").
+		Action(func(r *args.Result) error {
+			return generateDocs(r.Options["output"], r.Flag("with-examples"))
+		})
 
 	docs.
 		Command("serve", "Serve the documentation as webapp").
-		Option("port", "p", "Server port")
+		Option("port", "p", "Server port").
+		Option("host", "ho", "Interface to listen on, like 0.0.0.0 for every interface (default: 127.0.0.1)").
+		Option("template", "t", "Custom html/template file used to render the documentation").
+		Action(func(r *args.Result) error {
+			host, ok := r.Options["host"]
+			if !ok {
+				host = "127.0.0.1"
+			}
+			port, ok := r.Options["port"]
+			if !ok {
+				port = "8080"
+			}
+			return serveDocs(net.JoinHostPort(host, port), r.Options["template"])
+		})
 
 	docs.
-		Command("export", "Export documentation").
-		Option("output", "o", "Output path of the documentation").
-		Option("format", "format", "Format type of the documentation")
+		Command("export", "Export documentation, e.g. rq docs export pdf api.pdf").
+		Positional("format").
+		Positional("output").
+		Option("output", "o", "Output path of the documentation, when not given as the second argument").
+		Option("format", "f", "Format of the documentation, when not given as the first argument (default: html)", "html", "markdown", "md", "asciidoc", "adoc", "insomnia", "pdf").
+		Option("template", "t", "Custom html/template file used to render html and pdf documentation").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) > 2 {
+				return fmt.Errorf("expected a format and an output path, got %d arguments", len(r.Positionals))
+			}
 
-}
+			format, ok := r.Options["format"]
+			if len(r.Positionals) > 0 {
+				format = r.Positionals[0]
+			} else if !ok {
+				format = "html"
+			}
+			output := r.Options["output"]
+			if len(r.Positionals) > 1 {
+				output = r.Positionals[1]
+			}
+			return exportDocs(format, output, r.Options["template"])
+		})
 
-func Parse(args []string) {
-	if len(args) == 0 {
-		generateDocs("")
-		return
-	}
+	docs.
+		Command("changelog", "Show requests grouped by the version they were added in").
+		Option("output", "o", "Output path of the changelog").
+		Action(func(r *args.Result) error {
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
 
-	switch args[0] {
-	case "generate", "gen":
-		output := ""
-		if len(args) > 1 {
-			output = args[1]
-		}
-		generateDocs(output)
+			dockDocs, err := extractDockDocs(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to extract documentation: %w", err)
+			}
 
-	case "serve":
-		port := "8080"
-		if len(args) > 1 {
-			port = args[1]
-		}
-		serveDocs(port)
+			content := generateChangelog(dockDocs)
 
-	case "export":
-		format := "html"
-		output := ""
-		if len(args) > 1 {
-			format = args[1]
-		}
-		if len(args) > 2 {
-			output = args[2]
-		}
-		exportDocs(format, output)
+			output, ok := r.Options["output"]
+			if !ok {
+				fmt.Print(content)
+				return nil
+			}
 
-	case "--help", "-h":
-		printDocsHelp()
+			if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+				return fmt.Errorf("failed to save changelog: %w", err)
+			}
+			fmt.Printf("Changelog generated: %s\n", output)
+			return nil
+		})
 
-	default:
-		fmt.Printf("Error: unknown docs subcommand '%s'\n", args[0])
-		printDocsHelp()
-		os.Exit(1)
-	}
-}
+	docs.
+		Command("search", "Search the documentation of the requests").
+		Positional("query").
+		Flag("regex", "r", "Treat the query as a regular expression").
+		Flag("case-sensitive", "cs", "Match the query case-sensitively").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Missing search query")
+			}
+
+			re, err := compileSearchQuery(r.Positionals[0], r.Flag("regex"), r.Flag("case-sensitive"))
+			if err != nil {
+				return err
+			}
+
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			dockDocs, err := extractDockDocs(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to extract documentation: %w", err)
+			}
+
+			printSearchResults(searchRequests(dockDocs, re), re)
+			return nil
+		})
+
+	docs.
+		Command("deprecated", "List the deprecated requests").
+		Flag("fail-on-deprecated", "f", "Exit with an error if any request is deprecated").
+		Action(func(r *args.Result) error {
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			dockDocs, err := extractDockDocs(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to extract documentation: %w", err)
+			}
+
+			deprecated := deprecatedRequests(dockDocs)
+			printDeprecatedReport(deprecated)
 
-func printDocsHelp() {
-	fmt.Println("Usage: rq docs <subcommand> [options]")
-	fmt.Println()
-	fmt.Println("Subcommands:")
-	fmt.Println("  generate [output]     Generate documentation (default: stdout)")
-	fmt.Println("  serve [port]          Serve documentation on HTTP server (default: 8080)")
-	fmt.Println("  export <format> [output]  Export docs in different formats")
-	fmt.Println()
-	fmt.Println("Export formats:")
-	fmt.Println("  html                  HTML documentation")
-	fmt.Println("  markdown, md          Markdown documentation")
-	fmt.Println("  json                  JSON documentation")
-	fmt.Println("  openapi               OpenAPI 3.0 specification")
-	fmt.Println()
-	fmt.Println("Examples:")
-	fmt.Println("  rq docs generate")
-	fmt.Println("  rq docs serve 3000")
-	fmt.Println("  rq docs export html docs.html")
-	fmt.Println("  rq docs export openapi api-spec.yaml")
+			if r.Flag("fail-on-deprecated") && len(deprecated) > 0 {
+				return ErrDeprecatedRequests
+			}
+			return nil
+		})
+
+	docs.
+		Command("lint", "Report malformed doc comments").
+		Action(func(r *args.Result) error {
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			dockDocs, err := extractDockDocs(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to extract documentation: %w", err)
+			}
+
+			if lintDocs(dockDocs) > 0 {
+				return ErrMalformedDocs
+			}
+			return nil
+		})
 }
 
-func generateDocs(output string) {
-	ctx := dock.GetContext()
+func generateDocs(output string, withExamples bool) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
 
 	dockDocs, err := extractDockDocs(ctx)
 	if err != nil {
-		fmt.Printf("Error extracting documentation: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to extract documentation: %w", err)
+	}
+
+	if withExamples {
+		attachRecordedExamples(dockDocs)
 	}
 
 	if output == "" {
 		printDocsToStdout(dockDocs)
-	} else {
-		err := saveDocs(dockDocs, output)
-		if err != nil {
-			fmt.Printf("Error saving documentation: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Printf("Documentation generated: %s\n", output)
+		return nil
 	}
+
+	if err := saveDocs(dockDocs, output); err != nil {
+		return fmt.Errorf("failed to save documentation: %w", err)
+	}
+	fmt.Printf("Documentation generated: %s\n", output)
+	return nil
 }
 
 func extractDockDocs(ctx *dock.RqContext) (*DockDocs, error) {
@@ -212,13 +308,17 @@ func extractDockDocs(ctx *dock.RqContext) (*DockDocs, error) {
 		}
 	}
 
-	err := filepath.Walk(ctx.Dock, func(path string, info os.FileInfo, err error) error {
+	manifest, err := dock.LoadManifest(ctx.Dock)
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(ctx.Dock, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
-		if !info.IsDir() && (strings.HasSuffix(path, ".http") ||
-			strings.HasSuffix(path, ".ws") || strings.HasSuffix(path, ".grpc")) {
+		if !info.IsDir() && dock.IsRequestFile(path) {
 
 			reqDoc, err := extractRequestDoc(path, ctx.Dock)
 			if err != nil {
@@ -232,7 +332,8 @@ func extractDockDocs(ctx *dock.RqContext) (*DockDocs, error) {
 			if dir == "." {
 				dir = "Root"
 			}
-			dockDocs.Groups[dir] = append(dockDocs.Groups[dir], reqDoc)
+			group := manifest.Group(reqDoc.RelativePath, dir)
+			dockDocs.Groups[group] = append(dockDocs.Groups[group], reqDoc)
 		}
 
 		return nil
@@ -242,10 +343,22 @@ func extractDockDocs(ctx *dock.RqContext) (*DockDocs, error) {
 		return nil, fmt.Errorf("failed to walk dock directory: %w", err)
 	}
 
-	sort.Slice(dockDocs.Requests, func(i, j int) bool {
-		return dockDocs.Requests[i].Name < dockDocs.Requests[j].Name
+	sort.SliceStable(dockDocs.Requests, func(i, j int) bool {
+		a, b := dockDocs.Requests[i], dockDocs.Requests[j]
+		if order := manifest.Compare(a.RelativePath, b.RelativePath); order != 0 {
+			return order < 0
+		}
+		return a.Name < b.Name
 	})
 
+	for group, requests := range dockDocs.Groups {
+		sort.SliceStable(requests, func(i, j int) bool {
+			return manifest.Compare(requests[i].RelativePath, requests[j].RelativePath) < 0
+		})
+		dockDocs.GroupOrder = append(dockDocs.GroupOrder, group)
+	}
+	manifest.SortGroups(dockDocs.GroupOrder)
+
 	return dockDocs, nil
 }
 
@@ -274,6 +387,7 @@ func extractRequestDoc(filePath, dockPath string) (RequestDoc, error) {
 
 	inDocBlock := false
 	currentDocBlock := []string{}
+	docBlockStart := 0
 
 	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
@@ -282,11 +396,12 @@ func extractRequestDoc(filePath, dockPath string) (RequestDoc, error) {
 			if !inDocBlock {
 				inDocBlock = true
 				currentDocBlock = []string{}
+				docBlockStart = i + 1
 			}
 
 			docLine := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(trimmed, "///"), "##"))
 			currentDocBlock = append(currentDocBlock, docLine)
-		} else if inDocBlock && (trimmed == "" || strings.HasPrefix(trimmed, "#")) {
+		} else if inDocBlock && (trimmed == "" || strings.HasPrefix(trimmed, "#") && !isDirective(trimmed)) {
 			if strings.HasPrefix(trimmed, "#") {
 				docLine := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
 				currentDocBlock = append(currentDocBlock, docLine)
@@ -295,7 +410,7 @@ func extractRequestDoc(filePath, dockPath string) (RequestDoc, error) {
 			}
 		} else {
 			if inDocBlock {
-				processDocBlock(currentDocBlock, &reqDoc, i)
+				processDocBlock(currentDocBlock, &reqDoc, docBlockStart)
 				inDocBlock = false
 				currentDocBlock = []string{}
 			}
@@ -316,23 +431,20 @@ func extractRequestDoc(filePath, dockPath string) (RequestDoc, error) {
 	}
 
 	if inDocBlock {
-		processDocBlock(currentDocBlock, &reqDoc, len(lines))
+		processDocBlock(currentDocBlock, &reqDoc, docBlockStart)
 	}
 
 	return reqDoc, nil
 }
 
-func processDocBlock(lines []string, reqDoc *RequestDoc, lineNum int) {
-	content := strings.Join(lines, "\n")
-	content = strings.TrimSpace(content)
-
-	if content == "" {
-		return
-	}
-
-	docLines := strings.Split(content, "\n")
+// isDirective reports whether a plain comment holds a request directive such
+// as "# @assert", which ends a doc block instead of continuing it.
+func isDirective(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(line, "#")), "@")
+}
 
-	for _, line := range docLines {
+func processDocBlock(lines []string, reqDoc *RequestDoc, startLine int) {
+	for i, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
@@ -340,7 +452,7 @@ func processDocBlock(lines []string, reqDoc *RequestDoc, lineNum int) {
 
 		comment := DocComment{
 			Content:    line,
-			LineNumber: lineNum,
+			LineNumber: startLine + i,
 			Attributes: make(map[string]string),
 		}
 
@@ -359,6 +471,7 @@ func parseDocTag(line string, reqDoc *RequestDoc, comment *DocComment) {
 	matches := tagRegex.FindStringSubmatch(line)
 
 	if len(matches) < 2 {
+		reqDoc.addDiagnostic(comment.LineNumber, "malformed doc tag: %s", line)
 		return
 	}
 
@@ -370,7 +483,11 @@ func parseDocTag(line string, reqDoc *RequestDoc, comment *DocComment) {
 	comment.Content = content
 
 	if attributes != "" {
-		parseAttributes(attributes, comment.Attributes)
+		for _, problem := range parseAttributes(attributes, comment.Attributes) {
+			reqDoc.addDiagnostic(comment.LineNumber, "@%s: %s", tag, problem)
+		}
+	} else if strings.HasPrefix(content, "(") {
+		reqDoc.addDiagnostic(comment.LineNumber, "@%s: unterminated attribute list", tag)
 	}
 
 	switch tag {
@@ -390,6 +507,8 @@ func parseDocTag(line string, reqDoc *RequestDoc, comment *DocComment) {
 		}
 		if param.Name != "" {
 			reqDoc.Parameters = append(reqDoc.Parameters, param)
+		} else {
+			reqDoc.addDiagnostic(comment.LineNumber, "@%s is missing a name attribute", tag)
 		}
 
 	case "header":
@@ -401,6 +520,8 @@ func parseDocTag(line string, reqDoc *RequestDoc, comment *DocComment) {
 		}
 		if header.Name != "" {
 			reqDoc.Headers = append(reqDoc.Headers, header)
+		} else {
+			reqDoc.addDiagnostic(comment.LineNumber, "@header is missing a name attribute")
 		}
 
 	case "response":
@@ -413,6 +534,8 @@ func parseDocTag(line string, reqDoc *RequestDoc, comment *DocComment) {
 		}
 		if response.Status != "" {
 			reqDoc.Responses = append(reqDoc.Responses, response)
+		} else {
+			reqDoc.addDiagnostic(comment.LineNumber, "@response is missing a status attribute")
 		}
 
 	case "example":
@@ -425,31 +548,49 @@ func parseDocTag(line string, reqDoc *RequestDoc, comment *DocComment) {
 		reqDoc.Examples = append(reqDoc.Examples, example)
 
 	case "tag", "tags":
-		if content != "" {
-			tags := strings.Split(content, ",")
-			for _, tag := range tags {
-				reqDoc.Tags = append(reqDoc.Tags, strings.TrimSpace(tag))
-			}
-		}
+		reqDoc.Tags = append(reqDoc.Tags, splitTags(content)...)
 
 	case "since":
 		reqDoc.Since = content
 
 	case "deprecated":
 		reqDoc.Deprecated = true
+
+	default:
+		reqDoc.addDiagnostic(comment.LineNumber, "unknown tag @%s", tag)
 	}
 }
 
-func parseAttributes(attrStr string, attrs map[string]string) {
+func parseAttributes(attrStr string, attrs map[string]string) []string {
+	var problems []string
+
 	pairs := strings.Split(attrStr, ",")
 	for _, pair := range pairs {
-		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
-		if len(kv) == 2 {
-			key := strings.TrimSpace(kv[0])
-			value := strings.Trim(strings.TrimSpace(kv[1]), `"'`)
-			attrs[key] = value
+		pair = strings.TrimSpace(pair)
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			problems = append(problems, fmt.Sprintf("attribute %q is missing '='", pair))
+			continue
 		}
+
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			problems = append(problems, fmt.Sprintf("attribute %q has an empty name", pair))
+			continue
+		}
+
+		value := strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+		attrs[key] = value
 	}
+
+	return problems
+}
+
+func (reqDoc *RequestDoc) addDiagnostic(line int, format string, a ...any) {
+	reqDoc.Diagnostics = append(reqDoc.Diagnostics, DocDiagnostic{
+		Line:    line,
+		Message: fmt.Sprintf(format, a...),
+	})
 }
 
 func parseHTTPRequestLine(line string) (method, url string) {
@@ -519,10 +660,10 @@ func printDocsToStdout(dockDocs *DockDocs) {
 
 	fmt.Printf("**Generated:** %s\n\n", dockDocs.GeneratedAt.Format("2006-01-02 15:04:05"))
 
-	for groupName, requests := range dockDocs.Groups {
+	for _, groupName := range sortedGroupNames(dockDocs) {
 		fmt.Printf("## %s\n\n", groupName)
 
-		for _, req := range requests {
+		for _, req := range dockDocs.Groups[groupName] {
 			printRequestDoc(req)
 		}
 	}
@@ -567,7 +708,7 @@ func printRequestDoc(req RequestDoc) {
 		for _, resp := range req.Responses {
 			fmt.Printf("- **%s**: %s\n", resp.Status, resp.Description)
 			if resp.Example != "" {
-				fmt.Printf("  ```json\n  %s\n  ```\n", resp.Example)
+				fmt.Printf("  ```json\n%s\n  ```\n", indent(resp.Example, "  "))
 			}
 		}
 		fmt.Println()
@@ -578,7 +719,7 @@ func printRequestDoc(req RequestDoc) {
 		fmt.Printf("```json\n%s\n```\n\n", req.RequestBody)
 	}
 
-	fmt.Println("---\n")
+	fmt.Print("---\n\n")
 }
 
 func saveDocs(dockDocs *DockDocs, output string) error {
@@ -605,21 +746,39 @@ func generateMarkdownDocs(dockDocs *DockDocs) string {
 
 	md.WriteString(fmt.Sprintf("**Generated:** %s\n\n", dockDocs.GeneratedAt.Format("2006-01-02 15:04:05")))
 
+	groupNames := sortedGroupNames(dockDocs)
+
+	slugs := newSlugger()
+	slugs.slug(fmt.Sprintf("%s API Documentation", dockDocs.Name))
+	slugs.slug("Table of Contents")
+
+	groupAnchors := make(map[string]string)
+	requestAnchors := make(map[string][]string)
+	for _, groupName := range groupNames {
+		groupAnchors[groupName] = slugs.slug(groupName)
+		for _, req := range dockDocs.Groups[groupName] {
+			requestAnchors[groupName] = append(requestAnchors[groupName], slugs.slug(req.Name))
+			for _, example := range req.Examples {
+				if example.Title != "" {
+					slugs.slug(example.Title)
+				}
+			}
+		}
+	}
+
 	md.WriteString("## Table of Contents\n\n")
-	for groupName, requests := range dockDocs.Groups {
-		md.WriteString(fmt.Sprintf("- [%s](#%s)\n", groupName,
-			strings.ToLower(strings.ReplaceAll(groupName, " ", "-"))))
-		for _, req := range requests {
-			md.WriteString(fmt.Sprintf("  - [%s](#%s)\n", req.Name,
-				strings.ToLower(strings.ReplaceAll(req.Name, " ", "-"))))
+	for _, groupName := range groupNames {
+		md.WriteString(fmt.Sprintf("- [%s](#%s)\n", groupName, groupAnchors[groupName]))
+		for i, req := range dockDocs.Groups[groupName] {
+			md.WriteString(fmt.Sprintf("  - [%s](#%s)\n", req.Name, requestAnchors[groupName][i]))
 		}
 	}
 	md.WriteString("\n")
 
-	for groupName, requests := range dockDocs.Groups {
+	for _, groupName := range groupNames {
 		md.WriteString(fmt.Sprintf("## %s\n\n", groupName))
 
-		for _, req := range requests {
+		for _, req := range dockDocs.Groups[groupName] {
 			md.WriteString(generateRequestMarkdown(req))
 		}
 	}
@@ -683,7 +842,7 @@ func generateRequestMarkdown(req RequestDoc) string {
 		for _, resp := range req.Responses {
 			md.WriteString(fmt.Sprintf("- **%s**: %s\n", resp.Status, resp.Description))
 			if resp.Example != "" {
-				md.WriteString(fmt.Sprintf("  ```json\n  %s\n  ```\n", resp.Example))
+				md.WriteString(fmt.Sprintf("  ```json\n%s\n  ```\n", indent(resp.Example, "  ")))
 			}
 		}
 		md.WriteString("\n")
@@ -717,16 +876,127 @@ func generateRequestMarkdown(req RequestDoc) string {
 	return md.String()
 }
 
-func serveDocs(port string) {
-	fmt.Printf("Documentation server not yet implemented\n")
-	fmt.Printf("Will serve on http://localhost:%s\n", port)
+// indent prefixes every line of text, so a multi-line example stays inside
+// the list item it belongs to.
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
 }
 
-func exportDocs(format, output string) {
-	fmt.Printf("Export to %s format not yet implemented\n", format)
-	if output != "" {
-		fmt.Printf("Would save to: %s\n", output)
+func serveDocs(address, templatePath string) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		dockDocs, err := extractDockDocs(ctx)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to extract documentation: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		content, err := generateHTMLDocs(dockDocs, templatePath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, content)
+	}
+
+	fmt.Printf("Serving documentation on http://%s\n", address)
+	if err := http.ListenAndServe(address, http.HandlerFunc(handler)); err != nil {
+		return fmt.Errorf("failed to serve documentation: %w", err)
+	}
+	return nil
+}
+
+func exportDocs(format, output, templatePath string) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+
+	dockDocs, err := extractDockDocs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to extract documentation: %w", err)
+	}
+
+	if templatePath != "" && format != "html" && format != "pdf" {
+		return fmt.Errorf("--template only applies to the html and pdf formats, not %s", format)
+	}
+
+	var content string
+	switch format {
+	case "html":
+		content, err = generateHTMLDocs(dockDocs, templatePath)
+		if err != nil {
+			return fmt.Errorf("failed to render documentation: %w", err)
+		}
+	case "markdown", "md":
+		content = generateMarkdownDocs(dockDocs)
+	case "asciidoc", "adoc":
+		content = generateAsciiDocDocs(dockDocs)
+	case "insomnia":
+		content, err = generateInsomniaExport(ctx, dockDocs)
+		if err != nil {
+			return fmt.Errorf("failed to export Insomnia collection: %w", err)
+		}
+	case "pdf":
+		if err := generatePDFDocs(dockDocs, output, templatePath); err != nil {
+			return fmt.Errorf("failed to export PDF: %w", err)
+		}
+		fmt.Printf("Documentation exported: %s\n", output)
+		return nil
+	default:
+		return fmt.Errorf("export to %s format not yet implemented", format)
+	}
+
+	if output == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to save documentation: %w", err)
+	}
+	fmt.Printf("Documentation exported: %s\n", output)
+	return nil
+}
+
+func lintDocs(dockDocs *DockDocs) int {
+	count := 0
+	for _, req := range dockDocs.Requests {
+		for _, diagnostic := range req.Diagnostics {
+			fmt.Printf("%s:%d: %s\n", req.RelativePath, diagnostic.Line, diagnostic.Message)
+			count++
+		}
+	}
+
+	if count == 0 {
+		fmt.Println("No problems found")
+	} else {
+		fmt.Printf("\n%d problems found\n", count)
+	}
+	return count
+}
+
+func sortedGroupNames(dockDocs *DockDocs) []string {
+	if dockDocs.GroupOrder != nil {
+		return dockDocs.GroupOrder
+	}
+
+	groupNames := make([]string, 0, len(dockDocs.Groups))
+	for groupName := range dockDocs.Groups {
+		groupNames = append(groupNames, groupName)
 	}
+	sort.Strings(groupNames)
+	return groupNames
 }
 
 func fileExists(path string) bool {
@@ -0,0 +1,220 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"rq/dock"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// sampleDocs returns a small dock covering every section the exporters
+// write.
+func sampleDocs() *DockDocs {
+	login := RequestDoc{
+		Name:        "login",
+		Method:      "POST",
+		URL:         "{{BASE_URL}}/auth/login",
+		Description: "Log in with a username and password",
+		Tags:        []string{"auth", "public"},
+		Parameters: []ParamDoc{
+			{Name: "username", Type: "string", Required: true, Description: "Account name", Example: "alice"},
+			{Name: "mode", Type: "string", Description: "password | token", Example: "password"},
+		},
+		Headers: []HeaderDoc{
+			{Name: "Content-Type", Required: true, Description: "Body format", Example: "application/json"},
+		},
+		Responses: []ResponseDoc{
+			{Status: "200", Description: "Logged in", Example: "{\n  \"token\": \"abc\"\n}"},
+			{Status: "401", Description: "Wrong credentials"},
+		},
+		RequestBody: "{\n  \"username\": \"alice\"\n}",
+		Examples: []ExampleDoc{
+			{Title: "Log in", Description: "With the dev account", Code: "rq run auth/login", Output: "{\"token\": \"abc\"}"},
+		},
+	}
+	users := RequestDoc{
+		Name:        "users",
+		Method:      "GET",
+		URL:         "{{BASE_URL}}/users",
+		Description: "List users",
+		Deprecated:  true,
+	}
+
+	return &DockDocs{
+		Name:        "shop",
+		Description: "The shop API.",
+		Version:     "1.2.0",
+		BaseURL:     "https://api.example.com",
+		Requests:    []RequestDoc{login, users},
+		Groups:      map[string][]RequestDoc{"auth": {login}, "Root": {users}},
+		GroupOrder:  []string{"Root", "auth"},
+		GeneratedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// checkGolden compares got with testdata/name, rewriting the file when the
+// tests run with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s:\n%s", path, got)
+	}
+}
+
+func TestExtractRequestDocDiagnostics(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []DocDiagnostic
+	}{
+		{
+			"clean",
+			"/// List users\n/// @param(name=page, type=int) Page number\n/// @response(status=200) OK\nGET /users\n",
+			nil,
+		},
+		{
+			"unknown tag",
+			"/// List users\n/// @sinse 1.0\nGET /users\n",
+			[]DocDiagnostic{{Line: 2, Message: "unknown tag @sinse"}},
+		},
+		{
+			"malformed tag",
+			"/// @\nGET /users\n",
+			[]DocDiagnostic{{Line: 1, Message: "malformed doc tag: @"}},
+		},
+		{
+			"missing attributes",
+			"GET /users\n\n/// @param Page number\n/// @header(required=true) Auth\n/// @response Fine\n",
+			[]DocDiagnostic{
+				{Line: 3, Message: "@param is missing a name attribute"},
+				{Line: 4, Message: "@header is missing a name attribute"},
+				{Line: 5, Message: "@response is missing a status attribute"},
+			},
+		},
+		{
+			"bad attributes",
+			"/// @param(name=id, type) Id\n/// @param(=x, name=q) Query\n/// @param (name=open Open\nGET /users\n",
+			[]DocDiagnostic{
+				{Line: 1, Message: `@param: attribute "type" is missing '='`},
+				{Line: 2, Message: `@param: attribute "=x" has an empty name`},
+				{Line: 3, Message: "@param: unterminated attribute list"},
+				{Line: 3, Message: "@param is missing a name attribute"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "users.http")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := extractRequestDoc(path, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(req.Diagnostics, tt.want) {
+				t.Errorf("diagnostics = %v, want %v", req.Diagnostics, tt.want)
+			}
+
+			if got := lintDocs(&DockDocs{Requests: []RequestDoc{req}}); got != len(tt.want) {
+				t.Errorf("lintDocs = %d, want %d", got, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestExtractDockDocsManifest(t *testing.T) {
+	tests := []struct {
+		name         string
+		manifest     string
+		wantRequests []string
+		wantGroups   []string
+		wantInGroup  map[string][]string
+	}{
+		{
+			name:         "without a manifest",
+			wantRequests: []string{"users/create", "health", "users/list", "auth/signin"}, // By file name
+			wantGroups:   []string{"Root", "auth", "users"},
+			wantInGroup:  map[string][]string{"users": {"users/create", "users/list"}},
+		},
+		{
+			name:         "manifest order and groups",
+			manifest:     "[Users]\nusers/list\nusers/create\n[Authentication]\nauth/signin.http\n",
+			wantRequests: []string{"users/list", "users/create", "auth/signin", "health"},
+			wantGroups:   []string{"Users", "Authentication", "Root"},
+			wantInGroup:  map[string][]string{"Users": {"users/list", "users/create"}, "Root": {"health"}},
+		},
+		{
+			name:         "partial manifest",
+			manifest:     "users/list\n",
+			wantRequests: []string{"users/list", "users/create", "health", "auth/signin"},
+			wantGroups:   []string{"Root", "auth", "users"},
+			wantInGroup:  map[string][]string{"users": {"users/list", "users/create"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ".dock"), "shop")
+			for _, name := range []string{"health", "users/list", "users/create", "auth/signin"} {
+				writeFile(t, filepath.Join(dir, filepath.FromSlash(name)+".http"), "GET /"+name+"\n")
+			}
+			if tt.manifest != "" {
+				writeFile(t, filepath.Join(dir, ".rq", "manifest"), tt.manifest)
+			}
+			ctx, err := dock.ContextAt(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dockDocs, err := extractDockDocs(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := requestNames(dockDocs.Requests); !slices.Equal(got, tt.wantRequests) {
+				t.Errorf("Requests = %q, want %q", got, tt.wantRequests)
+			}
+			if got := sortedGroupNames(dockDocs); !slices.Equal(got, tt.wantGroups) {
+				t.Errorf("groups = %q, want %q", got, tt.wantGroups)
+			}
+			for group, want := range tt.wantInGroup {
+				if got := requestNames(dockDocs.Groups[group]); !slices.Equal(got, want) {
+					t.Errorf("group %s = %q, want %q", group, got, want)
+				}
+			}
+		})
+	}
+}
+
+func requestNames(requests []RequestDoc) []string {
+	names := make([]string, len(requests))
+	for i, req := range requests {
+		names[i] = strings.TrimSuffix(filepath.ToSlash(req.RelativePath), ".http")
+	}
+	return names
+}
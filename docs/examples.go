@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"rq/snapshot"
+)
+
+const redacted = "[REDACTED]"
+
+var secretKeyPattern = regexp.MustCompile(`(?i)(token|secret|passw(or)?d|api[_-]?key|authorization|credential|session|cookie)`)
+
+var secretPairPattern = regexp.MustCompile(`(?i)([\w-]*(?:token|secret|passw(?:or)?d|api[_-]?key|authorization|credential|session|cookie)[\w-]*\s*[=:]\s*)("[^"]*"|[^\s&,;]+)`)
+
+// attachRecordedExamples adds the latest recorded response of every request
+// (see `rq run`) to its documented responses.
+func attachRecordedExamples(dockDocs *DockDocs) {
+	examples := make(map[string]ResponseDoc)
+	for _, req := range dockDocs.Requests {
+		if example, ok := recordedExample(dockDocs.DockPath, req); ok {
+			examples[req.FilePath] = example
+		}
+	}
+
+	for i, req := range dockDocs.Requests {
+		if example, ok := examples[req.FilePath]; ok {
+			dockDocs.Requests[i].Responses = append(req.Responses, example)
+		}
+	}
+
+	for _, requests := range dockDocs.Groups {
+		for i, req := range requests {
+			if example, ok := examples[req.FilePath]; ok {
+				requests[i].Responses = append(req.Responses, example)
+			}
+		}
+	}
+}
+
+func recordedExample(dockPath string, req RequestDoc) (ResponseDoc, bool) {
+	name := strings.TrimSuffix(req.RelativePath, filepath.Ext(req.RelativePath))
+	snap, err := snapshot.Latest(dockPath, filepath.ToSlash(name))
+	if err != nil || snap == nil || !utf8.Valid(snap.Body) {
+		return ResponseDoc{}, false
+	}
+
+	contentType := ""
+	for key, values := range snap.Headers {
+		if strings.EqualFold(key, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+
+	return ResponseDoc{
+		Status:      snap.Status,
+		Description: "Recorded response (" + snap.RecordedAt.Format("2006-01-02 15:04:05") + ")",
+		ContentType: contentType,
+		Example:     redactSecrets(string(snap.Body)),
+	}, true
+}
+
+func redactSecrets(body string) string {
+	var data any
+	if err := json.Unmarshal([]byte(body), &data); err == nil {
+		content, err := json.MarshalIndent(redactValue(data), "", "  ")
+		if err == nil {
+			return string(content)
+		}
+	}
+
+	return secretPairPattern.ReplaceAllString(body, "${1}"+redacted)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if secretKeyPattern.MatchString(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"strings"
+	"testing"
+
+	"rq/snapshot"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{
+			"json",
+			`{"user": {"name": "alice", "access_token": "abc"}, "items": [{"api_key": "k"}]}`,
+			"{\n  \"items\": [\n    {\n      \"api_key\": \"[REDACTED]\"\n    }\n  ],\n  \"user\": {\n    \"access_token\": \"[REDACTED]\",\n    \"name\": \"alice\"\n  }\n}",
+		},
+		{"form", "user=alice&password=hunter2&x=1", "user=alice&password=[REDACTED]&x=1"},
+		{"text", `Session-Id: "abc def"; other`, `Session-Id: [REDACTED]; other`},
+		{"nothing", "hello", "hello"},
+	}
+	for _, tt := range tests {
+		if got := redactSecrets(tt.body); got != tt.want {
+			t.Errorf("%s: redactSecrets =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAttachRecordedExamples(t *testing.T) {
+	dir := t.TempDir()
+	headers := map[string][]string{"content-type": {"application/json"}}
+	snapshot.Save(dir, snapshot.New("auth/login", "POST", "http://x/login", 200, "200 OK", headers, []byte(`{"token": "abc"}`)))
+	snapshot.Save(dir, snapshot.New("logo", "GET", "http://x/logo", 200, "200 OK", nil, []byte{0xff, 0xd8, 0xff}))
+
+	login := RequestDoc{Name: "login", FilePath: dir + "/auth/login.http", RelativePath: "auth/login.http"}
+	logo := RequestDoc{Name: "logo", FilePath: dir + "/logo.http", RelativePath: "logo.http"}
+	dockDocs := &DockDocs{
+		DockPath: dir,
+		Requests: []RequestDoc{login, logo},
+		Groups:   map[string][]RequestDoc{"auth": {login}, "Root": {logo}},
+	}
+
+	attachRecordedExamples(dockDocs)
+
+	responses := dockDocs.Requests[0].Responses
+	if len(responses) != 1 || len(dockDocs.Groups["auth"][0].Responses) != 1 {
+		t.Fatalf("login responses = %v", responses)
+	}
+	if responses[0].ContentType != "application/json" || !strings.Contains(responses[0].Example, redacted) {
+		t.Errorf("example = %+v", responses[0])
+	}
+	if len(dockDocs.Requests[1].Responses) != 0 {
+		t.Errorf("binary snapshot was attached: %v", dockDocs.Requests[1].Responses)
+	}
+}
+
+func TestMultilineExamplesStayIndented(t *testing.T) {
+	checkGolden(t, "shop.md", generateMarkdownDocs(sampleDocs()))
+}
+
+func TestIndent(t *testing.T) {
+	if got := indent("{\n  \"a\": 1\n}", "  "); got != "  {\n    \"a\": 1\n  }" {
+		t.Errorf("indent = %q", got)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} API Documentation</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+  h1 { border-bottom: 2px solid #eee; padding-bottom: .3em; }
+  h2 { border-bottom: 1px solid #eee; padding-bottom: .2em; margin-top: 2em; }
+  .request { margin: 1.5em 0; page-break-inside: avoid; }
+  .endpoint { font-family: monospace; background: #f6f8fa; padding: .4em .6em; border-radius: 4px; display: inline-block; }
+  .method { font-weight: bold; color: #0366d6; }
+  .deprecated { color: #b31d28; font-weight: bold; }
+  table { border-collapse: collapse; width: 100%; margin: .5em 0 1em; }
+  th, td { border: 1px solid #ddd; padding: .4em .6em; text-align: left; vertical-align: top; }
+  th { background: #f6f8fa; }
+  pre { background: #f6f8fa; padding: .8em; border-radius: 4px; overflow-x: auto; white-space: pre-wrap; }
+  @media print {
+    body { max-width: none; margin: 0; }
+    h2 { page-break-before: always; }
+    h2:first-of-type { page-break-before: avoid; }
+    pre { border: 1px solid #ddd; }
+  }
+</style>
+</head>
+<body>
+<h1>{{.Name}} API Documentation</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{if .BaseURL}}<p><strong>Base URL:</strong> <code>{{.BaseURL}}</code></p>{{end}}
+{{if .Version}}<p><strong>Version:</strong> {{.Version}}</p>{{end}}
+<p><strong>Generated:</strong> {{.GeneratedAt.Format "2006-01-02 15:04:05"}}</p>
+{{range $group := .GroupOrder}}
+<h2>{{$group}}</h2>
+{{range index $.Groups $group}}
+<div class="request" id="{{.RelativePath}}">
+  <h3>{{.Name}}</h3>
+  {{if .Method}}<p class="endpoint"><span class="method">{{.Method}}</span> {{.URL}}</p>{{end}}
+  {{if .Description}}<p>{{.Description}}</p>{{end}}
+  {{if .Deprecated}}<p class="deprecated">Deprecated</p>{{end}}
+  {{if .Tags}}<p><strong>Tags:</strong> {{join .Tags ", "}}</p>{{end}}
+  {{if .Parameters}}
+  <h4>Parameters</h4>
+  <table>
+    <tr><th>Name</th><th>Type</th><th>Required</th><th>Description</th><th>Example</th></tr>
+    {{range .Parameters}}<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{if .Required}}Yes{{else}}No{{end}}</td><td>{{.Description}}</td><td>{{.Example}}</td></tr>
+    {{end}}
+  </table>
+  {{end}}
+  {{if .Headers}}
+  <h4>Headers</h4>
+  <table>
+    <tr><th>Name</th><th>Required</th><th>Description</th><th>Example</th></tr>
+    {{range .Headers}}<tr><td>{{.Name}}</td><td>{{if .Required}}Yes{{else}}No{{end}}</td><td>{{.Description}}</td><td>{{.Example}}</td></tr>
+    {{end}}
+  </table>
+  {{end}}
+  {{if .Responses}}
+  <h4>Responses</h4>
+  <ul>
+    {{range .Responses}}<li><strong>{{.Status}}</strong>: {{.Description}}{{if .Example}}<pre><code>{{.Example}}</code></pre>{{end}}</li>
+    {{end}}
+  </ul>
+  {{end}}
+  {{if .RequestBody}}
+  <h4>Request Body</h4>
+  <pre><code>{{.RequestBody}}</code></pre>
+  {{end}}
+  {{if .Examples}}
+  <h4>Examples</h4>
+  {{range .Examples}}
+    {{if .Title}}<h5>{{.Title}}</h5>{{end}}
+    {{if .Description}}<p>{{.Description}}</p>{{end}}
+    {{if .Code}}<pre><code>{{.Code}}</code></pre>{{end}}
+    {{if .Output}}<pre><code>{{.Output}}</code></pre>{{end}}
+  {{end}}
+  {{end}}
+</div>
+{{end}}
+{{end}}
+</body>
+</html>
+`
+
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// generateHTMLDocs renders the documentation with the built-in template,
+// or with the html/template file at templatePath when one is given.
+func generateHTMLDocs(dockDocs *DockDocs, templatePath string) (string, error) {
+	tmpl, err := loadHTMLTemplate(templatePath)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, dockDocs); err != nil {
+		if templatePath != "" {
+			return "", fmt.Errorf("failed to render template %s (see the DockDocs and RequestDoc fields for available data): %w", templatePath, err)
+		}
+		return "", fmt.Errorf("failed to render HTML documentation: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+func loadHTMLTemplate(templatePath string) (*template.Template, error) {
+	if templatePath == "" {
+		tmpl, err := template.New("docs").Funcs(templateFuncs).Parse(htmlTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HTML template: %w", err)
+		}
+		return tmpl, nil
+	}
+
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", templatePath, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", templatePath, err)
+	}
+
+	return tmpl, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateHTMLDocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string // Custom template content, the built-in one when empty
+		want     []string
+		wantErr  string
+	}{
+		{
+			name: "built-in",
+			want: []string{"<title>shop API Documentation</title>", "Log in with a username and password", "&#34;token&#34;"},
+		},
+		{
+			name:     "custom",
+			template: `{{.Name}}:{{range .Requests}} {{.Name}}[{{join .Tags ","}}]{{end}}`,
+			want:     []string{"shop: login[auth,public] users[]"},
+		},
+		{
+			name:     "escapes values",
+			template: `{{(index .Requests 0).Parameters}}<p>{{.Description}}</p>`,
+			want:     []string{"<p>The shop API.</p>"},
+		},
+		{
+			name:     "invalid template",
+			template: `{{.Name`,
+			wantErr:  "invalid template",
+		},
+		{
+			name:     "unknown field",
+			template: `{{.Nope}}`,
+			wantErr:  "see the DockDocs and RequestDoc fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.template != "" {
+				path = filepath.Join(t.TempDir(), "docs.tmpl")
+				writeFile(t, path, tt.template)
+			}
+
+			got, err := generateHTMLDocs(sampleDocs(), path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadHTMLTemplateMissingFile(t *testing.T) {
+	if _, err := loadHTMLTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"rq/dock"
+	"rq/request/http"
+)
+
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportDate   string             `json:"__export_date"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+type insomniaResource struct {
+	ID          string            `json:"_id"`
+	Type        string            `json:"_type"`
+	ParentID    *string           `json:"parentId"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Method      string            `json:"method,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Headers     []insomniaHeader  `json:"headers,omitempty"`
+	Body        *insomniaBody     `json:"body,omitempty"`
+	Data        map[string]string `json:"data,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+}
+
+type insomniaHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type insomniaBody struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+var rqVariable = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+func generateInsomniaExport(ctx *dock.RqContext, dockDocs *DockDocs) (string, error) {
+	workspaceID := insomniaID("wrk", dockDocs.DockPath)
+
+	export := insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportDate:   dockDocs.GeneratedAt.UTC().Format(time.RFC3339),
+		ExportSource: "rq",
+		Resources: []insomniaResource{
+			{
+				ID:          workspaceID,
+				Type:        "workspace",
+				Name:        dockDocs.Name,
+				Description: dockDocs.Description,
+			},
+		},
+	}
+
+	rootConfig, err := exportableConfig(filepath.Join(ctx.Dock, ".env"))
+	if err != nil {
+		return "", fmt.Errorf("failed to load dock configuration: %w", err)
+	}
+
+	export.Resources = append(export.Resources, insomniaResource{
+		ID:       insomniaID("env", dockDocs.DockPath),
+		Type:     "environment",
+		ParentID: &workspaceID,
+		Name:     "Base Environment",
+		Data:     rootConfig,
+	})
+
+	for _, groupName := range sortedGroupNames(dockDocs) {
+		parentID := workspaceID
+
+		if groupName != "Root" {
+			groupID := insomniaID("fld", groupName)
+			group := insomniaResource{
+				ID:       groupID,
+				Type:     "request_group",
+				ParentID: &workspaceID,
+				Name:     groupName,
+			}
+
+			if config, err := exportableConfig(filepath.Join(ctx.Dock, groupName, ".env")); err == nil && len(config) > 0 {
+				group.Environment = configOverrides(rootConfig, config)
+			}
+
+			export.Resources = append(export.Resources, group)
+			parentID = groupID
+		}
+
+		for _, req := range dockDocs.Groups[groupName] {
+			if filepath.Ext(req.FilePath) != ".http" {
+				continue
+			}
+			resource, err := insomniaRequest(req, parentID)
+			if err != nil {
+				fmt.Printf("Warning: skipping %s: %v\n", req.RelativePath, err)
+				continue
+			}
+			export.Resources = append(export.Resources, resource)
+		}
+	}
+
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Insomnia export: %w", err)
+	}
+
+	return string(content), nil
+}
+
+func insomniaRequest(req RequestDoc, parentID string) (insomniaResource, error) {
+	resource := insomniaResource{
+		ID:          insomniaID("req", req.RelativePath),
+		Type:        "request",
+		ParentID:    &parentID,
+		Name:        req.Name,
+		Description: req.Description,
+		Method:      req.Method,
+		URL:         toInsomniaVariables(req.URL),
+		Headers:     []insomniaHeader{},
+	}
+
+	content, err := os.ReadFile(req.FilePath)
+	if err != nil {
+		return resource, err
+	}
+
+	parsed, err := http.Parse(string(content))
+	if err != nil {
+		return resource, err
+	}
+
+	resource.Method = parsed.Method
+	resource.URL = toInsomniaVariables(parsed.URL)
+
+	for key, value := range parsed.Headers {
+		resource.Headers = append(resource.Headers, insomniaHeader{
+			Name:  key,
+			Value: toInsomniaVariables(value),
+		})
+	}
+
+	if parsed.Body != "" {
+		mimeType := parsed.Headers["Content-Type"]
+		if mimeType == "" {
+			mimeType = "text/plain"
+		}
+		resource.Body = &insomniaBody{
+			MimeType: mimeType,
+			Text:     toInsomniaVariables(parsed.Body),
+		}
+	}
+
+	return resource, nil
+}
+
+func toInsomniaVariables(value string) string {
+	return rqVariable.ReplaceAllString(value, "{{ _.$1 }}")
+}
+
+// exportableConfig reads a committed .env file as written, leaving out
+// encrypted values so that exports never carry secrets, decrypted or not.
+// .env.local overrides are never read.
+func exportableConfig(path string) (map[string]string, error) {
+	raw, err := dock.LoadRawConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if !dock.IsEncrypted(value) {
+			config[key] = value
+		}
+	}
+	return config, nil
+}
+
+func configOverrides(base, config map[string]string) map[string]string {
+	res := make(map[string]string)
+	for key, value := range config {
+		if baseValue, ok := base[key]; !ok || baseValue != value {
+			res[key] = value
+		}
+	}
+	return res
+}
+
+func insomniaID(prefix, seed string) string {
+	return fmt.Sprintf("%s_%x", prefix, sha1.Sum([]byte(prefix+":"+seed)))[:len(prefix)+17]
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"rq/dock"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateInsomniaExport(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".env"), "BASE_URL=https://api.example.com\nAPI_KEY="+dock.EncryptedPrefix+"v2:abc\n")
+	writeFile(t, filepath.Join(dir, ".env.local"), "PASSWORD=hunter2\n")
+	writeFile(t, filepath.Join(dir, "users", ".env"), "BASE_URL=https://users.example.com\nAPI_KEY=plain\n")
+	writeFile(t, filepath.Join(dir, "users", "create.http"), "POST {{BASE_URL}}/users\nContent-Type: application/json\nAuthorization: Bearer {{ TOKEN }}\n\n{\"name\": \"{{name}}\"}\n")
+	writeFile(t, filepath.Join(dir, "ping.tcp"), "localhost:7\nhello\n")
+
+	dockDocs := &DockDocs{
+		Name:     "shop",
+		DockPath: dir,
+		Groups: map[string][]RequestDoc{
+			"users": {{Name: "create", FilePath: filepath.Join(dir, "users", "create.http"), RelativePath: "users/create.http"}},
+			"Root":  {{Name: "ping", FilePath: filepath.Join(dir, "ping.tcp"), RelativePath: "ping.tcp"}},
+		},
+	}
+
+	content, err := generateInsomniaExport(&dock.RqContext{Dock: dir, Path: dir}, dockDocs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var export insomniaExport
+	if err := json.Unmarshal([]byte(content), &export); err != nil {
+		t.Fatal(err)
+	}
+
+	byType := map[string][]insomniaResource{}
+	for _, resource := range export.Resources {
+		byType[resource.Type] = append(byType[resource.Type], resource)
+	}
+
+	env := byType["environment"]
+	if len(env) != 1 {
+		t.Fatalf("got %d environments", len(env))
+	}
+	if env[0].Data["BASE_URL"] != "https://api.example.com" {
+		t.Errorf("base environment = %v", env[0].Data)
+	}
+	for _, secret := range []string{"API_KEY", "PASSWORD"} {
+		if _, ok := env[0].Data[secret]; ok {
+			t.Errorf("base environment leaks %s", secret)
+		}
+	}
+
+	groups := byType["request_group"]
+	if len(groups) != 1 || groups[0].Name != "users" {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if groups[0].Environment["BASE_URL"] != "https://users.example.com" {
+		t.Errorf("group environment = %v", groups[0].Environment)
+	}
+
+	requests := byType["request"]
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want only the .http one", len(requests))
+	}
+	req := requests[0]
+	if req.Method != "POST" || req.URL != "{{ _.BASE_URL }}/users" || *req.ParentID != groups[0].ID {
+		t.Errorf("request = %s %s parent %s", req.Method, req.URL, *req.ParentID)
+	}
+	if req.Body == nil || req.Body.MimeType != "application/json" || req.Body.Text != `{"name": "{{ _.name }}"}` {
+		t.Errorf("body = %+v", req.Body)
+	}
+}
+
+func TestToInsomniaVariables(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"{{BASE_URL}}/users", "{{ _.BASE_URL }}/users"},
+		{"{{ token }}", "{{ _.token }}"},
+		{"{{uuid()}}", "{{uuid()}}"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := toInsomniaVariables(tt.value); got != tt.want {
+			t.Errorf("toInsomniaVariables(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestInsomniaIDIsStable(t *testing.T) {
+	a, b := insomniaID("req", "users/create.http"), insomniaID("req", "users/create.http")
+	if a != b || len(a) != len("req_")+16 {
+		t.Errorf("insomniaID = %q, %q", a, b)
+	}
+	if insomniaID("req", "other") == a {
+		t.Error("different seeds share an ID")
+	}
+}
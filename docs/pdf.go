@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+var ErrNoPDFRenderer = errors.New("no PDF renderer found: install Chrome/Chromium or wkhtmltopdf, or export to html and print it from a browser")
+
+// pdfRenderers are tried in order; each renders an HTML file into a PDF file.
+var pdfRenderers = []struct {
+	binary string
+	args   func(input, output string) []string
+}{
+	{"chromium", chromeArgs},
+	{"chromium-browser", chromeArgs},
+	{"google-chrome", chromeArgs},
+	{"google-chrome-stable", chromeArgs},
+	{"chrome", chromeArgs},
+	{"wkhtmltopdf", func(input, output string) []string {
+		return []string{"--quiet", "--print-media-type", input, output}
+	}},
+}
+
+func chromeArgs(input, output string) []string {
+	return []string{
+		"--headless",
+		"--disable-gpu",
+		"--no-pdf-header-footer",
+		"--print-to-pdf=" + output,
+		"file://" + input,
+	}
+}
+
+func generatePDFDocs(dockDocs *DockDocs, output, templatePath string) error {
+	if output == "" {
+		return errors.New("PDF export requires an output file")
+	}
+
+	content, err := generateHTMLDocs(dockDocs, templatePath)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "rq-docs-*.html")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary HTML file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary HTML file: %w", err)
+	}
+	tmp.Close()
+
+	input, err := filepath.Abs(tmp.Name())
+	if err != nil {
+		return err
+	}
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	for _, renderer := range pdfRenderers {
+		binary, err := exec.LookPath(renderer.binary)
+		if err != nil {
+			continue
+		}
+
+		cmd := exec.Command(binary, renderer.args(input, output)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed to render PDF: %w\n%s", renderer.binary, err, out)
+		}
+
+		if info, err := os.Stat(output); err != nil || info.Size() == 0 {
+			return fmt.Errorf("%s produced no PDF output", renderer.binary)
+		}
+
+		return nil
+	}
+
+	return ErrNoPDFRenderer
+}
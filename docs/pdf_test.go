@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePDFDocs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake renderers are shell scripts")
+	}
+
+	tests := []struct {
+		name     string
+		renderer string // Shell builtins installed as wkhtmltopdf, none when empty
+		wantErr  string
+	}{
+		{"no renderer", "", ErrNoPDFRenderer.Error()},
+		{"renders", `while read -r line; do case $line in *"shop API"*) echo "%PDF" > "$4";; esac; done < "$3"`, ""},
+		{"renderer fails", "echo broken >&2; exit 3", "wkhtmltopdf failed to render PDF"},
+		{"empty output", `for last; do :; done; : > "$last"`, "produced no PDF output"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := t.TempDir()
+			t.Setenv("PATH", bin)
+			if tt.renderer != "" {
+				script := "#!/bin/sh\n" + tt.renderer + "\n"
+				if err := os.WriteFile(filepath.Join(bin, "wkhtmltopdf"), []byte(script), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			output := filepath.Join(t.TempDir(), "docs.pdf")
+			err := generatePDFDocs(sampleDocs(), output, "")
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if content, _ := os.ReadFile(output); !strings.HasPrefix(string(content), "%PDF") {
+					t.Errorf("output = %q", content)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratePDFDocsNeedsOutput(t *testing.T) {
+	if err := generatePDFDocs(sampleDocs(), "", ""); err == nil || errors.Is(err, ErrNoPDFRenderer) {
+		t.Fatalf("got %v, want an error about the output file", err)
+	}
+}
+
+func TestDocsExportPDFCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake renderers are shell scripts")
+	}
+
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	// The fake renderer copies the HTML it is given, so the PDF shows which
+	// template was used.
+	script := "#!/bin/sh\n{ echo %PDF; while read -r line; do echo \"$line\"; done < \"$3\"; } > \"$4\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "wkhtmltopdf"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		file string
+		want string
+	}{
+		{name: "positionals", args: []string{"pdf", "api.pdf"}, file: "api.pdf", want: "shop API"},
+		{name: "options", args: []string{"-f", "pdf", "-o", "options.pdf"}, file: "options.pdf", want: "shop API"},
+		{name: "template", args: []string{"pdf", "custom.pdf", "--template", "brand.html"}, file: "custom.pdf", want: "Branded docs for shop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := docsDock(t)
+			writeFile(t, filepath.Join(dir, "brand.html"), "<html>Branded docs for {{.Name}}</html>\n")
+
+			if _, err := runDocs(t, append([]string{"docs", "export"}, tt.args...)...); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("PDF not written: %v", err)
+			}
+			if !strings.HasPrefix(string(content), "%PDF") || !strings.Contains(string(content), tt.want) {
+				t.Errorf("%s = %.300q, want a PDF of %q", tt.file, content, tt.want)
+			}
+		})
+	}
+}
+
+func TestChromeArgs(t *testing.T) {
+	args := chromeArgs("/tmp/in.html", "/tmp/out.pdf")
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"--headless", "--print-to-pdf=/tmp/out.pdf", "file:///tmp/in.html"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("chromeArgs missing %q: %v", want, args)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"fmt"
+	"regexp"
+	"rq/request/network"
+	"strings"
+)
+
+type searchMatch struct {
+	Request RequestDoc
+	Fields  []searchField
+}
+
+type searchField struct {
+	Name  string
+	Value string
+}
+
+func compileSearchQuery(query string, isRegex, caseSensitive bool) (*regexp.Regexp, error) {
+	if !isRegex {
+		query = regexp.QuoteMeta(query)
+	}
+	if !caseSensitive {
+		query = "(?i)" + query
+	}
+
+	re, err := regexp.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+	return re, nil
+}
+
+func searchRequests(dockDocs *DockDocs, re *regexp.Regexp) []searchMatch {
+	var matches []searchMatch
+
+	for _, req := range dockDocs.Requests {
+		var fields []searchField
+
+		check := func(name, value string) {
+			if value != "" && re.MatchString(value) {
+				fields = append(fields, searchField{Name: name, Value: value})
+			}
+		}
+
+		check("name", req.Name)
+		check("description", req.Description)
+		check("url", req.URL)
+		for _, param := range req.Parameters {
+			check("param "+param.Name, param.Name+" "+param.Description)
+		}
+		for _, tag := range req.Tags {
+			check("tag", tag)
+		}
+
+		if len(fields) > 0 {
+			matches = append(matches, searchMatch{Request: req, Fields: fields})
+		}
+	}
+
+	return matches
+}
+
+func printSearchResults(matches []searchMatch, re *regexp.Regexp) {
+	for _, match := range matches {
+		req := match.Request
+		fmt.Printf("%s (%s)\n", req.Name, req.RelativePath)
+		if req.Method != "" {
+			fmt.Printf("  %s %s\n", req.Method, req.URL)
+		}
+		for _, field := range match.Fields {
+			fmt.Printf("  %-12s %s\n", field.Name+":", highlight(field.Value, re))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d matching requests\n", len(matches))
+}
+
+func highlight(value string, re *regexp.Regexp) string {
+	value = strings.ReplaceAll(value, "\n", " ")
+	return re.ReplaceAllStringFunc(value, func(match string) string {
+		return network.Colorize(network.ColorBoldYellow, match)
+	})
+}
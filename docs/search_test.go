@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"rq/request/network"
+	"slices"
+	"testing"
+)
+
+func TestSearchRequests(t *testing.T) {
+	tests := []struct {
+		query         string
+		regex, sense  bool
+		wantRequests  []string
+		wantFirstHits []string
+	}{
+		{"login", false, false, []string{"login"}, []string{"name", "url"}},
+		{"LOGIN", false, false, []string{"login"}, []string{"name", "url"}},
+		{"LOGIN", false, true, nil, nil},
+		{"auth", false, false, []string{"login"}, []string{"url", "tag"}},
+		{"account name", false, false, []string{"login"}, []string{"param username"}},
+		{"^user", true, false, []string{"login", "users"}, []string{"param username"}},
+		{"a.b", false, false, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			re, err := compileSearchQuery(tt.query, tt.regex, tt.sense)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			matches := searchRequests(sampleDocs(), re)
+
+			var names []string
+			for _, match := range matches {
+				names = append(names, match.Request.Name)
+			}
+			if !slices.Equal(names, tt.wantRequests) {
+				t.Fatalf("matched %v, want %v", names, tt.wantRequests)
+			}
+			if len(matches) == 0 {
+				return
+			}
+
+			var fields []string
+			for _, field := range matches[0].Fields {
+				fields = append(fields, field.Name)
+			}
+			if !slices.Equal(fields, tt.wantFirstHits) {
+				t.Errorf("fields %v, want %v", fields, tt.wantFirstHits)
+			}
+		})
+	}
+}
+
+func TestCompileSearchQueryRejectsInvalidRegex(t *testing.T) {
+	if _, err := compileSearchQuery("(", true, false); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := compileSearchQuery("(", false, false); err != nil {
+		t.Fatalf("plain queries are quoted: %v", err)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	network.NoColor = true
+	defer func() { network.NoColor = false }()
+
+	re, _ := compileSearchQuery("user", false, false)
+	if got := highlight("User\nlist", re); got != "User list" {
+		t.Errorf("highlight = %q", got)
+	}
+}
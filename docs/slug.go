@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// slugger generates heading anchors the same way GitHub does, so every
+// heading must be passed through it in document order for duplicates to
+// receive the right -1, -2 suffixes.
+type slugger struct {
+	seen map[string]int
+}
+
+func newSlugger() *slugger {
+	return &slugger{seen: make(map[string]int)}
+}
+
+func (s *slugger) slug(heading string) string {
+	base := githubSlug(heading)
+
+	count, ok := s.seen[base]
+	s.seen[base] = count + 1
+	if !ok {
+		return base
+	}
+
+	for {
+		candidate := fmt.Sprintf("%s-%d", base, count)
+		if _, taken := s.seen[candidate]; !taken {
+			s.seen[candidate] = 1
+			return candidate
+		}
+		count++
+		s.seen[base] = count + 1
+	}
+}
+
+func githubSlug(heading string) string {
+	var sb strings.Builder
+
+	for _, char := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(char) || unicode.IsNumber(char) || char == '_' || char == '-':
+			sb.WriteRune(char)
+		case char == ' ':
+			sb.WriteRune('-')
+		}
+	}
+
+	return sb.String()
+}
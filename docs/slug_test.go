@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGithubSlug(t *testing.T) {
+	tests := []struct {
+		heading, want string
+	}{
+		{"Users", "users"},
+		{"Get User", "get-user"},
+		{"  Trimmed  ", "trimmed"},
+		{"auth/login (v2)!", "authlogin-v2"},
+		{"snake_case-and-dash", "snake_case-and-dash"},
+		{"Café Über", "café-über"},
+		{"a  b", "a--b"},
+	}
+	for _, tt := range tests {
+		if got := githubSlug(tt.heading); got != tt.want {
+			t.Errorf("githubSlug(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}
+
+func TestSluggerDuplicates(t *testing.T) {
+	s := newSlugger()
+	headings := []string{"Users", "users", "Users-1", "Users", "Other"}
+	want := []string{"users", "users-1", "users-1-1", "users-2", "other"}
+
+	for i, heading := range headings {
+		if got := s.slug(heading); got != want[i] {
+			t.Errorf("slug #%d (%q) = %q, want %q", i, heading, got, want[i])
+		}
+	}
+}
+
+func TestMarkdownAnchorsMatchHeadings(t *testing.T) {
+	dockDocs := sampleDocs()
+	dockDocs.Groups["users"] = []RequestDoc{{Name: "users"}, {Name: "Log in"}}
+	dockDocs.GroupOrder = append(dockDocs.GroupOrder, "users")
+
+	md := generateMarkdownDocs(dockDocs)
+
+	s := newSlugger()
+	headings := map[string]bool{}
+	for _, line := range strings.Split(md, "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimLeft(line, "#"), " "); ok && strings.HasPrefix(line, "#") {
+			headings[s.slug(title)] = true
+		}
+	}
+
+	links := regexp.MustCompile(`\]\(#([^)]+)\)`).FindAllStringSubmatch(md, -1)
+	if len(links) == 0 {
+		t.Fatal("no table of contents links")
+	}
+	for _, link := range links {
+		if !headings[link[1]] {
+			t.Errorf("anchor #%s matches no heading", link[1])
+		}
+	}
+}
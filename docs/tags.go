@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+var tagLinePattern = regexp.MustCompile(`^(?:///|##)\s*@tags?\b(.*)$`)
+
+func splitTags(content string) []string {
+	return strings.FieldsFunc(content, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// ReadTags returns the @tag values of a request file without running the
+// full documentation extraction, so commands like `rq run --tag` stay cheap.
+func ReadTags(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, line := range strings.Split(string(content), "\n") {
+		matches := tagLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) > 1 {
+			tags = append(tags, splitTags(matches[1])...)
+		}
+	}
+	return tags, nil
+}
+
+func HasTag(path, tag string) bool {
+	tags, err := ReadTags(path)
+	if err != nil {
+		return false
+	}
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
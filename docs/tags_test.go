@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package docs
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "doc comment", content: "/// @tag smoke\nGET /health\n", want: []string{"smoke"}},
+		{name: "hash doc comment", content: "## @tags smoke, auth\nGET /login\n", want: []string{"smoke", "auth"}},
+		{name: "several lines", content: "/// Login\n/// @tag auth\n///   @tag  smoke slow\nPOST /login\n", want: []string{"auth", "smoke", "slow"}},
+		{name: "plain comment is not a tag", content: "# @tag smoke\nGET /health\n"},
+		{name: "directive is not a tag", content: "# @assert status == 200\nGET /health\n"},
+		{name: "other attribute", content: "/// @tagline hello\nGET /\n"},
+		{name: "no tags", content: "GET /\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "req.http")
+			writeFile(t, path, tt.content)
+
+			got, err := ReadTags(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "req.http")
+	writeFile(t, path, "/// @tag Smoke, auth\nGET /\n")
+
+	for tag, want := range map[string]bool{"smoke": true, "SMOKE": true, "auth": true, "slow": false, "": false} {
+		if got := HasTag(path, tag); got != want {
+			t.Errorf("HasTag(%q) = %v, want %v", tag, got, want)
+		}
+	}
+	if HasTag(filepath.Join(t.TempDir(), "missing.http"), "smoke") {
+		t.Error("HasTag() of a missing file = true")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"rq/dock"
+	"slices"
 	"strings"
 
 	"github.com/marcomit/args"
@@ -30,7 +31,10 @@ func findEnvFiles(root string) []string {
 }
 
 func List() error {
-	ctx := dock.GetContext()
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("Environment files in dock: %s\n", ctx.Dock)
 	fmt.Println()
@@ -49,12 +53,14 @@ func List() error {
 }
 
 func Show(path string) error {
-	ctx := dock.GetContext()
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
 
 	config, err := ctx.GetConfig(path)
 	if err != nil {
-		fmt.Printf("Error loading configuration: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("Error loading configuration: %w", err)
 	}
 
 	if path == "" {
@@ -74,6 +80,286 @@ func Show(path string) error {
 	return nil
 }
 
+func Import(source, envName string, overwrite bool) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+
+	values, err := dock.LoadRawConfigFile(source)
+	if err != nil {
+		return fmt.Errorf("Error loading %s: %w", source, err)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("No variables found in %s", source)
+	}
+
+	target := envFilePath(ctx, envName)
+
+	existing, err := dock.LoadOwnConfigFile(target)
+	if err != nil {
+		return fmt.Errorf("Error loading %s: %w", target, err)
+	}
+
+	updates := make(map[string]string)
+	skipped := 0
+	for key, value := range values {
+		if current, ok := existing[key]; ok {
+			if current == value {
+				continue
+			}
+			if !overwrite {
+				skipped++
+				continue
+			}
+		}
+		updates[key] = value
+	}
+
+	if err := dock.WriteConfigValues(target, updates); err != nil {
+		return err
+	}
+
+	relPath, _ := filepath.Rel(ctx.Dock, target)
+	fmt.Printf("Imported %d variables into %s\n", len(updates), relPath)
+	if skipped > 0 {
+		fmt.Printf("Skipped %d existing variables (use --overwrite to replace them)\n", skipped)
+	}
+	return nil
+}
+
+func envFilePath(ctx *dock.RqContext, envName string) string {
+	target := filepath.Join(ctx.Dock, ".env")
+	if envName != "" {
+		target += "." + envName
+	}
+	return target
+}
+
+// Scaffold creates an empty .env.<env> at the dock root for every
+// environment that does not have one yet, and returns the files it created.
+func Scaffold(ctx *dock.RqContext, envs []string) ([]string, error) {
+	var created []string
+	for _, env := range envs {
+		if !validEnvName(env) {
+			return created, fmt.Errorf("invalid environment name: %q", env)
+		}
+
+		target := envFilePath(ctx, env)
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+
+		content := fmt.Sprintf("# Variables for the %s environment, they override .env\n", env)
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return created, fmt.Errorf("failed to create %s: %w", filepath.Base(target), err)
+		}
+		created = append(created, target)
+	}
+	return created, nil
+}
+
+// validEnvName reports whether name can follow .env. in a file name without
+// clashing with .env.local or leaving the directory.
+func validEnvName(name string) bool {
+	return name != "" && name != "local" && !strings.ContainsAny(name, `/\`) && !strings.HasPrefix(name, ".")
+}
+
+// renameFile moves env files, replaceable by tests.
+var renameFile = os.Rename
+
+// Rename renames the environment, moving every .env.<old> and
+// .env.<old>.local file in the dock and repointing DEFAULT_ENV where it
+// names it.
+func Rename(oldName, newName string) error {
+	if oldName == "" || newName == "" {
+		return errors.New("Environment names cannot be empty")
+	}
+	if !validEnvName(newName) {
+		return fmt.Errorf("Invalid environment name: %s", newName)
+	}
+
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+
+	// Every directory of the dock can have its own .env.<name> and
+	// .env.<name>.local override, and they all move together.
+	renamed := map[string]string{
+		".env." + oldName:            ".env." + newName,
+		".env." + oldName + ".local": ".env." + newName + ".local",
+	}
+
+	var moves [][2]string
+	for _, envFile := range findEnvFiles(ctx.Dock) {
+		name, ok := renamed[filepath.Base(envFile)]
+		if !ok {
+			continue
+		}
+		target := filepath.Join(filepath.Dir(envFile), name)
+		if _, err := os.Stat(target); err == nil {
+			relPath, _ := filepath.Rel(ctx.Dock, target)
+			return fmt.Errorf("%s already exists", relPath)
+		}
+		moves = append(moves, [2]string{envFile, target})
+	}
+
+	if len(moves) == 0 {
+		return fmt.Errorf("Environment %s does not exist", oldName)
+	}
+
+	for i, move := range moves {
+		if err := renameFile(move[0], move[1]); err != nil {
+			// Put back the files already moved, so the dock is not left
+			// with the environment split across two names.
+			for _, done := range slices.Backward(moves[:i]) {
+				renameFile(done[1], done[0])
+			}
+			return fmt.Errorf("Failed to rename %s: %w", move[0], err)
+		}
+	}
+	for _, move := range moves {
+		relPath, _ := filepath.Rel(ctx.Dock, move[1])
+		fmt.Printf("Renamed to %s\n", relPath)
+	}
+
+	for _, envFile := range findEnvFiles(ctx.Dock) {
+		values, err := dock.LoadRawConfigFile(envFile)
+		if err != nil {
+			return fmt.Errorf("Error loading %s: %w", envFile, err)
+		}
+		if values[dock.DefaultEnvKey] != oldName {
+			continue
+		}
+		if err := dock.WriteConfigValues(envFile, map[string]string{dock.DefaultEnvKey: newName}); err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(ctx.Dock, envFile)
+		fmt.Printf("Updated %s in %s\n", dock.DefaultEnvKey, relPath)
+	}
+
+	return nil
+}
+
+func Encrypt(key, envName string) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+	target := envFilePath(ctx, envName)
+
+	values, err := dock.LoadOwnConfigFile(target)
+	if err != nil {
+		return fmt.Errorf("Error loading %s: %w", target, err)
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return fmt.Errorf("Key %s not found in %s", key, filepath.Base(target))
+	}
+	if dock.IsEncrypted(value) {
+		return fmt.Errorf("Key %s is already encrypted", key)
+	}
+
+	encrypted, err := dock.EncryptValue(value)
+	if err != nil {
+		return err
+	}
+
+	if err := dock.WriteConfigValues(target, map[string]string{key: encrypted}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Encrypted %s in %s\n", key, filepath.Base(target))
+	return nil
+}
+
+func Decrypt(key, envName string) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+	target := envFilePath(ctx, envName)
+
+	values, err := dock.LoadOwnConfigFile(target)
+	if err != nil {
+		return fmt.Errorf("Error loading %s: %w", target, err)
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return fmt.Errorf("Key %s not found in %s", key, filepath.Base(target))
+	}
+	if !dock.IsEncrypted(value) {
+		return fmt.Errorf("Key %s is not encrypted", key)
+	}
+
+	decrypted, err := dock.DecryptValue(value)
+	if err != nil {
+		return fmt.Errorf("Failed to decrypt %s: %w", key, err)
+	}
+
+	if err := dock.WriteConfigValues(target, map[string]string{key: decrypted}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Decrypted %s in %s\n", key, filepath.Base(target))
+	return nil
+}
+
+func Export(path, envName, format string) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+
+	config, err := ctx.GetConfigForEnv(path, envName)
+	if err != nil {
+		return fmt.Errorf("Error loading configuration: %w", err)
+	}
+
+	content, err := formatExport(config, format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(content)
+	return nil
+}
+
+func Validate(path, envName string) error {
+	ctx, err := dock.GetContext()
+	if err != nil {
+		return err
+	}
+
+	schema, err := dock.LoadSchema(ctx.Dock)
+	if err != nil {
+		return err
+	}
+
+	if len(schema) == 0 {
+		return fmt.Errorf("No schema found, declare the expected keys in %s", dock.SchemaPath(ctx.Dock))
+	}
+
+	config, err := ctx.GetConfigForEnv(path, envName)
+	if err != nil {
+		return fmt.Errorf("Error loading configuration: %w", err)
+	}
+
+	problems := dock.ValidateConfig(schema, config)
+	if len(problems) == 0 {
+		fmt.Printf("Configuration is valid (%d keys checked)\n", len(schema))
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("  %s\n", problem)
+	}
+	return fmt.Errorf("Configuration has %d problems", len(problems))
+}
+
 func Setup(app *args.Parser) {
 	env := app.Command("env", "Environment manager")
 
@@ -82,6 +368,70 @@ func Setup(app *args.Parser) {
 			return List()
 		})
 
+	env.Command("import", "Merges the keys of another env file into the dock").
+		Positional("file").
+		Option("env", "e", "Environment to import into").
+		Flag("overwrite", "w", "Overwrite keys that already exist").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Missing file argument")
+			}
+			return Import(r.Positionals[0], r.Options["env"], r.Flag("overwrite"))
+		})
+
+	env.Command("rename", "Renames an environment and updates DEFAULT_ENV").
+		Positional("old").
+		Positional("new").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) < 2 {
+				return errors.New("Expected the current and the new environment name")
+			}
+			return Rename(r.Positionals[0], r.Positionals[1])
+		})
+
+	env.Command("encrypt", "Encrypts the value of a key with the master key").
+		Positional("key").
+		Option("env", "e", "Environment file to update").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Missing key argument")
+			}
+			return Encrypt(r.Positionals[0], r.Options["env"])
+		})
+
+	env.Command("decrypt", "Decrypts the value of a key back to plain text").
+		Positional("key").
+		Option("env", "e", "Environment file to update").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Missing key argument")
+			}
+			return Decrypt(r.Positionals[0], r.Options["env"])
+		})
+
+	env.Command("export", "Prints the configuration as shell export statements").
+		Positional("path").
+		Option("env", "e", "Environment").
+		Option("format", "f", "Shell syntax", "sh", "bash", "zsh", "fish", "powershell", "pwsh").
+		Action(func(r *args.Result) error {
+			path := ""
+			if len(r.Positionals) > 0 {
+				path = r.Positionals[0]
+			}
+			return Export(path, r.Options["env"], r.Options["format"])
+		})
+
+	env.Command("validate", "Validates the configuration against the dock schema").
+		Positional("path").
+		Option("env", "e", "Environment").
+		Action(func(r *args.Result) error {
+			path := ""
+			if len(r.Positionals) > 0 {
+				path = r.Positionals[0]
+			}
+			return Validate(path, r.Options["env"])
+		})
+
 	env.Command("show", "Shows the current configuration").
 		Positional("path").
 		Action(func(r *args.Result) error {
@@ -0,0 +1,355 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package environment
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rq/dock"
+)
+
+// chdirDock creates a dock with the given files and makes it the working directory.
+func chdirDock(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	files[".dock"] = "shop"
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(root)
+	return root
+}
+
+func TestImport(t *testing.T) {
+	const source = "# exported from the old tool\nBASE_URL=https://api.example.com\nTOKEN=new\nSECRET=enc:v2:abc\n"
+
+	tests := []struct {
+		name      string
+		envName   string
+		existing  string
+		overwrite bool
+		target    string
+		want      map[string]string
+	}{
+		{
+			name:   "into empty default env",
+			target: ".env",
+			want:   map[string]string{"BASE_URL": "https://api.example.com", "TOKEN": "new", "SECRET": "enc:v2:abc"},
+		},
+		{
+			name:     "keeps existing values",
+			envName:  "staging",
+			existing: "TOKEN=old\nEXTRA=1\n",
+			target:   ".env.staging",
+			want:     map[string]string{"BASE_URL": "https://api.example.com", "TOKEN": "old", "SECRET": "enc:v2:abc", "EXTRA": "1"},
+		},
+		{
+			name:      "overwrite replaces existing values",
+			envName:   "staging",
+			existing:  "TOKEN=old\n",
+			overwrite: true,
+			target:    ".env.staging",
+			want:      map[string]string{"BASE_URL": "https://api.example.com", "TOKEN": "new", "SECRET": "enc:v2:abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"legacy.env": source}
+			if tt.existing != "" {
+				files[tt.target] = tt.existing
+			}
+			root := chdirDock(t, files)
+
+			if err := Import("legacy.env", tt.envName, tt.overwrite); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := dock.LoadRawConfigFile(filepath.Join(root, tt.target))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportIgnoresIncludedKeys(t *testing.T) {
+	root := chdirDock(t, map[string]string{
+		"shared.env": "TOKEN=shared\nREGION=eu\n",
+		".env":       "@env-include shared.env\nREGION=us\n",
+		"legacy.env": "TOKEN=new\nREGION=ap\n",
+	})
+
+	if err := Import("legacy.env", "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// TOKEN only comes from the include, so it is new to .env, while REGION
+	// is set by .env itself and kept.
+	want := "@env-include shared.env\nREGION=us\nTOKEN=new\n"
+	if got, _ := os.ReadFile(filepath.Join(root, ".env")); string(got) != want {
+		t.Errorf(".env = %q, want %q", got, want)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "shared.env")); string(got) != "TOKEN=shared\nREGION=eu\n" {
+		t.Errorf("shared.env was changed: %q", got)
+	}
+}
+
+func TestEncryptIgnoresIncludedKeys(t *testing.T) {
+	t.Setenv("RQ_MASTER_KEY", "correct horse")
+	const content = "@env-include shared.env\nUSER=admin\n"
+	root := chdirDock(t, map[string]string{
+		"shared.env": "TOKEN=s3cret\n",
+		".env":       content,
+	})
+
+	for name, run := range map[string]func(string, string) error{"Encrypt": Encrypt, "Decrypt": Decrypt} {
+		err := run("TOKEN", "")
+		if err == nil || err.Error() != "Key TOKEN not found in .env" {
+			t.Errorf("%s() of an included key error = %v", name, err)
+		}
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, ".env")); string(got) != content {
+		t.Errorf(".env = %q, want it unchanged", got)
+	}
+}
+
+func TestImportEmptySource(t *testing.T) {
+	chdirDock(t, map[string]string{"empty.env": "# nothing here\n"})
+
+	if err := Import("empty.env", "", false); err == nil {
+		t.Fatal("Import() of an empty file succeeded, want error")
+	}
+}
+
+func TestRename(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		old, new  string
+		wantFiles map[string]string
+		wantGone  []string
+		wantErr   string
+	}{
+		{
+			name: "moves the env and repoints DEFAULT_ENV",
+			files: map[string]string{
+				".env":         "BASE_URL=http://root\nDEFAULT_ENV=stage\n",
+				".env.stage":   "BASE_URL=http://stage\n",
+				".env.staging": "",
+			},
+			old: "stage", new: "preprod",
+			wantFiles: map[string]string{
+				".env":         "BASE_URL=http://root\nDEFAULT_ENV=preprod\n",
+				".env.preprod": "BASE_URL=http://stage\n",
+				".env.staging": "",
+			},
+			wantGone: []string{".env.stage"},
+		},
+		{
+			name: "other DEFAULT_ENV values are kept",
+			files: map[string]string{
+				".env":       "DEFAULT_ENV=dev\n",
+				".env.stage": "A=1\n",
+			},
+			old: "stage", new: "preprod",
+			wantFiles: map[string]string{".env": "DEFAULT_ENV=dev\n", ".env.preprod": "A=1\n"},
+		},
+		{
+			name:  "target exists",
+			files: map[string]string{".env.stage": "A=1\n", ".env.preprod": "B=2\n"},
+			old:   "stage", new: "preprod",
+			wantErr:   ".env.preprod already exists",
+			wantFiles: map[string]string{".env.stage": "A=1\n", ".env.preprod": "B=2\n"},
+		},
+		{
+			name:  "missing environment",
+			files: map[string]string{".env": "A=1\n"},
+			old:   "stage", new: "preprod",
+			wantErr: "Environment stage does not exist",
+		},
+		{
+			name:  "invalid name",
+			files: map[string]string{".env.stage": "A=1\n"},
+			old:   "stage", new: "../prod",
+			wantErr: "Invalid environment name: ../prod",
+		},
+		{
+			name:  "local",
+			files: map[string]string{".env.stage": "A=1\n"},
+			old:   "stage", new: "local",
+			wantErr:   "Invalid environment name: local",
+			wantFiles: map[string]string{".env.stage": "A=1\n"},
+		},
+		{
+			name:  "leading dot",
+			files: map[string]string{".env.stage": "A=1\n"},
+			old:   "stage", new: ".prod",
+			wantErr:   "Invalid environment name: .prod",
+			wantFiles: map[string]string{".env.stage": "A=1\n"},
+		},
+		{
+			name:  "empty name",
+			files: map[string]string{".env.stage": "A=1\n"},
+			old:   "stage", new: "",
+			wantErr: "Environment names cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := chdirDock(t, tt.files)
+
+			err := Rename(tt.old, tt.new)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Rename() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, want := range tt.wantFiles {
+				got, err := os.ReadFile(filepath.Join(root, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			for _, name := range tt.wantGone {
+				if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+					t.Errorf("%s still exists", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRenameMovesLocalOverrides(t *testing.T) {
+	root := chdirDock(t, map[string]string{
+		".env.stage":             "A=1\n",
+		".env.stage.local":       "A=2\n",
+		"users/.env.stage.local": "B=3\n",
+	})
+
+	if err := Rename("stage", "preprod"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{".env.preprod", ".env.preprod.local", "users/.env.preprod.local"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			t.Errorf("%s is missing: %v", name, err)
+		}
+	}
+	for _, name := range []string{".env.stage", ".env.stage.local", "users/.env.stage.local"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			t.Errorf("%s still exists", name)
+		}
+	}
+}
+
+func TestRenameUndoesMovesOnFailure(t *testing.T) {
+	root := chdirDock(t, map[string]string{
+		".env.stage":             "A=1\n",
+		".env.stage.local":       "A=2\n",
+		"users/.env.stage.local": "B=3\n",
+	})
+
+	moved := 0
+	renameFile = func(from, to string) error {
+		if strings.HasPrefix(filepath.Base(to), ".env.preprod") {
+			if moved == 2 {
+				return errors.New("disk full")
+			}
+			moved++
+		}
+		return os.Rename(from, to)
+	}
+	t.Cleanup(func() { renameFile = os.Rename })
+
+	err := Rename("stage", "preprod")
+	if err == nil || !strings.HasSuffix(err.Error(), ": disk full") {
+		t.Fatalf("Rename() error = %v, want the failed move", err)
+	}
+
+	for _, name := range []string{".env.stage", ".env.stage.local", "users/.env.stage.local"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			t.Errorf("%s was not put back: %v", name, err)
+		}
+	}
+	for _, name := range []string{".env.preprod", ".env.preprod.local", "users/.env.preprod.local"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			t.Errorf("%s was left behind", name)
+		}
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	tests := []struct {
+		name        string
+		envs        []string
+		wantCreated []string
+		wantErr     string
+	}{
+		{name: "new environments", envs: []string{"dev", "prod"}, wantCreated: []string{".env.dev", ".env.prod"}},
+		{name: "existing file is kept", envs: []string{"staging", "dev"}, wantCreated: []string{".env.dev"}},
+		{name: "local is not an environment", envs: []string{"dev", "local"}, wantCreated: []string{".env.dev"}, wantErr: `invalid environment name: "local"`},
+		{name: "path", envs: []string{"../prod"}, wantErr: `invalid environment name: "../prod"`},
+		{name: "hidden", envs: []string{".prod"}, wantErr: `invalid environment name: ".prod"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := chdirDock(t, map[string]string{".env.staging": "BASE_URL=https://staging.test\n"})
+			ctx, err := dock.GetContext()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			created, err := Scaffold(ctx, tt.envs)
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Scaffold() error = %v, want %q", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, path := range created {
+				rel, _ := filepath.Rel(root, path)
+				got = append(got, rel)
+			}
+			if !reflect.DeepEqual(got, tt.wantCreated) {
+				t.Errorf("Scaffold() created %q, want %q", got, tt.wantCreated)
+			}
+			for _, name := range tt.wantCreated {
+				content, err := os.ReadFile(filepath.Join(root, name))
+				if err != nil || len(content) == 0 || content[0] != '#' {
+					t.Errorf("%s = %q, %v, want a commented stub", name, content, err)
+				}
+			}
+
+			staging, _ := os.ReadFile(filepath.Join(root, ".env.staging"))
+			if string(staging) != "BASE_URL=https://staging.test\n" {
+				t.Errorf(".env.staging was overwritten: %q", staging)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package environment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var exportFormats = map[string]func(key, value string) string{
+	"sh": func(key, value string) string {
+		return fmt.Sprintf("export %s=%s", key, quotePosix(value))
+	},
+	"fish": func(key, value string) string {
+		return fmt.Sprintf("set -gx %s %s", key, quoteFish(value))
+	},
+	"powershell": func(key, value string) string {
+		return fmt.Sprintf("$env:%s = %s", key, quotePowershell(value))
+	},
+}
+
+func formatExport(config map[string]string, format string) (string, error) {
+	switch format {
+	case "", "bash", "zsh":
+		format = "sh"
+	case "pwsh":
+		format = "powershell"
+	}
+
+	line, ok := exportFormats[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported export format: %s (supported: sh, bash, zsh, fish, powershell, pwsh)", format)
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(line(key, config[key]))
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+func quotePosix(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func quoteFish(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+func quotePowershell(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
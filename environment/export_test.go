@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package environment
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestFormatExport(t *testing.T) {
+	config := map[string]string{
+		"TOKEN":    `it's a \secret`,
+		"BASE_URL": "https://api.example.com",
+	}
+
+	posix := "export BASE_URL='https://api.example.com'\nexport TOKEN='it'\\''s a \\secret'\n"
+	powershell := "$env:BASE_URL = 'https://api.example.com'\n$env:TOKEN = 'it''s a \\secret'\n"
+
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{format: "", want: posix},
+		{format: "sh", want: posix},
+		{format: "bash", want: posix},
+		{format: "zsh", want: posix},
+		{format: "fish", want: "set -gx BASE_URL 'https://api.example.com'\nset -gx TOKEN 'it\\'s a \\\\secret'\n"},
+		{format: "powershell", want: powershell},
+		{format: "pwsh", want: powershell},
+		{format: "cmd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := formatExport(config, tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("formatExport(%q) succeeded, want error", tt.format)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("formatExport(%q) =\n%s\nwant\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatExportEvalsInSh(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	values := []string{"plain", "it's", `back\slash`, "$HOME and `cmd`", "two\nlines", ""}
+	for _, value := range values {
+		script, err := formatExport(map[string]string{"VALUE": value}, "sh")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := exec.Command(sh, "-c", script+`printf '%s' "$VALUE"`).Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", value, err)
+		}
+		if string(out) != value {
+			t.Errorf("sh evaluated %q as %q", value, out)
+		}
+	}
+}
@@ -4,4 +4,9 @@ go 1.24.6
 
 require github.com/google/uuid v1.6.0
 
-require github.com/marcomit/args v1.0.2
+require (
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/marcomit/args v1.0.2
+	golang.org/x/crypto v0.47.0
+	golang.org/x/sys v0.40.0
+)
@@ -6,26 +6,44 @@ package main
 import (
 	"fmt"
 	"os"
+	"rq/cli"
 	"rq/dock"
 	"rq/docs"
 	"rq/environment"
+	"rq/mock"
+	"rq/repl"
 	"rq/request"
+	"rq/version"
 
 	"github.com/marcomit/args"
 )
 
 func main() {
-	rq := args.New("rq").Action(func(r *args.Result) error {
-		fmt.Println("Welcome to RQ!")
-		return nil
-	})
+	rq := args.New("rq").
+		Flag("version", "v", "Prints the rq version").
+		Flag("no-color", "", "Disable colored output (also honors NO_COLOR)").
+		Option("dock", "", "Use the dock at this path, or registered under this name, instead of the current one").
+		Action(func(r *args.Result) error {
+			if r.Flag("version") {
+				version.Print()
+				return nil
+			}
+			if len(r.Positionals) > 0 {
+				return fmt.Errorf("%w: %s", cli.ErrUnknownCommand, r.Positionals[0])
+			}
+			fmt.Println("Welcome to RQ!")
+			return nil
+		})
 
 	dock.Setup(rq)
 	request.Setup(rq)
 	environment.Setup(rq)
 	docs.Setup(rq)
+	mock.Setup(rq)
+	repl.Setup(rq)
+	version.Setup(rq)
 
-	err := rq.Run(os.Args[1:])
+	err := cli.Run(rq, os.Args[1:])
 
 	if err != nil {
 		fmt.Println(err)
@@ -34,4 +52,8 @@ func main() {
 	if len(os.Args) == 1 {
 		rq.Usage()
 	}
+
+	if err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"rq/dock"
+	"rq/snapshot"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcomit/args"
+)
+
+type Server struct {
+	Dock      string
+	Target    string
+	snapshots map[string]*snapshot.Snapshot
+	mu        sync.RWMutex
+	client    *http.Client
+}
+
+func NewServer(dockPath, target string) (*Server, error) {
+	snapshots, err := snapshot.Load(dockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if target != "" {
+		parsed, err := url.Parse(target)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid passthrough target: %s", target)
+		}
+	}
+
+	return &Server{
+		Dock:      dockPath,
+		Target:    strings.TrimSuffix(target, "/"),
+		snapshots: snapshots,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := snapshot.Key(r.Method, r.URL.Path)
+
+	s.mu.RLock()
+	snap, ok := s.snapshots[key]
+	s.mu.RUnlock()
+
+	if !ok && s.Target != "" {
+		recorded, err := s.record(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("rq mock: passthrough failed: %v", err), http.StatusBadGateway)
+			fmt.Printf("%s -> passthrough error: %v\n", key, err)
+			return
+		}
+		snap = recorded
+		fmt.Printf("%s -> %d (recorded)\n", key, snap.StatusCode)
+	} else if !ok {
+		http.Error(w, fmt.Sprintf("rq mock: no recorded response for %s", key), http.StatusNotFound)
+		fmt.Printf("%s -> not found\n", key)
+		return
+	} else {
+		fmt.Printf("%s -> %d (replayed)\n", key, snap.StatusCode)
+	}
+
+	replay(w, snap)
+}
+
+func (s *Server) record(r *http.Request) (*snapshot.Snapshot, error) {
+	target := s.Target + r.URL.RequestURI()
+
+	req, err := http.NewRequest(r.Method, target, r.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// The escaped path keeps distinct paths such as /a_b and /a/b apart.
+	name := "mock/" + strings.ToLower(r.Method) + "_" + url.QueryEscape(r.URL.Path)
+	snap := snapshot.New(name, r.Method, target, resp.StatusCode, resp.Status, resp.Header, body)
+
+	if err := snapshot.Save(s.Dock, snap); err != nil {
+		return nil, fmt.Errorf("failed to record response: %w", err)
+	}
+
+	s.mu.Lock()
+	s.snapshots[snap.Key()] = snap
+	s.mu.Unlock()
+
+	return snap, nil
+}
+
+func replay(w http.ResponseWriter, snap *snapshot.Snapshot) {
+	for key, values := range snap.Headers {
+		if strings.EqualFold(key, "Content-Length") || strings.EqualFold(key, "Transfer-Encoding") {
+			continue
+		}
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	status := snap.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(snap.Body)
+}
+
+func Setup(app *args.Parser) {
+	app.Command("mock", "Serve recorded responses as a local mock server").
+		Option("port", "p", "Server port").
+		Option("host", "ho", "Interface to listen on, like 0.0.0.0 for every interface (default: 127.0.0.1)").
+		Option("target", "t", "Upstream base URL used to record missing responses").
+		Flag("passthrough", "pt", "Record live responses from the target the first time and replay afterward").
+		Action(func(r *args.Result) error {
+			host, ok := r.Options["host"]
+			if !ok {
+				host = "127.0.0.1"
+			}
+			port, ok := r.Options["port"]
+			if !ok {
+				port = "8080"
+			}
+
+			target := r.Options["target"]
+			if r.Flag("passthrough") && target == "" {
+				return errors.New("Passthrough mode requires a --target")
+			}
+			if !r.Flag("passthrough") {
+				target = ""
+			}
+
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			server, err := NewServer(ctx.Dock, target)
+			if err != nil {
+				return err
+			}
+
+			address := net.JoinHostPort(host, port)
+			fmt.Printf("Serving %d recorded responses on http://%s\n", len(server.snapshots), address)
+			if target != "" {
+				fmt.Printf("Passthrough to %s\n", target)
+			}
+
+			return http.ListenAndServe(address, server)
+		})
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package mock
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rq/snapshot"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func TestServerRecordsAndReplays(t *testing.T) {
+	hits := map[string]int{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.URL.Path]++
+		w.Header().Set("X-Path", r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "body of %s", r.URL.Path)
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	server, err := NewServer(dir, upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{"/a_b", "/a/b", "/a_b"}
+	for _, path := range paths {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if recorder.Code != http.StatusCreated {
+			t.Errorf("%s: status %d", path, recorder.Code)
+		}
+		if got := recorder.Body.String(); got != "body of "+path {
+			t.Errorf("%s: body %q", path, got)
+		}
+		if got := recorder.Header().Get("X-Path"); got != path {
+			t.Errorf("%s: X-Path %q", path, got)
+		}
+	}
+
+	if hits["/a_b"] != 1 || hits["/a/b"] != 1 {
+		t.Errorf("upstream hits = %v, want one per path", hits)
+	}
+
+	replayed, err := NewServer(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed.snapshots) != 2 {
+		t.Errorf("recorded %d snapshots, want 2 kept apart", len(replayed.snapshots))
+	}
+}
+
+func TestServerWithoutRecording(t *testing.T) {
+	dir := t.TempDir()
+	body := []byte{0x00, 0xff, 0x10}
+	if err := snapshot.Save(dir, snapshot.New("bin", "GET", "http://x/bin", 200, "200 OK", map[string][]string{"Content-Length": {"99"}}, body)); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/bin", http.StatusOK, string(body)},
+		{"POST", "/bin", http.StatusNotFound, ""},
+		{"GET", "/missing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+		if recorder.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, recorder.Code, tt.status)
+		}
+		if tt.body != "" && recorder.Body.String() != tt.body {
+			t.Errorf("%s %s: body %q", tt.method, tt.path, recorder.Body.String())
+		}
+		if recorder.Header().Get("Content-Length") == "99" {
+			t.Errorf("%s %s: replayed the recorded Content-Length", tt.method, tt.path)
+		}
+	}
+}
+
+func TestNewServerRejectsInvalidTarget(t *testing.T) {
+	for _, target := range []string{"localhost:8080", "://x", "/path"} {
+		if _, err := NewServer(t.TempDir(), target); err == nil {
+			t.Errorf("NewServer accepted %q", target)
+		}
+	}
+}
+
+func TestMockListenAddress(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	_, port, _ := net.SplitHostPort(busy.Addr().String())
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".dock"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "loopback by default", args: []string{"mock", "--port", port}, want: "listen tcp 127.0.0.1:" + port + ": "},
+		{name: "--host", args: []string{"mock", "--port", port, "--host", "0.0.0.0"}, want: "listen tcp 0.0.0.0:" + port + ": "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			// The port is taken, so the server fails right after choosing
+			// its address instead of serving.
+			err := app.Run(tt.args)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// plainReader reads whole lines, used when stdin is not a terminal.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+	echo    bool
+}
+
+func (reader *plainReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(reader.out, prompt)
+	if !reader.scanner.Scan() {
+		if err := reader.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	line := reader.scanner.Text()
+	if reader.echo {
+		fmt.Fprintln(reader.out, line)
+	}
+	return line, nil
+}
+
+// editor is a minimal line editor with history and tab completion.
+type editor struct {
+	in       *os.File
+	reader   *bufio.Reader
+	out      io.Writer
+	history  *[]string
+	complete func(line string) []string
+}
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyTab       = 9
+	keyEnter     = 13
+	keyNewline   = 10
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyBackspace = 127
+	keyCtrlH     = 8
+)
+
+func (ed *editor) ReadLine(prompt string) (string, error) {
+	fd := int(ed.in.Fd())
+	state, err := makeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore(fd, state)
+
+	reader := ed.reader
+	line := []rune{}
+	index := len(*ed.history)
+
+	redraw := func() {
+		fmt.Fprintf(ed.out, "\r\033[K%s%s", prompt, string(line))
+	}
+	redraw()
+
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyNewline:
+			fmt.Fprintln(ed.out)
+			return string(line), nil
+
+		case keyCtrlC:
+			fmt.Fprintln(ed.out, "^C")
+			line = line[:0]
+			index = len(*ed.history)
+			redraw()
+
+		case keyCtrlD:
+			if len(line) == 0 {
+				fmt.Fprintln(ed.out)
+				return "", io.EOF
+			}
+
+		case keyCtrlU:
+			line = line[:0]
+			redraw()
+
+		case keyBackspace, keyCtrlH:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+
+		case keyTab:
+			line = ed.completeLine(line)
+			redraw()
+
+		case keyEscape:
+			if next, _, _ := reader.ReadRune(); next != '[' {
+				continue
+			}
+			arrow, _, _ := reader.ReadRune()
+			switch arrow {
+			case 'A':
+				if index > 0 {
+					index--
+					line = []rune((*ed.history)[index])
+				}
+			case 'B':
+				if index < len(*ed.history)-1 {
+					index++
+					line = []rune((*ed.history)[index])
+				} else {
+					index = len(*ed.history)
+					line = line[:0]
+				}
+			}
+			redraw()
+
+		default:
+			if r >= 32 {
+				line = append(line, r)
+				fmt.Fprint(ed.out, string(r))
+			}
+		}
+	}
+}
+
+// completeLine extends the last word to the longest prefix shared by the
+// candidates, listing them when that does not add anything.
+func (ed *editor) completeLine(line []rune) []rune {
+	text := string(line)
+	candidates := ed.complete(text)
+	if len(candidates) == 0 {
+		return line
+	}
+
+	start := strings.LastIndex(text, " ") + 1
+	word := text[start:]
+	prefix := commonPrefix(candidates)
+
+	if len(candidates) == 1 {
+		prefix += " "
+	}
+
+	if len(prefix) > len(word) {
+		return []rune(text[:start] + prefix)
+	}
+
+	fmt.Fprintf(ed.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	return line
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
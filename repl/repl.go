@@ -0,0 +1,270 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"rq/cli"
+	"rq/dock"
+	"rq/request"
+	"rq/request/http"
+	"slices"
+	"strings"
+
+	"github.com/marcomit/args"
+)
+
+const historyLimit = 500
+
+var builtins = []string{"help", "ls", "use", "last", "responses", "history", "exit", "quit"}
+
+var methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "TRACE"}
+
+// Repl runs commands typed at a prompt inside a dock. Request names run the
+// request, lines starting with an HTTP method are sent as they are and
+// anything else is handed to the rq command line.
+type Repl struct {
+	app     *args.Parser
+	ctx     *dock.RqContext
+	session *request.Session
+	env     string
+	out     io.Writer
+	history []string
+}
+
+func Setup(app *args.Parser) {
+	app.Command("repl", "Open an interactive prompt inside the current dock").
+		Option("env", "e", "Environment used by the requests of the session").
+		Action(func(r *args.Result) error {
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			return Start(app, ctx, r.Options["env"], os.Stdin, os.Stdout)
+		})
+}
+
+// Start reads commands from in until it is closed or the user exits. When in
+// is a terminal the prompt supports history and tab completion.
+func Start(app *args.Parser, ctx *dock.RqContext, env string, in *os.File, out io.Writer) error {
+	session, err := request.StartSession()
+	if err != nil {
+		return err
+	}
+	defer request.EndSession()
+
+	repl := &Repl{app: app, ctx: ctx, session: session, env: env, out: out}
+	repl.history = loadHistory(ctx.Dock)
+
+	var reader lineReader
+	if isTerminal(int(in.Fd())) {
+		reader = &editor{in: in, reader: bufio.NewReader(in), out: out, history: &repl.history, complete: repl.Complete}
+	} else {
+		reader = &plainReader{scanner: bufio.NewScanner(in), out: out, echo: true}
+	}
+
+	fmt.Fprintf(out, "rq repl in %s, type 'help' for the commands\n", ctx.Dock)
+
+	for {
+		line, err := reader.ReadLine(repl.prompt())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		repl.remember(line)
+
+		if line == "exit" || line == "quit" {
+			break
+		}
+
+		if err := repl.Execute(line); err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+	}
+
+	return saveHistory(ctx.Dock, repl.history)
+}
+
+func (repl *Repl) prompt() string {
+	if repl.env == "" {
+		return "rq> "
+	}
+	return fmt.Sprintf("rq (%s)> ", repl.env)
+}
+
+// Execute runs a single line of input.
+func (repl *Repl) Execute(line string) error {
+	fields := strings.Fields(line)
+	name, rest := fields[0], fields[1:]
+
+	switch name {
+	case "help":
+		repl.printHelp()
+		return nil
+
+	case "ls":
+		prefix := strings.Join(rest, "")
+		for _, req := range request.RequestNames(repl.ctx) {
+			if strings.HasPrefix(req, prefix) {
+				fmt.Fprintf(repl.out, "  %s\n", req)
+			}
+		}
+		return nil
+
+	case "use":
+		if len(rest) > 0 {
+			repl.env = rest[0]
+		}
+		if repl.env == "" {
+			fmt.Fprintln(repl.out, "Using the default environment")
+		} else {
+			fmt.Fprintf(repl.out, "Using environment: %s\n", repl.env)
+		}
+		return nil
+
+	case "last":
+		response, ok := repl.session.Response(strings.Join(rest, ""))
+		if !ok {
+			return errors.New("no response stored yet")
+		}
+		response.Fprint(repl.out)
+		return nil
+
+	case "responses":
+		for _, stored := range repl.session.Names() {
+			response, _ := repl.session.Response(stored)
+			fmt.Fprintf(repl.out, "  %-30s %s\n", stored, response.Status)
+		}
+		return nil
+
+	case "history":
+		for i, entry := range repl.history {
+			fmt.Fprintf(repl.out, "%4d  %s\n", i+1, entry)
+		}
+		return nil
+
+	case "repl":
+		return errors.New("already in a repl")
+	}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	options := http.ExecuteOptions{Environment: repl.env, Writer: repl.out, Context: interrupted}
+
+	if slices.Contains(methods, name) && len(rest) > 0 {
+		return request.RunInline(repl.ctx, line, options)
+	}
+
+	if slices.Contains(request.RequestNames(repl.ctx), name) && len(rest) == 0 {
+		_, err := request.Execute(repl.ctx, name, options)
+		return err
+	}
+
+	if name == "run" && repl.env != "" && !slices.Contains(rest, "--env") && !slices.Contains(rest, "-e") {
+		fields = append(fields, "--env", repl.env)
+	}
+	err := cli.Run(repl.app, fields)
+	if errors.Is(err, cli.ErrUnknownCommand) {
+		return fmt.Errorf("unknown command or request: %s", name)
+	}
+	return err
+}
+
+// Complete returns the candidates for the last word of line: commands and
+// request names for the first word, request names after 'run'.
+func (repl *Repl) Complete(line string) []string {
+	fields := strings.Fields(line)
+	word := ""
+	if !strings.HasSuffix(line, " ") && len(fields) > 0 {
+		word = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+
+	var options []string
+	switch {
+	case len(fields) == 0:
+		options = append(slices.Clone(builtins), request.RequestNames(repl.ctx)...)
+	case fields[0] == "run" || fields[0] == "last" || fields[0] == "ls":
+		options = request.RequestNames(repl.ctx)
+	}
+
+	var candidates []string
+	for _, option := range options {
+		if strings.HasPrefix(option, word) {
+			candidates = append(candidates, option)
+		}
+	}
+	return candidates
+}
+
+func (repl *Repl) remember(line string) {
+	if n := len(repl.history); n > 0 && repl.history[n-1] == line {
+		return
+	}
+	repl.history = append(repl.history, line)
+	if len(repl.history) > historyLimit {
+		repl.history = repl.history[len(repl.history)-historyLimit:]
+	}
+}
+
+func (repl *Repl) printHelp() {
+	fmt.Fprintln(repl.out, `Commands:
+  <request>            Run a request of the dock
+  GET <url> ...        Send an ad-hoc request, '\n' separates headers and body
+  ls [prefix]          List the requests of the dock
+  use [env]            Switch the environment of the session
+  last [request]       Print the last response, or the last one of a request
+  responses            List the stored responses
+  history              Show the command history
+  exit, quit           Leave the repl
+
+Any other line runs as an rq command, e.g. 'env show' or 'run users --brief'.
+Cookies are shared by every request of the session.`)
+}
+
+func historyPath(dockPath string) string {
+	return filepath.Join(dockPath, ".rq", "history")
+}
+
+func loadHistory(dockPath string) []string {
+	data, err := os.ReadFile(historyPath(dockPath))
+	if err != nil {
+		return nil
+	}
+
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	return history
+}
+
+func saveHistory(dockPath string, history []string) error {
+	path := historyPath(dockPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package repl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rq/cli"
+	"rq/dock"
+	"rq/request"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func writeDock(t *testing.T, files map[string]string) *dock.RqContext {
+	t.Helper()
+	root := t.TempDir()
+	files[".dock"] = "shop"
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestStartScripted(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/"})
+			fmt.Fprint(w, "logged in")
+		case "/me":
+			cookie, err := r.Cookie("session")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, "session=%s host=%s", cookie.Value, r.Host)
+		default:
+			fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ctx := writeDock(t, map[string]string{
+		".env":            "BASE_URL=" + server.URL + "\n",
+		".env.staging":    "BASE_URL=" + server.URL + "/staging\n",
+		"auth/login.http": "POST /login\n",
+		"users/me.http":   "GET /me\n",
+	})
+
+	script := []string{
+		"ls users",
+		"auth/login",
+		"users/me",
+		"last",
+		"responses",
+		"use staging",
+		"GET " + server.URL + "/inline",
+		"repl",
+		"bogus",
+		"history",
+		"exit",
+		"users/me",
+	}
+	in := filepath.Join(t.TempDir(), "script")
+	if err := os.WriteFile(in, []byte(strings.Join(script, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	app := args.New("rq").Action(func(r *args.Result) error {
+		return fmt.Errorf("%w: %s", cli.ErrUnknownCommand, r.Positionals[0])
+	})
+	request.Setup(app)
+
+	var out strings.Builder
+	if err := Start(app, ctx, "", file, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"rq repl in " + ctx.Dock,
+		"rq> ls users\n  users/me\n",
+		"session=s3cr3t host=",
+		"logged in",
+		"auth/login",
+		"Using environment: staging",
+		"rq (staging)> GET ",
+		"GET /inline",
+		"Error: already in a repl",
+		"Error: unknown command or request: bogus",
+		"   1  ls users\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "session=s3cr3t host=") != 2 {
+		t.Errorf("users/me should run once and be printed again by 'last':\n%s", got)
+	}
+
+	history := loadHistory(ctx.Dock)
+	if want := script[:len(script)-1]; !slices.Equal(history, want) {
+		t.Errorf("saved history = %q, want %q", history, want)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	ctx := writeDock(t, map[string]string{
+		"users/list.http":   "GET /users\n",
+		"users/create.http": "POST /users\n",
+		"health.http":       "GET /health\n",
+	})
+	repl := &Repl{ctx: ctx}
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", append(slices.Clone(builtins), "health", "users/create", "users/list")},
+		{"h", []string{"help", "history", "health"}},
+		{"users/", []string{"users/create", "users/list"}},
+		{"run ", []string{"health", "users/create", "users/list"}},
+		{"run users/l", []string{"users/list"}},
+		{"last he", []string{"health"}},
+		{"use ", nil},
+		{"env sh", nil},
+	}
+
+	for _, tt := range tests {
+		if got := repl.Complete(tt.line); !slices.Equal(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestRemember(t *testing.T) {
+	repl := &Repl{}
+	for i := range historyLimit + 10 {
+		repl.remember(fmt.Sprintf("run %d", i))
+		repl.remember(fmt.Sprintf("run %d", i))
+	}
+
+	if len(repl.history) != historyLimit {
+		t.Fatalf("history has %d entries, want %d", len(repl.history), historyLimit)
+	}
+	if first, last := repl.history[0], repl.history[historyLimit-1]; first != "run 10" || last != fmt.Sprintf("run %d", historyLimit+9) {
+		t.Errorf("history spans %q to %q", first, last)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"rq/directive"
+	"rq/request/http"
+	"rq/request/network"
+	"strconv"
+	"strings"
+)
+
+var assertPattern = regexp.MustCompile(`^(status|body|header\s+\S+|jsonpath\s+\S+)\s+(==|!=|<=|>=|<|>|contains|matches|exists)(?:\s+(.*))?$`)
+
+// assertion is an "@assert subject op expected" line. The expected value is
+// resolved with the rest of the request, so it can use config and captured
+// variables: @assert jsonpath $.id == {{expectedId}}
+type assertion struct {
+	Line     int
+	Text     string
+	Subject  string // status, body, header or jsonpath
+	Arg      string // Header name or JSON path
+	Op       string
+	Expected string
+}
+
+func parseAssertion(text string) (assertion, error) {
+	match := assertPattern.FindStringSubmatch(text)
+	if match == nil {
+		return assertion{}, fmt.Errorf("invalid @assert %q, expected: status|body|header NAME|jsonpath PATH, an operator and a value", text)
+	}
+
+	a := assertion{Text: text, Op: match[2], Expected: unquote(strings.TrimSpace(match[3]))}
+	a.Subject, a.Arg, _ = strings.Cut(match[1], " ")
+	a.Arg = strings.TrimSpace(a.Arg)
+
+	if a.Op != "exists" && match[3] == "" {
+		return assertion{}, fmt.Errorf("@assert %s %s needs a value to compare with", match[1], a.Op)
+	}
+	if a.Op == "matches" {
+		if _, err := regexp.Compile(a.Expected); err != nil {
+			return assertion{}, fmt.Errorf("invalid @assert pattern: %w", err)
+		}
+	}
+	return a, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// extractAssertions removes the @assert lines that precede the request line.
+func extractAssertions(content string) (string, []assertion, error) {
+	content, directives := directive.Extract(content, "assert")
+
+	var assertions []assertion
+	for _, d := range directives {
+		a, err := parseAssertion(d.Args)
+		if err != nil {
+			return content, nil, fmt.Errorf("line %d: %w", d.Line, err)
+		}
+		a.Line = d.Line
+		assertions = append(assertions, a)
+	}
+
+	return content, assertions, nil
+}
+
+// actual returns the value the assertion looks at, and whether it exists.
+func (a assertion) actual(response *http.HttpResponse) (string, bool) {
+	switch a.Subject {
+	case "status":
+		return strconv.Itoa(response.StatusCode), true
+	case "body":
+		return response.BodyString(), true
+	case "header":
+		values := response.Header(a.Arg)
+		return strings.Join(values, ", "), len(values) > 0
+	}
+
+	var body any
+	if err := json.Unmarshal(response.Body, &body); err != nil {
+		return "", false
+	}
+	value, err := jsonPath(body, a.Arg)
+	return value, err == nil
+}
+
+func (a assertion) check(response *http.HttpResponse) error {
+	actual, found := a.actual(response)
+	if a.Op == "exists" {
+		if !found {
+			return fmt.Errorf("not found")
+		}
+		return nil
+	}
+	if !found {
+		return fmt.Errorf("not found, expected %s %s", a.Op, a.Expected)
+	}
+
+	var ok bool
+	switch a.Op {
+	case "contains":
+		ok = strings.Contains(actual, a.Expected)
+	case "matches":
+		ok = regexp.MustCompile(a.Expected).MatchString(actual)
+	default:
+		ok = compare(actual, a.Op, a.Expected)
+	}
+
+	if !ok {
+		return fmt.Errorf("got %q, expected %s %q", actual, a.Op, a.Expected)
+	}
+	return nil
+}
+
+// compare compares numbers numerically and anything else as text.
+func compare(actual, op, expected string) bool {
+	result := strings.Compare(actual, expected)
+	x, errX := strconv.ParseFloat(actual, 64)
+	y, errY := strconv.ParseFloat(expected, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			result = -1
+		case x > y:
+			result = 1
+		default:
+			result = 0
+		}
+	}
+
+	switch op {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// checkAssertions prints the outcome of each assertion and fails when any of
+// them does not hold.
+func checkAssertions(w io.Writer, response *http.HttpResponse, assertions []assertion) error {
+	if len(assertions) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "\nAssertions:")
+	failed := 0
+	for _, a := range assertions {
+		if err := a.check(response); err != nil {
+			failed++
+			fmt.Fprintf(w, "  %s %s: %v\n", network.Colorize(network.ColorRed, "FAIL"), a.Text, err)
+			continue
+		}
+		fmt.Fprintf(w, "  %s %s\n", network.Colorize(network.ColorGreen, "PASS"), a.Text)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d assertions failed", failed, len(assertions))
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"rq/request/http"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func TestHeaderAssertions(t *testing.T) {
+	response := &http.HttpResponse{Headers: map[string][]string{
+		"Content-Type": {"application/json; charset=utf-8"},
+		"X-Rate-Limit": {"100"},
+		"set-cookie":   {"a=1", "b=2"},
+	}}
+
+	tests := []struct {
+		text    string
+		wantErr string
+	}{
+		{text: "header content-type contains json"},
+		{text: "header CONTENT-TYPE matches ^application/"},
+		{text: "header x-rate-limit >= 50"},
+		{text: "header X-RATE-LIMIT == 100"},
+		{text: "header Set-Cookie == \"a=1, b=2\""},
+		{text: "header set-cookie exists"},
+		{text: "header x-missing exists", wantErr: "not found"},
+		{text: "header x-missing == 1", wantErr: "not found, expected == 1"},
+		{text: "header x-rate-limit < 10", wantErr: `got "100", expected < "10"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			a, err := parseAssertion(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = a.check(response)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("check() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+				t.Errorf("check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAssertion(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    assertion
+		wantErr string
+	}{
+		{text: "status == 200", want: assertion{Subject: "status", Op: "==", Expected: "200"}},
+		{text: `jsonpath $.name == "Ada Lovelace"`, want: assertion{Subject: "jsonpath", Arg: "$.name", Op: "==", Expected: "Ada Lovelace"}},
+		{text: "header X-Id  matches   '^[0-9]+$'", want: assertion{Subject: "header", Arg: "X-Id", Op: "matches", Expected: "^[0-9]+$"}},
+		{text: "jsonpath $.id exists", want: assertion{Subject: "jsonpath", Arg: "$.id", Op: "exists"}},
+		{text: "status is 200", wantErr: `invalid @assert "status is 200"`},
+		{text: "latency < 100", wantErr: `invalid @assert "latency < 100"`},
+		{text: "status ==", wantErr: "@assert status == needs a value to compare with"},
+		{text: "body matches (", wantErr: "invalid @assert pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseAssertion(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("parseAssertion() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.Text = tt.text
+			if got != tt.want {
+				t.Errorf("parseAssertion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	response := &http.HttpResponse{StatusCode: 201, Body: []byte(`{"id":42,"name":"Ada","price":9.5,"tags":["a","b"]}`)}
+
+	tests := []struct {
+		text    string
+		wantErr string
+	}{
+		{text: "status == 201"},
+		{text: "status >= 200"},
+		{text: "status < 300"},
+		{text: "status != 200"},
+		{text: "status == 200", wantErr: `got "201", expected == "200"`},
+		{text: "body contains \"Ada\""},
+		{text: "body matches \"id\":\\d+"},
+		{text: "jsonpath $.id == 42"},
+		{text: "jsonpath $.id == 42.0"},
+		{text: "jsonpath $.price > 10", wantErr: `got "9.5", expected > "10"`},
+		{text: "jsonpath $.price < 10"},
+		{text: "jsonpath $.name > Ab"},
+		{text: "jsonpath $.tags[1] == b"},
+		{text: `jsonpath $.tags == ["a","b"]`},
+		{text: "jsonpath $.email exists", wantErr: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			a, err := parseAssertion(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = a.check(response)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("check() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+				t.Errorf("check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunAssertionsWithVariables(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		fmt.Fprint(w, `{"id":7,"owner":"ada","total":120}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		asserts    string
+		session    string
+		wantOutput []string
+		wantErr    string
+	}{
+		{
+			name:       "config values",
+			asserts:    "@assert jsonpath $.id == {{ expectedId }}\n@assert jsonpath $.owner == ${OWNER}\n",
+			wantOutput: []string{"PASS jsonpath $.id == 7", "PASS jsonpath $.owner == ada"},
+		},
+		{
+			name:       "captured value",
+			asserts:    "@assert jsonpath $.total <= {{budget}}\n",
+			session:    `{"budget":"100"}`,
+			wantOutput: []string{`FAIL jsonpath $.total <= 100: got "120", expected <= "100"`},
+			wantErr:    "1 of 1 assertions failed",
+		},
+		{
+			name:       "mismatch",
+			asserts:    "@assert status == 200\n@assert jsonpath $.owner == {{ OTHER }}\n",
+			wantOutput: []string{"PASS status == 200", `FAIL jsonpath $.owner == grace: got "ada", expected == "grace"`},
+			wantErr:    "1 of 2 assertions failed",
+		},
+		{
+			name:    "unknown variable",
+			asserts: "@assert jsonpath $.id == {{ missingId }}\n",
+			wantErr: "variable 'missingId' not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{
+				".env":       "BASE_URL=" + server.URL + "\nexpectedId=7\nOWNER=ada\nOTHER=grace\n",
+				"order.http": tt.asserts + "GET {{BASE_URL}}/orders/7\n",
+			}
+			if tt.session != "" {
+				files[".rq/session.json"] = tt.session
+			}
+			t.Chdir(writeDock(t, files))
+
+			app := args.New("rq")
+			Setup(app)
+			var err error
+			out := captureStdout(t, func() {
+				err = app.Run([]string{"run", "order"})
+			})
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("run error = %v, want %q", err, tt.wantErr)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
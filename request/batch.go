@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"rq/dock"
+	"rq/request/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type batchResult struct {
+	Name        string
+	Label       string            // Shown in the output instead of Name when set
+	Variables   map[string]string // Extra variables for this run
+	Environment string            // Environment for this run, overriding the shared one
+	Output      bytes.Buffer
+	Response    *http.HttpResponse
+	Err         error
+	Duration    time.Duration
+}
+
+// RunMany executes the named requests with at most parallel running at the
+// same time. Output is buffered per request and printed in the given order.
+func RunMany(ctx *dock.RqContext, names []string, options http.ExecuteOptions, parallel int) error {
+	results := make([]*batchResult, len(names))
+	for i, name := range names {
+		results[i] = &batchResult{Name: name}
+	}
+	return runBatch(ctx, results, options, parallel, "requests")
+}
+
+// RunEnvironments executes one request against each environment, so the
+// summary compares their status and latency.
+func RunEnvironments(ctx *dock.RqContext, name string, envs []string, options http.ExecuteOptions, parallel int) error {
+	results := make([]*batchResult, len(envs))
+	for i, env := range envs {
+		results[i] = &batchResult{Name: name, Label: fmt.Sprintf("%s [%s]", name, env), Environment: env}
+	}
+	return runBatch(ctx, results, options, parallel, "environments")
+}
+
+func (result *batchResult) label() string {
+	if result.Label != "" {
+		return result.Label
+	}
+	return result.Name
+}
+
+func runBatch(ctx *dock.RqContext, results []*batchResult, options http.ExecuteOptions, parallel int, noun string) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	done := make([]chan struct{}, len(results))
+	for i := range results {
+		done[i] = make(chan struct{})
+	}
+
+	start := time.Now()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := results[i]
+				opts := options
+				opts.Writer = &result.Output
+				opts.Variables = maps.Clone(result.Variables)
+				if opts.Variables == nil {
+					opts.Variables = make(map[string]string)
+				}
+				maps.Copy(opts.Variables, options.Variables)
+				if result.Environment != "" {
+					opts.Environment = result.Environment
+				}
+
+				began := time.Now()
+				result.Response, result.Err = Execute(ctx, result.Name, opts)
+				result.Duration = time.Since(began)
+				close(done[i])
+			}
+		}()
+	}
+
+	go func() {
+		for i := range results {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	out := options.Output()
+	for i, result := range results {
+		<-done[i]
+		fmt.Fprintf(out, "=== %s ===\n", result.label())
+		out.Write(result.Output.Bytes())
+		if result.Err != nil {
+			fmt.Fprintf(out, "Error: %v\n", result.Err)
+		}
+		fmt.Fprintln(out)
+	}
+	wg.Wait()
+
+	wall := time.Since(start)
+	failed := 0
+
+	if options.JUnitFile != "" {
+		if err := writeJUnit(options.JUnitFile, filepath.Base(ctx.Dock), results, start, wall); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out, "Summary:")
+	for _, result := range results {
+		status := "OK"
+		if result.Err != nil {
+			status = "FAILED"
+			failed++
+		}
+		code := "-"
+		if result.Response != nil {
+			code = strconv.Itoa(result.Response.StatusCode)
+		}
+		fmt.Fprintf(out, "  %-30s %-7s %-4s %v\n", result.label(), status, code, result.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(out, "Total: %d %s, %d failed, %v wall-clock\n", len(results), noun, failed, wall.Round(time.Millisecond))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d %s failed", failed, len(results), noun)
+	}
+	return nil
+}
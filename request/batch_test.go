@@ -0,0 +1,206 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bytes"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"rq/dock"
+	"rq/request/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/marcomit/args"
+)
+
+// batchDock creates a dock whose requests GET /<name> on the server.
+func batchDock(t *testing.T, serverURL string, names []string) *dock.RqContext {
+	t.Helper()
+	files := map[string]string{".env": "BASE_URL=" + serverURL + "\n"}
+	for _, name := range names {
+		files[name+".http"] = "GET {{BASE_URL}}/" + name + "\n"
+	}
+	ctx, err := dock.ContextAt(writeDock(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestRunManyBoundsConcurrency(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f"}
+
+	tests := []struct {
+		parallel int
+		want     int32
+	}{
+		{parallel: 0, want: 1},
+		{parallel: 1, want: 1},
+		{parallel: 2, want: 2},
+		{parallel: 10, want: int32(len(names))},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.parallel), func(t *testing.T) {
+			var inflight, peak atomic.Int32
+			server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				n := inflight.Add(1)
+				defer inflight.Add(-1)
+				for {
+					current := peak.Load()
+					if n <= current || peak.CompareAndSwap(current, n) {
+						break
+					}
+				}
+				time.Sleep(50 * time.Millisecond)
+				fmt.Fprintf(w, "body of %s", r.URL.Path)
+			}))
+			defer server.Close()
+
+			var out bytes.Buffer
+			ctx := batchDock(t, server.URL, names)
+			if err := RunMany(ctx, names, http.ExecuteOptions{Writer: &out}, tt.parallel); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := peak.Load(); got != tt.want {
+				t.Errorf("peak concurrency = %d, want %d", got, tt.want)
+			}
+
+			// Each block holds its own response and blocks appear in the given order.
+			output := out.String()
+			last := -1
+			for i, name := range names {
+				header := strings.Index(output, "=== "+name+" ===")
+				if header < last {
+					t.Fatalf("block %s is out of order:\n%s", name, output)
+				}
+				block := output[header:]
+				if i+1 < len(names) {
+					block = block[:strings.Index(block, "=== "+names[i+1]+" ===")]
+				}
+				if !strings.Contains(block, "body of /"+name) || strings.Count(block, "body of") != 1 {
+					t.Errorf("block %s does not hold only its own response:\n%s", name, block)
+				}
+				last = header
+			}
+			if !strings.Contains(output, fmt.Sprintf("Total: %d requests, 0 failed", len(names))) {
+				t.Errorf("missing summary:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestRunManyReportsFailures(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	ctx := batchDock(t, server.URL, []string{"a"})
+	err := RunMany(ctx, []string{"a", "missing"}, http.ExecuteOptions{Writer: &out}, 2)
+	if err == nil || err.Error() != "1 of 2 requests failed" {
+		t.Fatalf("RunMany() error = %v, want 1 of 2 failed", err)
+	}
+
+	output := out.String()
+	for _, want := range []string{"a", "OK", "200", "missing", "FAILED", "Total: 2 requests, 1 failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunEnvironments(t *testing.T) {
+	hosts := make(map[string]string)
+	for name, status := range map[string]int{"dev": 200, "staging": 500, "prod": 200} {
+		server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			w.WriteHeader(status)
+			fmt.Fprint(w, name)
+		}))
+		defer server.Close()
+		hosts[name] = server.URL
+	}
+
+	ctx, err := dock.ContextAt(writeDock(t, map[string]string{
+		".env.dev":     "BASE_URL=" + hosts["dev"] + "\n",
+		".env.staging": "BASE_URL=" + hosts["staging"] + "\n",
+		".env.prod":    "BASE_URL=" + hosts["prod"] + "\n",
+		"health.http":  "@assert status == 200\nGET /health\n",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		envs    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "all pass",
+			envs: []string{"dev", "prod"},
+			want: []string{"=== health [dev] ===", "=== health [prod] ===", "health [dev]                   OK      200", "health [prod]                  OK      200", "Total: 2 environments, 0 failed"},
+		},
+		{
+			name:    "one fails its assertion",
+			envs:    []string{"dev", "staging"},
+			want:    []string{"health [dev]                   OK      200", "health [staging]               FAILED  500", "Total: 2 environments, 1 failed"},
+			wantErr: "1 of 2 environments failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := RunEnvironments(ctx, "health", tt.envs, http.ExecuteOptions{Writer: &out, NoRetry: true}, 1)
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("RunEnvironments() error = %v, want %q", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			if first, second := strings.Index(out.String(), "["+tt.envs[0]+"]"), strings.Index(out.String(), "["+tt.envs[1]+"]"); first > second {
+				t.Errorf("environments printed out of order:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestRunEnvFlagSplitsEnvironments(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env.dev":    "BASE_URL=" + server.URL + "\n",
+		".env.prod":   "BASE_URL=" + server.URL + "\n",
+		"health.http": "GET /health\n",
+	})
+	t.Chdir(root)
+
+	app := args.New("rq")
+	Setup(app)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Run([]string{"run", "health", "--env", "dev, prod"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "=== health [dev] ===") || !strings.Contains(out, "=== health [prod] ===") {
+		t.Errorf("output does not compare both environments:\n%s", out)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	"io"
+	"rq/dock"
+	"rq/request/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type BenchOptions struct {
+	Requests    int // Measured requests
+	Concurrency int // Requests in flight at once
+	Warmup      int // Requests sent first and left out of the results
+}
+
+type benchSample struct {
+	Duration time.Duration
+	ConnWait time.Duration // Time spent getting a connection, part of Duration
+	Status   int
+	Err      error
+}
+
+// Bench sends the named request repeatedly and prints latency percentiles.
+// Warmup requests go out first with the same concurrency, so DNS, TLS and
+// the connection pool are primed before anything is measured.
+func Bench(ctx *dock.RqContext, name string, options http.ExecuteOptions, bench BenchOptions) error {
+	req, err := LoadWithOptions(ctx, name, options)
+	if err != nil {
+		return err
+	}
+
+	out := options.Output()
+	fmt.Fprintf(out, "Benchmarking %s %s: %d requests, %d concurrent", req.Method, req.URL, bench.Requests, bench.Concurrency)
+	if bench.Warmup > 0 {
+		fmt.Fprintf(out, ", %d warmup", bench.Warmup)
+	}
+	fmt.Fprintln(out)
+
+	if bench.Warmup > 0 {
+		sendConcurrently(req, bench.Warmup, bench.Concurrency)
+	}
+
+	started := time.Now()
+	samples := sendConcurrently(req, bench.Requests, bench.Concurrency)
+	printBench(out, samples, time.Since(started))
+
+	failed := 0
+	for _, sample := range samples {
+		if sample.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d requests failed", failed, len(samples))
+	}
+	return nil
+}
+
+// sendConcurrently executes count copies of req with at most concurrency in
+// flight. It stops early when the request's context is canceled.
+func sendConcurrently(req *http.HttpRequest, count, concurrency int) []benchSample {
+	samples := make([]benchSample, count)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				clone := *req
+				began := time.Now()
+				response, err := clone.Execute()
+				samples[i] = benchSample{Duration: time.Since(began), Err: err}
+				if response != nil {
+					samples[i].Status = response.StatusCode
+					samples[i].ConnWait = response.ConnWait
+				}
+			}
+		}()
+	}
+
+	sent := 0
+	for ; sent < count; sent++ {
+		if req.Context != nil && req.Context.Err() != nil {
+			break
+		}
+		jobs <- sent
+	}
+	close(jobs)
+	wg.Wait()
+
+	return samples[:sent]
+}
+
+func printBench(out io.Writer, samples []benchSample, wall time.Duration) {
+	var durations, waits, server []time.Duration
+	statuses := make(map[int]int)
+	failures := make(map[string]int)
+	for _, sample := range samples {
+		if sample.Err != nil {
+			failures[sample.Err.Error()]++
+			continue
+		}
+		durations = append(durations, sample.Duration)
+		waits = append(waits, sample.ConnWait)
+		server = append(server, sample.Duration-sample.ConnWait)
+		statuses[sample.Status]++
+	}
+
+	fmt.Fprintf(out, "\nRequests: %d, failed %d, in %v (%.1f req/s)\n",
+		len(samples), len(samples)-len(durations), wall.Round(time.Millisecond), float64(len(samples))/wall.Seconds())
+
+	// Connection wait grows when every pooled connection is busy, so it
+	// tells client saturation apart from a slow server.
+	if len(durations) > 0 {
+		fmt.Fprintf(out, "\nLatency      %10s %10s %10s %10s %10s\n", "min", "p50", "p90", "p99", "max")
+		for _, row := range []struct {
+			name   string
+			values []time.Duration
+		}{{"total", durations}, {"conn wait", waits}, {"server", server}} {
+			slices.Sort(row.values)
+			fmt.Fprintf(out, "  %-10s", row.name)
+			for _, p := range []int{0, 50, 90, 99, 100} {
+				fmt.Fprintf(out, " %10v", percentile(row.values, p).Round(time.Microsecond))
+			}
+			fmt.Fprintln(out)
+		}
+	}
+
+	if len(statuses) > 0 {
+		codes := make([]int, 0, len(statuses))
+		for code := range statuses {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		fmt.Fprintln(out, "\nStatus codes:")
+		for _, code := range codes {
+			fmt.Fprintf(out, "  %d  %d\n", code, statuses[code])
+		}
+	}
+
+	if len(failures) > 0 {
+		fmt.Fprintln(out, "\nErrors:")
+		for message, count := range failures {
+			fmt.Fprintf(out, "  %d  %s\n", count, message)
+		}
+	}
+}
+
+// percentile uses the nearest-rank method on sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"rq/request/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/marcomit/args"
+)
+
+func TestBenchWarmup(t *testing.T) {
+	tests := []struct {
+		name        string
+		bench       BenchOptions
+		wantHits    int32
+		wantSummary string
+	}{
+		{name: "no warmup", bench: BenchOptions{Requests: 5, Concurrency: 1}, wantHits: 5, wantSummary: "Requests: 5, failed 0"},
+		{name: "warmup", bench: BenchOptions{Requests: 5, Concurrency: 1, Warmup: 3}, wantHits: 8, wantSummary: "Requests: 5, failed 0"},
+		{name: "concurrent warmup", bench: BenchOptions{Requests: 4, Concurrency: 2, Warmup: 2}, wantHits: 6, wantSummary: "Requests: 4, failed 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Warmup requests get a 503, so any that leak into the results
+			// show up in the status codes.
+			var hits atomic.Int32
+			server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				if hits.Add(1) <= int32(tt.bench.Warmup) {
+					w.WriteHeader(nethttp.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+			ctx := batchDock(t, server.URL, []string{"ping"})
+
+			var out strings.Builder
+			if err := Bench(ctx, "ping", http.ExecuteOptions{Writer: &out}, tt.bench); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("server got %d requests, want %d", got, tt.wantHits)
+			}
+			if !strings.Contains(out.String(), tt.wantSummary) {
+				t.Errorf("output missing %q:\n%s", tt.wantSummary, out.String())
+			}
+			if want := fmt.Sprintf("  200  %d\n", tt.bench.Requests); !strings.Contains(out.String(), want) {
+				t.Errorf("output missing status count %q:\n%s", want, out.String())
+			}
+			if strings.Contains(out.String(), "503") {
+				t.Errorf("warmup responses were counted:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestBenchFailures(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+	ctx := batchDock(t, server.URL, []string{"slow"})
+
+	var out strings.Builder
+	err := Bench(ctx, "slow", http.ExecuteOptions{Writer: &out, Timeout: 20 * time.Millisecond}, BenchOptions{Requests: 3, Concurrency: 3})
+	if err == nil || err.Error() != "3 of 3 requests failed" {
+		t.Errorf("Bench() error = %v, want 3 of 3 requests failed", err)
+	}
+	if !strings.Contains(out.String(), "Requests: 3, failed 3") || !strings.Contains(out.String(), "Errors:\n  3  ") {
+		t.Errorf("output does not report the failures:\n%s", out.String())
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 10)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{p: 0, want: 1 * time.Millisecond},
+		{p: 50, want: 5 * time.Millisecond},
+		{p: 90, want: 9 * time.Millisecond},
+		{p: 99, want: 10 * time.Millisecond},
+		{p: 100, want: 10 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%d) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile([]time.Duration{time.Second}, 50); got != time.Second {
+		t.Errorf("percentile() of one sample = %v", got)
+	}
+}
+
+func TestBenchCommandOptions(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"bench"}, wantErr: "Missing name of the request to benchmark"},
+		{args: []string{"bench", "ping", "--requests", "0"}, wantErr: "Invalid --requests: 0"},
+		{args: []string{"bench", "ping", "--concurrency", "many"}, wantErr: "Invalid --concurrency: many"},
+		{args: []string{"bench", "ping", "--warmup", "-1"}, wantErr: "Invalid --warmup: -1"},
+		{args: []string{"bench", "ping", "--timeout", "soon"}, wantErr: "Invalid --timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			err := app.Run(tt.args)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintBenchConnWait(t *testing.T) {
+	samples := []benchSample{
+		{Duration: 30 * time.Millisecond, ConnWait: 20 * time.Millisecond, Status: 200},
+		{Duration: 10 * time.Millisecond, Status: 200},
+		{Duration: 50 * time.Millisecond, ConnWait: 40 * time.Millisecond, Status: 503},
+	}
+
+	var out strings.Builder
+	printBench(&out, samples, time.Second)
+
+	tests := []struct {
+		row  string
+		want []string // min, p50, p90, p99 and max
+	}{
+		{row: "total", want: []string{"10ms", "30ms", "50ms", "50ms", "50ms"}},
+		{row: "conn wait", want: []string{"0s", "20ms", "40ms", "40ms", "40ms"}},
+		{row: "server", want: []string{"10ms", "10ms", "10ms", "10ms", "10ms"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if rest, ok := strings.CutPrefix(line, "  "+tt.row+" "); ok {
+				got = strings.Fields(rest)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s row = %q, want %q\n%s", tt.row, got, tt.want, out.String())
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"rq/dock"
+	"rq/request/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseCaptures reads "name=$.path" pairs separated by commas into a map of
+// variable names to JSON paths.
+func ParseCaptures(spec string) (map[string]string, error) {
+	captures := make(map[string]string)
+	for _, part := range strings.Split(spec, ",") {
+		name, path, ok := strings.Cut(strings.TrimSpace(part), "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || !strings.HasPrefix(path, "$") {
+			return nil, fmt.Errorf("invalid capture %q, expected name=$.path", part)
+		}
+		captures[name] = path
+	}
+	return captures, nil
+}
+
+// captureFile is where captured values are kept between runs: the file
+// given with --capture-file, or .rq/session.json in the dock.
+func captureFile(ctx *dock.RqContext, options http.ExecuteOptions) string {
+	if options.CaptureFile != "" {
+		return options.CaptureFile
+	}
+	return filepath.Join(ctx.Dock, ".rq", "session.json")
+}
+
+// loadCaptures reads the values captured by earlier runs. A missing file
+// means nothing was captured yet.
+func loadCaptures(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture file: %w", err)
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid capture file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// saveCaptures extracts the captures from the response body and merges them
+// into the capture file.
+func saveCaptures(path string, captures map[string]string, response *http.HttpResponse) (map[string]string, error) {
+	var body any
+	if err := json.Unmarshal(response.Body, &body); err != nil {
+		return nil, fmt.Errorf("cannot capture from a body that is not JSON: %w", err)
+	}
+
+	captured := make(map[string]string)
+	for name, expression := range captures {
+		value, err := jsonPath(body, expression)
+		if err != nil {
+			return nil, fmt.Errorf("capture %s: %w", name, err)
+		}
+		captured[name] = value
+	}
+
+	values, err := loadCaptures(path)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = make(map[string]string)
+	}
+	maps.Copy(values, captured)
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode captures: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write capture file: %w", err)
+	}
+	return captured, nil
+}
+
+// jsonPath follows a path made of .key, ["key"] and [index] steps. Strings
+// are returned as they are, any other value as JSON.
+func jsonPath(value any, path string) (string, error) {
+	rest := strings.TrimPrefix(path, "$")
+	for rest != "" {
+		var key string
+		index := -1
+
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return "", fmt.Errorf("unclosed [ in %s", path)
+			}
+			inner := strings.Trim(rest[1:end], `"'`)
+			rest = rest[end+1:]
+			if n, err := strconv.Atoi(inner); err == nil {
+				index = n
+			} else {
+				key = inner
+			}
+		default:
+			return "", fmt.Errorf("invalid path %s", path)
+		}
+
+		if index >= 0 {
+			items, ok := value.([]any)
+			if !ok || index >= len(items) {
+				return "", fmt.Errorf("no element [%d] in %s", index, path)
+			}
+			value = items[index]
+			continue
+		}
+
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("no field %q in %s", key, path)
+		}
+		if value, ok = object[key]; !ok {
+			return "", fmt.Errorf("no field %q in %s", key, path)
+		}
+	}
+
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func capturedNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
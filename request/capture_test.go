@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func TestParseCaptures(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[string]string
+		wantErr string
+	}{
+		{spec: "token=$.access_token", want: map[string]string{"token": "$.access_token"}},
+		{spec: "token = $.access_token, id=$.user.id", want: map[string]string{"token": "$.access_token", "id": "$.user.id"}},
+		{spec: "token", wantErr: `invalid capture "token", expected name=$.path`},
+		{spec: "=$.id", wantErr: `invalid capture "=$.id", expected name=$.path`},
+		{spec: "id=user.id", wantErr: `invalid capture "id=user.id", expected name=$.path`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseCaptures(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseCaptures() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCaptures() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	var body any
+	json.Unmarshal([]byte(`{"access_token":"abc","user":{"id":42,"roles":["admin","dev"],"dotted.key":true},"items":[{"sku":"A1"}]}`), &body)
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: "$.access_token", want: "abc"},
+		{path: "$.user.id", want: "42"},
+		{path: "$.user.roles[1]", want: "dev"},
+		{path: "$.user.roles", want: `["admin","dev"]`},
+		{path: `$.user["dotted.key"]`, want: "true"},
+		{path: "$.items[0].sku", want: "A1"},
+		{path: "$", want: `{"access_token":"abc","items":[{"sku":"A1"}],"user":{"dotted.key":true,"id":42,"roles":["admin","dev"]}}`},
+		{path: "$.missing", wantErr: `no field "missing" in $.missing`},
+		{path: "$.user.roles[5]", wantErr: "no element [5] in $.user.roles[5]"},
+		{path: "$.access_token.length", wantErr: `no field "length" in $.access_token.length`},
+		{path: "$.items[0", wantErr: "unclosed [ in $.items[0"},
+		{path: "$items", wantErr: "invalid path $items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := jsonPath(body, tt.path)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("jsonPath() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("jsonPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureAcrossRuns(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		switch r.URL.Path {
+		case "/login":
+			fmt.Fprint(w, `{"access_token":"fresh","user":{"id":7}}`)
+		case "/me":
+			fmt.Fprintf(w, "auth=%s user=%s", r.Header.Get("Authorization"), r.URL.Query().Get("id"))
+		default:
+			fmt.Fprint(w, "not json")
+		}
+	}))
+	defer server.Close()
+
+	customFile := filepath.Join(t.TempDir(), "ci-session.json")
+
+	tests := []struct {
+		name     string
+		flags    []string
+		file     string
+		wantFile map[string]string
+	}{
+		{name: "default file", file: ".rq/session.json", wantFile: map[string]string{"token": "fresh", "user": "7"}},
+		{name: "--capture-file", flags: []string{"--capture-file", customFile}, file: customFile, wantFile: map[string]string{"token": "fresh", "user": "7"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeDock(t, map[string]string{
+				".env":       "BASE_URL=" + server.URL + "\ntoken=stale\nuser=0\n",
+				"login.http": "POST {{BASE_URL}}/login\n",
+				"me.http":    "GET {{BASE_URL}}/me?id={{user}}\nAuthorization: Bearer {{token}}\n",
+				"text.http":  "GET {{BASE_URL}}/text\n",
+			})
+			t.Chdir(root)
+
+			run := func(argv ...string) (string, error) {
+				app := args.New("rq")
+				Setup(app)
+				var err error
+				out := captureStdout(t, func() {
+					err = app.Run(append(argv, tt.flags...))
+				})
+				return out, err
+			}
+
+			out, err := run("run", "me")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, "auth=Bearer stale user=0") {
+				t.Fatalf("before capturing, output = %s", out)
+			}
+
+			out, err = run("run", "login", "--capture", "token=$.access_token,user=$.user.id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, "Captured: token, user") {
+				t.Errorf("output does not list the captures:\n%s", out)
+			}
+
+			path := tt.file
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(root, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var saved map[string]string
+			if err := json.Unmarshal(data, &saved); err != nil || !reflect.DeepEqual(saved, tt.wantFile) {
+				t.Errorf("%s = %s, want %v", tt.file, data, tt.wantFile)
+			}
+
+			out, err = run("run", "me")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, "auth=Bearer fresh user=7") {
+				t.Errorf("captured values do not win over .env:\n%s", out)
+			}
+
+			if _, err := run("run", "text", "--capture", "token=$.access_token"); err == nil || !strings.Contains(err.Error(), "cannot capture from a body that is not JSON") {
+				t.Errorf("capturing from text error = %v", err)
+			}
+			if _, err := run("run", "login", "--capture", "token"); err == nil || !strings.HasPrefix(err.Error(), "Invalid --capture") {
+				t.Errorf("invalid --capture error = %v", err)
+			}
+		})
+	}
+}
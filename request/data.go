@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"rq/dock"
+	"rq/request/http"
+	"strings"
+)
+
+// RunData runs the request once per row of the data file, exposing the
+// row's columns as variables, and reports every row like RunMany does.
+func RunData(ctx *dock.RqContext, name, dataFile string, options http.ExecuteOptions, parallel int) error {
+	rows, err := loadDataFile(dataFile)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("%s has no rows", dataFile)
+	}
+
+	results := make([]*batchResult, len(rows))
+	for i, row := range rows {
+		results[i] = &batchResult{
+			Name:      name,
+			Label:     fmt.Sprintf("%s [row %d]", name, i+1),
+			Variables: row,
+		}
+	}
+	return runBatch(ctx, results, options, parallel, "rows")
+}
+
+// loadDataFile reads a CSV file (the header row names the variables) or a
+// JSON array of objects.
+func loadDataFile(path string) ([]map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCSVData(content)
+	case ".json":
+		return parseJSONData(content)
+	default:
+		return nil, fmt.Errorf("unsupported data file %s: expected .csv or .json", path)
+	}
+}
+
+func parseCSVData(content []byte) ([]map[string]string, error) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV data: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i, key := range header {
+		header[i] = strings.TrimSpace(key)
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, key := range header {
+			row[key] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJSONData(content []byte) ([]map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var objects []map[string]any
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("invalid JSON data, expected an array of objects: %w", err)
+	}
+
+	rows := make([]map[string]string, 0, len(objects))
+	for _, object := range objects {
+		row := make(map[string]string, len(object))
+		for key, value := range object {
+			switch v := value.(type) {
+			case string:
+				row[key] = v
+			case nil:
+				row[key] = ""
+			case json.Number, bool:
+				row[key] = fmt.Sprint(v)
+			default:
+				encoded, _ := json.Marshal(v)
+				row[key] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
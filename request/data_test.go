@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bytes"
+	nethttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"rq/dock"
+	"rq/request/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoadDataFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []map[string]string
+		wantErr string
+	}{
+		{
+			name:    "csv",
+			file:    "users.csv",
+			content: "name, email\nada,ada@example.com\n\"Lovelace, Ada\",\n",
+			want: []map[string]string{
+				{"name": "ada", "email": "ada@example.com"},
+				{"name": "Lovelace, Ada", "email": ""},
+			},
+		},
+		{name: "csv header only", file: "users.csv", content: "name,email\n", want: []map[string]string{}},
+		{name: "empty csv", file: "users.csv", content: ""},
+		{name: "ragged csv", file: "users.csv", content: "name,email\nada\n", wantErr: "invalid CSV data"},
+		{
+			name:    "json",
+			file:    "users.JSON",
+			content: `[{"name":"ada","age":36,"admin":true,"team":null,"tags":["x"]},{"name":"bob","id":12345678901234567890}]`,
+			want: []map[string]string{
+				{"name": "ada", "age": "36", "admin": "true", "team": "", "tags": `["x"]`},
+				{"name": "bob", "id": "12345678901234567890"},
+			},
+		},
+		{name: "json object", file: "users.json", content: `{"name":"ada"}`, wantErr: "expected an array of objects"},
+		{name: "unsupported", file: "users.yaml", content: "- name: ada\n", wantErr: "expected .csv or .json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeFile(t, path, tt.content)
+
+			got, err := loadDataFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadDataFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadDataFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunData(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		name := r.URL.Query().Get("name")
+		mu.Lock()
+		received = append(received, name+" "+r.URL.Query().Get("email"))
+		mu.Unlock()
+		if name == "taken" {
+			w.WriteHeader(nethttp.StatusConflict)
+			return
+		}
+		w.WriteHeader(nethttp.StatusCreated)
+	}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env":            "BASE_URL=" + server.URL + "\n",
+		"createUser.http": "@assert status == 201\nPOST {{BASE_URL}}/users\n?name={{name}}\n&email={{email}}\n",
+		"users.csv":       "name,email\nada,ada@example.com\ntaken,t@example.com\n",
+		"users.json":      `[{"name":"bob","email":"bob@example.com"}]`,
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file     string
+		want     []string
+		wantErr  string
+		wantRows []string
+	}{
+		{
+			file:     "users.csv",
+			want:     []string{"ada ada@example.com", "taken t@example.com"},
+			wantErr:  "1 of 2 rows failed",
+			wantRows: []string{"createUser [row 1]", "OK", "createUser [row 2]", "FAILED", "Total: 2 rows, 1 failed"},
+		},
+		{
+			file:     "users.json",
+			want:     []string{"bob bob@example.com"},
+			wantRows: []string{"createUser [row 1]", "OK", "Total: 1 rows, 0 failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			received = nil
+			var out bytes.Buffer
+			err := RunData(ctx, "createUser", filepath.Join(root, tt.file), http.ExecuteOptions{Writer: &out}, 1)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("RunData() error = %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("RunData() error = %v, want %q", err, tt.wantErr)
+			}
+
+			slices.Sort(received)
+			if !slices.Equal(received, tt.want) {
+				t.Errorf("server received %q, want %q", received, tt.want)
+			}
+			for _, want := range tt.wantRows {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"rq/directive"
+	"strings"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// includeForm replaces a "@form-file path" line before the request line with
+// a form-encoded body built from the fields of the file, a JSON object or
+// key=value lines. The path is relative to the request file and each value
+// goes through resolve. Content-Type is set unless the request sets it.
+func includeForm(requestPath, content string, resolve func(string) (string, error)) (string, error) {
+	content, directives := directive.Extract(content, "form-file")
+	if len(directives) > 1 {
+		return "", fmt.Errorf("line %d: only one @form-file is allowed", directives[1].Line)
+	}
+
+	lines := strings.Split(content, "\n")
+	_, requestLine := directive.Preamble(lines)
+	if len(directives) == 0 || requestLine == len(lines) {
+		return content, nil
+	}
+
+	formPath, err := directivePath(requestPath, directives[0])
+	if err != nil {
+		return "", fmt.Errorf("line %d: %w", directives[0].Line, err)
+	}
+	fields, err := readFormFile(formPath)
+	if err != nil {
+		return "", err
+	}
+	for key, values := range fields {
+		for i, value := range values {
+			if values[i], err = resolve(value); err != nil {
+				return "", fmt.Errorf("form field %s: %w", key, err)
+			}
+		}
+	}
+
+	headerEnd := len(lines)
+	hasContentType := false
+	for i := requestLine + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			headerEnd = i
+			break
+		}
+		if name, _, ok := strings.Cut(trimmed, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Content-Type") {
+			hasContentType = true
+		}
+	}
+	if headerEnd < len(lines) && strings.TrimSpace(strings.Join(lines[headerEnd:], "\n")) != "" {
+		return "", fmt.Errorf("a request with @form-file cannot have its own body")
+	}
+
+	result := append([]string{}, lines[:headerEnd]...)
+	if !hasContentType {
+		result = append(result, "Content-Type: "+formContentType)
+	}
+	result = append(result, "", fields.Encode())
+	return strings.Join(result, "\n"), nil
+}
+
+// readFormFile reads a JSON object, whose arrays become repeated fields, or
+// key=value lines, where a repeated key adds a value.
+func readFormFile(path string) (url.Values, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read form file: %w", err)
+	}
+
+	fields := url.Values{}
+	text := strings.TrimSpace(string(data))
+
+	if filepath.Ext(path) == ".json" || strings.HasPrefix(text, "{") {
+		var object map[string]any
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, fmt.Errorf("form file %s must hold a JSON object: %w", filepath.Base(path), err)
+		}
+		for key, value := range object {
+			items, ok := value.([]any)
+			if !ok {
+				items = []any{value}
+			}
+			for _, item := range items {
+				text, err := formValue(item)
+				if err != nil {
+					return nil, fmt.Errorf("form field %s: %w", key, err)
+				}
+				fields.Add(key, text)
+			}
+		}
+		return fields, nil
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%s:%d: expected key=value", filepath.Base(path), i+1)
+		}
+		fields.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return fields, nil
+}
+
+func formValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		return "", fmt.Errorf("nested values cannot be form-encoded")
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func TestReadFormFile(t *testing.T) {
+	const want = "active=true&age=36&name=Ada+Lovelace&note=&tag=math&tag=poetry"
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{name: "JSON object", file: "signup.json", content: `{"name":"Ada Lovelace","age":36,"active":true,"note":null,"tag":["math","poetry"]}`},
+		{name: "JSON without extension", file: "signup", content: ` {"tag":["math","poetry"],"name":"Ada Lovelace","age":36,"active":true,"note":""}`},
+		{name: "key=value lines", file: "signup.form", content: "# sign up\nname = Ada Lovelace\nage=36\nactive=true\nnote=\ntag=math\n\ntag=poetry\n"},
+		{name: "nested JSON", file: "bad.json", content: `{"address":{"city":"London"}}`, wantErr: "form field address: nested values cannot be form-encoded"},
+		{name: "JSON array", file: "bad.json", content: `["a"]`, wantErr: "form file bad.json must hold a JSON object"},
+		{name: "line without =", file: "bad.form", content: "name=Ada\nage\n", wantErr: "bad.form:2: expected key=value"},
+		{name: "missing file", wantErr: "failed to read form file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "missing.form")
+			if tt.file != "" {
+				path = filepath.Join(dir, tt.file)
+				writeFile(t, path, tt.content)
+			}
+
+			fields, err := readFormFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("readFormFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fields.Encode(); got != want {
+				t.Errorf("readFormFile() encodes to %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestIncludeForm(t *testing.T) {
+	root := writeDock(t, map[string]string{"users/signup.form": "name={{NAME}}\nplan=pro\n"})
+	requestPath := filepath.Join(root, "users", "signup.http")
+	resolve := func(value string) (string, error) {
+		if value == "{{NAME}}" {
+			return "Ada & co", nil
+		}
+		return value, nil
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "sets the body and Content-Type",
+			content: "@form-file signup.form\nPOST http://api.test/signup\nAccept: */*\n",
+			want:    "\nPOST http://api.test/signup\nAccept: */*\nContent-Type: application/x-www-form-urlencoded\n\nname=Ada+%26+co&plan=pro",
+		},
+		{
+			name:    "keeps the request's Content-Type",
+			content: "@form-file signup.form\nPOST http://api.test/signup\ncontent-type: application/x-www-form-urlencoded; charset=utf-8\n",
+			want:    "\nPOST http://api.test/signup\ncontent-type: application/x-www-form-urlencoded; charset=utf-8\n\nname=Ada+%26+co&plan=pro",
+		},
+		{
+			name:    "without the directive",
+			content: "POST http://api.test/signup\n\nraw=1\n",
+			want:    "POST http://api.test/signup\n\nraw=1\n",
+		},
+		{name: "own body", content: "@form-file signup.form\nPOST http://api.test/signup\n\nraw=1\n", wantErr: "a request with @form-file cannot have its own body"},
+		{name: "two files", content: "@form-file signup.form\n@form-file other.form\nPOST http://api.test/signup\n", wantErr: "line 2: only one @form-file is allowed"},
+		{name: "no path", content: "@form-file\nPOST http://api.test/signup\n", wantErr: "line 1: @form-file expects one path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := includeForm(requestPath, tt.content, resolve)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("includeForm() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("includeForm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFormFile(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if err := r.ParseForm(); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "type=%s name=%s tags=%s", r.Header.Get("Content-Type"), r.PostForm.Get("name"), strings.Join(r.PostForm["tag"], ","))
+	}))
+	defer server.Close()
+
+	const want = "type=application/x-www-form-urlencoded name=Ada Lovelace tags=math,poetry"
+	root := writeDock(t, map[string]string{
+		".env":             "BASE_URL=" + server.URL + "\nFIRST=Ada\n",
+		"forms/json.json":  `{"name":"{{FIRST}} Lovelace","tag":["math","poetry"]}`,
+		"forms/lines.form": "name=${FIRST} Lovelace\ntag=math\ntag=poetry\n",
+		"json.http":        "@form-file forms/json.json\nPOST {{BASE_URL}}/signup\n",
+		"lines.http":       "@form-file forms/lines.form\nPOST {{BASE_URL}}/signup\n",
+	})
+	t.Chdir(root)
+
+	for _, name := range []string{"json", "lines"} {
+		t.Run(name, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = app.Run([]string{"run", name})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q:\n%s", want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"rq/directive"
+	"strings"
+)
+
+// directivePath returns the single path argument of a directive such as
+// @headers, relative to the request file.
+func directivePath(requestPath string, d directive.Directive) (string, error) {
+	if d.Args == "" || strings.ContainsAny(d.Args, " \t") {
+		return "", fmt.Errorf("@%s expects one path", d.Name)
+	}
+	if filepath.IsAbs(d.Args) {
+		return d.Args, nil
+	}
+	return filepath.Join(filepath.Dir(requestPath), d.Args), nil
+}
+
+// includeHeaders replaces the "@headers path" lines that precede the request
+// line with the headers of the named files. Paths are relative to the request
+// file. The request's own headers win over included ones, and a later file
+// wins over an earlier one. Variables are resolved afterwards, together with
+// the rest of the request.
+func includeHeaders(requestPath, content string) (string, error) {
+	content, directives := directive.Extract(content, "headers")
+
+	var included [][2]string
+	for _, d := range directives {
+		path, err := directivePath(requestPath, d)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", d.Line, err)
+		}
+		headers, err := readHeadersFile(path)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", d.Line, err)
+		}
+		included = append(included, headers...)
+	}
+
+	lines := strings.Split(content, "\n")
+	_, requestLine := directive.Preamble(lines)
+	if requestLine == len(lines) || len(included) == 0 {
+		return content, nil
+	}
+
+	own := make(map[string]bool)
+	for _, line := range lines[requestLine+1:] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			break
+		}
+		if name, _, ok := strings.Cut(trimmed, ":"); ok && !strings.HasPrefix(trimmed, "#") {
+			own[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+	}
+
+	merged := make(map[string]string)
+	var order []string
+	for _, header := range included {
+		key := strings.ToLower(header[0])
+		if own[key] {
+			continue
+		}
+		if _, seen := merged[key]; !seen {
+			order = append(order, key)
+		}
+		merged[key] = header[0] + ": " + header[1]
+	}
+
+	extra := make([]string, 0, len(order))
+	for _, key := range order {
+		extra = append(extra, merged[key])
+	}
+
+	result := append([]string{}, lines[:requestLine+1]...)
+	result = append(result, extra...)
+	result = append(result, lines[requestLine+1:]...)
+	return strings.Join(result, "\n"), nil
+}
+
+// readHeadersFile reads "Key: Value" lines, skipping blank lines and
+// comments.
+func readHeadersFile(path string) ([][2]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers file: %w", err)
+	}
+
+	var headers [][2]string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s:%d: invalid header: %s", filepath.Base(path), i+1, line)
+		}
+		headers = append(headers, [2]string{name, strings.TrimSpace(value)})
+	}
+	return headers, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+// Directive lines are left blank, so the results start with one empty line
+// per @headers.
+func TestIncludeHeaders(t *testing.T) {
+	const common = "# shared\nAccept: application/json\nX-Client: rq\n\nAuthorization: Bearer {{TOKEN}}\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "included before own headers",
+			content: "@headers common.headers\nGET http://api.test\nX-Trace: 1\n",
+			want:    "\nGET http://api.test\nAccept: application/json\nX-Client: rq\nAuthorization: Bearer {{TOKEN}}\nX-Trace: 1\n",
+		},
+		{
+			name:    "own header wins",
+			content: "@headers common.headers\nGET http://api.test\nx-client: cli\n",
+			want:    "\nGET http://api.test\nAccept: application/json\nAuthorization: Bearer {{TOKEN}}\nx-client: cli\n",
+		},
+		{
+			name:    "later file wins",
+			content: "@headers common.headers\n@headers ../shared/override.headers\nGET http://api.test\n",
+			want:    "\n\nGET http://api.test\nAccept: text/plain\nX-Client: rq\nAuthorization: Bearer {{TOKEN}}\n",
+		},
+		{
+			name:    "body lines are not headers",
+			content: "@headers common.headers\nPOST http://api.test\n\nAccept: nothing\n",
+			want:    "\nPOST http://api.test\nAccept: application/json\nX-Client: rq\nAuthorization: Bearer {{TOKEN}}\n\nAccept: nothing\n",
+		},
+		{
+			name:    "without the directive",
+			content: "GET http://api.test\nAccept: */*\n",
+			want:    "GET http://api.test\nAccept: */*\n",
+		},
+		{
+			name:    "missing file",
+			content: "@headers missing.headers\nGET http://api.test\n",
+			wantErr: "line 1: failed to read headers file",
+		},
+		{
+			name:    "invalid header",
+			content: "@headers broken.headers\nGET http://api.test\n",
+			wantErr: "line 1: broken.headers:2: invalid header: not a header",
+		},
+		{
+			name:    "several paths",
+			content: "@headers a.headers b.headers\nGET http://api.test\n",
+			wantErr: "line 1: @headers expects one path",
+		},
+	}
+
+	root := writeDock(t, map[string]string{
+		"users/common.headers":    common,
+		"users/broken.headers":    "Accept: */*\nnot a header\n",
+		"shared/override.headers": "Accept: text/plain\n",
+	})
+	requestPath := filepath.Join(root, "users", "list.http")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := includeHeaders(requestPath, tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("includeHeaders() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("includeHeaders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHeadersDirective(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"authorization": r.Header.Get("Authorization"),
+			"client":        r.Header.Get("X-Client"),
+		})
+	}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env":           "TOKEN=s3cr3t\n",
+		"common.headers": "Authorization: Bearer {{TOKEN}}\nX-Client: shared\n",
+		"shared.http":    "@headers common.headers\nGET " + server.URL + "\n",
+		"own.http":       "@headers common.headers\nGET " + server.URL + "\nX-Client: own\n",
+		"missing.http":   "@headers nowhere.headers\nGET " + server.URL + "\n",
+	})
+	t.Chdir(root)
+
+	tests := []struct {
+		request string
+		want    string
+		wantErr string
+	}{
+		{request: "shared", want: `{"authorization":"Bearer s3cr3t","client":"shared"}`},
+		{request: "own", want: `{"authorization":"Bearer s3cr3t","client":"own"}`},
+		{request: "missing", wantErr: "failed to include headers: line 1: failed to read headers file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.request, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = app.Run([]string{"run", tt.request})
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("run error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output = %q, want %s", out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultConnectTimeout      = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultMaxConnsPerHost     = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// ClientOptions configures the clients built by NewClient. Zero values keep
+// the defaults rq has always used.
+type ClientOptions struct {
+	Timeout        time.Duration // Deadline for the whole request, 0 never expires
+	ConnectTimeout time.Duration // Dial and TLS handshake limit, 0 uses 10s
+	KeepAlive      time.Duration // Interval of TCP keep-alive probes, 0 uses 30s
+	NoKeepAlive    bool          // Close connections after each response
+	HTTP2          bool          // Negotiate HTTP/2 over TLS
+
+	MaxIdleConns        int           // Idle connections kept overall, 0 uses 100
+	MaxIdleConnsPerHost int           // Idle connections kept per host, 0 uses 10
+	MaxConnsPerHost     int           // Connections open per host, 0 uses 10
+	IdleConnTimeout     time.Duration // How long an idle connection is kept, 0 uses 90s
+
+	Proxy     *url.URL    // Proxy every request goes through, nil connects directly
+	TLSConfig *tls.Config // TLS settings, nil verifies against the system roots
+
+	Jar           http.CookieJar                                      // Cookies kept between requests, nil disables them
+	CheckRedirect func(next *http.Request, via []*http.Request) error // Redirect policy, nil follows up to 10
+}
+
+// transportKey holds the options that shape a transport and can be compared,
+// so clients with the same settings share a connection pool.
+type transportKey struct {
+	connectTimeout      time.Duration
+	keepAlive           time.Duration
+	noKeepAlive         bool
+	http2               bool
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
+	proxy               string
+}
+
+// transports holds one transport per transportKey, so concurrent runs reuse
+// the same connection pool.
+var transports sync.Map
+
+// NewClient builds an HTTP client from options. Clients without a TLSConfig
+// share their transport with every client of the same settings.
+func NewClient(options ClientOptions) *http.Client {
+	return &http.Client{
+		Timeout:       options.Timeout,
+		Jar:           options.Jar,
+		Transport:     transportFor(options),
+		CheckRedirect: options.CheckRedirect,
+	}
+}
+
+// NewTransport builds a transport from options. The overall deadline is left
+// to the client Timeout.
+func NewTransport(options ClientOptions) *http.Transport {
+	key := options.transportKey()
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(key.http2)
+
+	tlsConfig := options.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: false}
+	}
+
+	var proxy func(*http.Request) (*url.URL, error)
+	if options.Proxy != nil {
+		proxy = http.ProxyURL(options.Proxy)
+	}
+
+	return &http.Transport{
+		Protocols:         protocols,
+		Proxy:             proxy,
+		DisableKeepAlives: key.noKeepAlive,
+		DialContext: (&net.Dialer{
+			Timeout:   key.connectTimeout,
+			KeepAlive: key.keepAlive,
+		}).DialContext,
+		TLSHandshakeTimeout:   key.connectTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConns:          key.maxIdleConns,
+		MaxIdleConnsPerHost:   key.maxIdleConnsPerHost,
+		MaxConnsPerHost:       key.maxConnsPerHost,
+		IdleConnTimeout:       key.idleConnTimeout,
+
+		TLSClientConfig: tlsConfig,
+	}
+}
+
+func transportFor(options ClientOptions) *http.Transport {
+	if options.TLSConfig != nil {
+		return NewTransport(options)
+	}
+	key := options.transportKey()
+	if t, ok := transports.Load(key); ok {
+		return t.(*http.Transport)
+	}
+	t, _ := transports.LoadOrStore(key, NewTransport(options))
+	return t.(*http.Transport)
+}
+
+func (options ClientOptions) transportKey() transportKey {
+	key := transportKey{
+		connectTimeout:      orDefault(options.ConnectTimeout, defaultConnectTimeout),
+		keepAlive:           orDefault(options.KeepAlive, defaultKeepAlive),
+		noKeepAlive:         options.NoKeepAlive,
+		http2:               options.HTTP2,
+		maxIdleConns:        orDefault(options.MaxIdleConns, defaultMaxIdleConns),
+		maxIdleConnsPerHost: orDefault(options.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		maxConnsPerHost:     orDefault(options.MaxConnsPerHost, defaultMaxConnsPerHost),
+		idleConnTimeout:     orDefault(options.IdleConnTimeout, defaultIdleConnTimeout),
+	}
+	if options.Proxy != nil {
+		key.proxy = options.Proxy.String()
+	}
+	return key
+}
+
+func orDefault[T time.Duration | int](value, fallback T) T {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewTransport(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.test:8080")
+
+	tests := []struct {
+		name    string
+		options ClientOptions
+		check   func(t *testing.T, transport *http.Transport)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, transport *http.Transport) {
+				if transport.TLSHandshakeTimeout != defaultConnectTimeout {
+					t.Errorf("TLSHandshakeTimeout = %v", transport.TLSHandshakeTimeout)
+				}
+				if transport.MaxIdleConns != defaultMaxIdleConns || transport.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || transport.MaxConnsPerHost != defaultMaxConnsPerHost {
+					t.Errorf("connection limits = %d, %d, %d", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+				}
+				if transport.IdleConnTimeout != defaultIdleConnTimeout {
+					t.Errorf("IdleConnTimeout = %v", transport.IdleConnTimeout)
+				}
+				if transport.DisableKeepAlives || transport.Proxy != nil {
+					t.Errorf("DisableKeepAlives = %v, Proxy set = %v", transport.DisableKeepAlives, transport.Proxy != nil)
+				}
+				if transport.Protocols.HTTP2() || !transport.Protocols.HTTP1() {
+					t.Errorf("Protocols = %v, want HTTP/1 only", transport.Protocols)
+				}
+				if transport.TLSClientConfig == nil || transport.TLSClientConfig.InsecureSkipVerify {
+					t.Errorf("TLSClientConfig = %+v, want verification on", transport.TLSClientConfig)
+				}
+			},
+		},
+		{
+			name:    "ConnectTimeout",
+			options: ClientOptions{ConnectTimeout: 2 * time.Second},
+			check: func(t *testing.T, transport *http.Transport) {
+				if transport.TLSHandshakeTimeout != 2*time.Second {
+					t.Errorf("TLSHandshakeTimeout = %v, want 2s", transport.TLSHandshakeTimeout)
+				}
+			},
+		},
+		{
+			name:    "NoKeepAlive",
+			options: ClientOptions{NoKeepAlive: true},
+			check: func(t *testing.T, transport *http.Transport) {
+				if !transport.DisableKeepAlives {
+					t.Error("DisableKeepAlives = false")
+				}
+			},
+		},
+		{
+			name:    "HTTP2",
+			options: ClientOptions{HTTP2: true},
+			check: func(t *testing.T, transport *http.Transport) {
+				if !transport.Protocols.HTTP2() || !transport.Protocols.HTTP1() {
+					t.Errorf("Protocols = %v, want HTTP/1 and HTTP/2", transport.Protocols)
+				}
+			},
+		},
+		{
+			name:    "connection limits",
+			options: ClientOptions{MaxIdleConns: 5, MaxIdleConnsPerHost: 2, MaxConnsPerHost: 3, IdleConnTimeout: time.Second},
+			check: func(t *testing.T, transport *http.Transport) {
+				if transport.MaxIdleConns != 5 || transport.MaxIdleConnsPerHost != 2 || transport.MaxConnsPerHost != 3 {
+					t.Errorf("connection limits = %d, %d, %d, want 5, 2, 3", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+				}
+				if transport.IdleConnTimeout != time.Second {
+					t.Errorf("IdleConnTimeout = %v, want 1s", transport.IdleConnTimeout)
+				}
+			},
+		},
+		{
+			name:    "negative values use the defaults",
+			options: ClientOptions{ConnectTimeout: -1, MaxConnsPerHost: -1},
+			check: func(t *testing.T, transport *http.Transport) {
+				if transport.TLSHandshakeTimeout != defaultConnectTimeout || transport.MaxConnsPerHost != defaultMaxConnsPerHost {
+					t.Errorf("TLSHandshakeTimeout = %v, MaxConnsPerHost = %d", transport.TLSHandshakeTimeout, transport.MaxConnsPerHost)
+				}
+			},
+		},
+		{
+			name:    "Proxy",
+			options: ClientOptions{Proxy: proxy},
+			check: func(t *testing.T, transport *http.Transport) {
+				req, _ := http.NewRequest("GET", "http://api.test/users", nil)
+				if got, err := transport.Proxy(req); err != nil || got.String() != proxy.String() {
+					t.Errorf("Proxy() = %v, %v, want %v", got, err, proxy)
+				}
+			},
+		},
+		{
+			name:    "TLSConfig",
+			options: ClientOptions{TLSConfig: &tls.Config{ServerName: "api.test"}},
+			check: func(t *testing.T, transport *http.Transport) {
+				if transport.TLSClientConfig.ServerName != "api.test" {
+					t.Errorf("TLSClientConfig = %+v, want the given config", transport.TLSClientConfig)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, NewTransport(tt.options))
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	jar, _ := cookiejar.New(nil)
+	errStop := errors.New("stop")
+	client := NewClient(ClientOptions{
+		Timeout:       3 * time.Second,
+		Jar:           jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return errStop },
+	})
+
+	if client.Timeout != 3*time.Second {
+		t.Errorf("Timeout = %v, want 3s", client.Timeout)
+	}
+	if client.Jar != jar {
+		t.Error("Jar is not the given jar")
+	}
+	if client.CheckRedirect == nil || client.CheckRedirect(nil, nil) != errStop {
+		t.Error("CheckRedirect is not the given policy")
+	}
+
+	if NewClient(ClientOptions{}).Jar != nil {
+		t.Error("a client without a Jar keeps cookies")
+	}
+}
+
+func TestNewClientSharesTransports(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b ClientOptions
+		same bool
+	}{
+		{name: "same settings", a: ClientOptions{MaxConnsPerHost: 4}, b: ClientOptions{MaxConnsPerHost: 4, Timeout: time.Second}, same: true},
+		{name: "explicit defaults", a: ClientOptions{}, b: ClientOptions{ConnectTimeout: defaultConnectTimeout, MaxIdleConns: defaultMaxIdleConns}, same: true},
+		{name: "different limits", a: ClientOptions{MaxConnsPerHost: 4}, b: ClientOptions{MaxConnsPerHost: 5}},
+		{name: "different keep-alive", a: ClientOptions{}, b: ClientOptions{NoKeepAlive: true}},
+		{name: "TLS config", a: ClientOptions{TLSConfig: &tls.Config{}}, b: ClientOptions{TLSConfig: &tls.Config{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := NewClient(tt.a).Transport, NewClient(tt.b).Transport
+			if same := a == b; same != tt.same {
+				t.Errorf("clients share a transport = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+)
+
+// headerValue looks up a request header regardless of how the request file
+// capitalized it.
+func (req *HttpRequest) headerValue(name string) (string, string) {
+	for key, value := range req.Headers {
+		if strings.EqualFold(key, name) {
+			return key, value
+		}
+	}
+	return "", ""
+}
+
+// compressBody gzips the body when --compress was given or the request file
+// declares "Content-Encoding: gzip" for a body that is still plain. Empty
+// bodies and bodies in another encoding are left alone.
+func (req *HttpRequest) compressBody() error {
+	if req.Body == "" {
+		return nil
+	}
+
+	key, encoding := req.headerValue("Content-Encoding")
+	switch {
+	case strings.EqualFold(encoding, "gzip"):
+		if strings.HasPrefix(req.Body, "\x1f\x8b") {
+			return nil
+		}
+	case encoding != "" || !req.Compress:
+		return nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(req.Body)); err != nil {
+		return fmt.Errorf("failed to compress body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress body: %w", err)
+	}
+
+	if key != "" {
+		delete(req.Headers, key)
+	}
+	if key, _ := req.headerValue("Content-Length"); key != "" {
+		delete(req.Headers, key)
+	}
+	req.Headers["Content-Encoding"] = "gzip"
+	req.Body = buf.String()
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipString(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRunCompress(t *testing.T) {
+	body := strings.Repeat(`{"name":"rq","tags":["http","cli"]}`, 50)
+	compressed := gzipString(t, body)
+
+	tests := []struct {
+		name         string
+		content      string
+		compress     bool
+		wantEncoding string
+		wantBody     string // As decoded by the server, which only understands gzip
+	}{
+		{name: "--compress", content: "POST {{URL}}\n\n" + body, compress: true, wantEncoding: "gzip", wantBody: body},
+		{name: "Content-Encoding header", content: "POST {{URL}}\nContent-Encoding: gzip\n\n" + body, wantEncoding: "gzip", wantBody: body},
+		{name: "stale Content-Length is dropped", content: "POST {{URL}}\nContent-Length: 3\n\n" + body, compress: true, wantEncoding: "gzip", wantBody: body},
+		{name: "already gzipped", content: "POST {{URL}}\nContent-Encoding: gzip\n\n" + compressed, compress: true, wantEncoding: "gzip", wantBody: body},
+		{name: "other encoding", content: "POST {{URL}}\nContent-Encoding: br\n\n" + body, compress: true, wantEncoding: "br", wantBody: body},
+		{name: "empty body", content: "POST {{URL}}\n", compress: true},
+		{name: "without --compress", content: "POST {{URL}}\n\n" + body, wantBody: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEncoding, gotBody string
+			var gotLength int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotEncoding = r.Header.Get("Content-Encoding")
+				gotLength = r.ContentLength
+				var reader io.Reader = r.Body
+				if gotEncoding == "gzip" {
+					gz, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("request body is not gzip: %v", err)
+						return
+					}
+					reader = gz
+				}
+				data, err := io.ReadAll(reader)
+				if err != nil {
+					t.Errorf("failed to read request body: %v", err)
+				}
+				gotBody = string(data)
+			}))
+			defer server.Close()
+
+			content := strings.ReplaceAll(tt.content, "{{URL}}", server.URL)
+			if _, err := Run(content, ExecuteOptions{Writer: io.Discard, Compress: tt.compress}); err != nil {
+				t.Fatal(err)
+			}
+
+			if gotEncoding != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", gotEncoding, tt.wantEncoding)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("server decoded %d bytes, want %d", len(gotBody), len(tt.wantBody))
+			}
+			if tt.wantEncoding == "gzip" && gotLength != int64(len(compressed)) {
+				t.Errorf("Content-Length = %d, want %d", gotLength, len(compressed))
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"rq/request/network"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxCSVColumnWidth = 40
+
+func init() {
+	RegisterFormatter("text/csv", formatCSV)
+}
+
+// formatCSV renders a CSV body as an aligned table with the header row in
+// bold, cutting cells wider than maxCSVColumnWidth.
+func formatCSV(body string) (string, error) {
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("empty CSV")
+	}
+
+	var widths []int
+	for r, record := range records {
+		for i, field := range record {
+			field = truncateCell(strings.ReplaceAll(field, "\n", " "))
+			records[r][i] = field
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(field))
+		}
+	}
+
+	var sb strings.Builder
+	for r, record := range records {
+		cells := make([]string, len(record))
+		for i, field := range record {
+			cells[i] = field + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(field))
+		}
+		line := strings.TrimRight(strings.Join(cells, "  "), " ")
+		if r == 0 {
+			line = network.Colorize(network.ColorBold, line)
+		}
+		sb.WriteString(line)
+		if r < len(records)-1 {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func truncateCell(field string) string {
+	if utf8.RuneCountInString(field) <= maxCSVColumnWidth {
+		return field
+	}
+	runes := []rune(field)
+	return string(runes[:maxCSVColumnWidth-1]) + "…"
+}
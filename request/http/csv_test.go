@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatCSV(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "quoted fields and embedded commas",
+			body: "name,city,note\nada,\"London, UK\",\"said \"\"hi\"\"\"\nbob,Rome,\n",
+			want: "name  city        note\nada   London, UK  said \"hi\"\nbob   Rome",
+		},
+		{
+			name: "wide column is cut with an ellipsis",
+			body: "id,text\n1," + strings.Repeat("x", 50) + "\n",
+			want: "id  text\n1   " + strings.Repeat("x", maxCSVColumnWidth-1) + "…",
+		},
+		{name: "newline inside a field", body: "a,b\n\"one\ntwo\",3\n", want: "a        b\none two  3"},
+		{name: "ragged rows", body: "a,b\nc\n", want: "a  b\nc"},
+		{name: "unterminated quote", body: "a,\"b\n", wantErr: "invalid CSV"},
+		{name: "empty", body: "", wantErr: "empty CSV"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatCSV(tt.body)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("formatCSV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("formatCSV() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFprintBodyCSV(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	resp := &HttpResponse{
+		Body:    []byte("id,name\n1,ada\n"),
+		Headers: map[string][]string{"Content-Type": {"text/csv; charset=utf-8"}},
+	}
+
+	var out strings.Builder
+	resp.fprintBody(&out, PrintOptions{})
+	if want := "id  name\n1   ada\n"; out.String() != want {
+		t.Errorf("fprintBody() = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"rq/request/network"
+	"strings"
+	"unicode/utf8"
+)
+
+// Formatter renders a response body for the terminal. Returning an error
+// makes Print fall back to the raw body.
+type Formatter func(body string) (string, error)
+
+var formatters = make(map[string]Formatter)
+
+func init() {
+	RegisterFormatter("application/json", formatJSON)
+	RegisterFormatter("text/json", formatJSON)
+	RegisterFormatter("application/xml", formatXML)
+	RegisterFormatter("text/xml", formatXML)
+	for _, contentType := range []string{"application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines", "application/json-seq"} {
+		RegisterFormatter(contentType, formatNDJSON)
+	}
+}
+
+// RegisterFormatter makes Print use formatter for responses whose media
+// type is contentType (parameters such as charset are ignored).
+func RegisterFormatter(contentType string, formatter Formatter) error {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return fmt.Errorf("content type cannot be empty")
+	}
+	if _, exists := formatters[contentType]; exists {
+		return fmt.Errorf("a formatter for %s is already registered", contentType)
+	}
+
+	formatters[contentType] = formatter
+	return nil
+}
+
+func ContainsFormatter(contentType string) bool {
+	_, ok := FormatterFor(contentType)
+	return ok
+}
+
+// FormatterFor looks up the formatter for a Content-Type header value. A
+// structured syntax suffix falls back to its base type, so
+// application/problem+json uses the application/json formatter.
+func FormatterFor(contentType string) (Formatter, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	if formatter, ok := formatters[mediaType]; ok {
+		return formatter, true
+	}
+
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		formatter, ok := formatters["application/"+mediaType[i+1:]]
+		return formatter, ok
+	}
+
+	return nil, false
+}
+
+var errNotJSON = errors.New("body is not JSON")
+
+func formatJSON(body string) (string, error) {
+	formatted := network.FormatJSON(body)
+	if formatted == "" {
+		return "", errNotJSON
+	}
+	return formatted, nil
+}
+
+func formatNDJSON(body string) (string, error) {
+	values, ok := network.SplitJSONValues(body)
+	if !ok {
+		return "", errNotJSON
+	}
+
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = network.FormatJSON(value)
+	}
+	return strings.Join(formatted, "\n"), nil
+}
+
+var binaryTypes = []string{"image/", "audio/", "video/", "font/", "application/octet-stream", "application/pdf", "application/zip", "application/gzip", "application/x-gzip", "application/wasm"}
+
+// binarySniffLength is how much of the body is scanned for NUL bytes.
+const binarySniffLength = 8192
+
+// isBinary reports whether a body should not be printed to a terminal,
+// judging by its content type first and by its bytes otherwise.
+func isBinary(contentType string, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		for _, prefix := range binaryTypes {
+			if strings.HasPrefix(mediaType, prefix) && !strings.HasSuffix(mediaType, "+xml") {
+				return true
+			}
+		}
+	}
+
+	sniff := body[:min(len(body), binarySniffLength)]
+	return bytes.IndexByte(sniff, 0) >= 0 || !utf8.Valid(body)
+}
+
+// looksLikeNDJSON reports bodies holding several JSON objects, which
+// servers often send without an NDJSON content type.
+func looksLikeNDJSON(body string) bool {
+	if !strings.HasPrefix(strings.TrimSpace(body), "{") {
+		return false
+	}
+	values, ok := network.SplitJSONValues(body)
+	return ok && len(values) > 1
+}
+
+func formatXML(body string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	var out bytes.Buffer
+	encoder := xml.NewEncoder(&out)
+	encoder.Indent("", "  ")
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if data, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		if inst, ok := token.(xml.ProcInst); ok && inst.Target == "xml" {
+			fmt.Fprintf(&out, "<?xml %s?>\n", inst.Inst)
+			continue
+		}
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
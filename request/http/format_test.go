@@ -0,0 +1,221 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"rq/request/network"
+	"strings"
+	"testing"
+)
+
+const ndjsonBody = `{"id":1,"level":"info"}
+{"id":2,"tags":["a","b"]}
+
+{"id":3}
+`
+
+const ndjsonFormatted = `{
+  "id": 1,
+  "level": "info"
+}
+{
+  "id": 2,
+  "tags": [
+    "a",
+    "b"
+  ]
+}
+{
+  "id": 3
+}
+`
+
+func TestFormatterFor(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"application/problem+json", true},
+		{"application/x-ndjson", true},
+		{"application/jsonl", true},
+		{"text/xml", true},
+		{"application/atom+xml", true},
+		{"text/plain", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := FormatterFor(tt.contentType); ok != tt.want {
+			t.Errorf("FormatterFor(%q) = %v, want %v", tt.contentType, ok, tt.want)
+		}
+	}
+}
+
+func TestLooksLikeNDJSON(t *testing.T) {
+	tests := map[string]bool{
+		ndjsonBody:               true,
+		`{"a":1}{"b":2}`:         true,
+		`{"a":1}`:                false,
+		`[{"a":1},{"b":2}]`:      false,
+		"plain\ntext":            false,
+		`{"a":1}` + "\nnot json": false,
+	}
+
+	for body, want := range tests {
+		if got := looksLikeNDJSON(body); got != want {
+			t.Errorf("looksLikeNDJSON(%q) = %v, want %v", body, got, want)
+		}
+	}
+}
+
+func TestFprintBodyNDJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		ndjson      bool
+		want        string
+	}{
+		{name: "ndjson content type", contentType: "application/x-ndjson", body: ndjsonBody, want: ndjsonFormatted},
+		{name: "detected under a json content type", contentType: "application/json", body: ndjsonBody, want: ndjsonFormatted},
+		{name: "detected without a content type", body: ndjsonBody, want: ndjsonFormatted},
+		{name: "--ndjson for arrays per line", contentType: "text/plain", body: "[1]\n[2]\n", ndjson: true, want: "[\n  1\n]\n[\n  2\n]\n"},
+		{name: "invalid ndjson falls back to the raw body", contentType: "application/x-ndjson", body: "{\"a\":1}\noops\n", want: "{\"a\":1}\noops\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &HttpResponse{Body: []byte(tt.body), Headers: map[string][]string{}}
+			if tt.contentType != "" {
+				resp.Headers["Content-Type"] = []string{tt.contentType}
+			}
+
+			var out strings.Builder
+			resp.fprintBody(&out, PrintOptions{NDJSON: tt.ndjson})
+			if out.String() != tt.want {
+				t.Errorf("fprintBody() =\n%q\nwant\n%q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	upper := func(body string) (string, error) {
+		if body == "fail" {
+			return "", errors.New("cannot format")
+		}
+		return strings.ToUpper(body), nil
+	}
+	if err := RegisterFormatter(" Application/RQ-Test ", upper); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { delete(formatters, "application/rq-test") })
+
+	errorTests := []struct {
+		contentType string
+		wantErr     string
+	}{
+		{"application/rq-test", "a formatter for application/rq-test is already registered"},
+		{"APPLICATION/JSON", "a formatter for application/json is already registered"},
+		{"  ", "content type cannot be empty"},
+	}
+	for _, tt := range errorTests {
+		if err := RegisterFormatter(tt.contentType, upper); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("RegisterFormatter(%q) error = %v, want %q", tt.contentType, err, tt.wantErr)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "registered type", contentType: "application/rq-test", body: "hello", want: "HELLO\n"},
+		{name: "parameters are ignored", contentType: "application/rq-test; charset=utf-8", body: "hello", want: "HELLO\n"},
+		{name: "structured suffix", contentType: "application/vnd.acme+rq-test", body: "hello", want: "HELLO\n"},
+		{name: "formatter error falls back to the raw body", contentType: "application/rq-test", body: "fail", want: "fail\n"},
+		{name: "unregistered type is raw", contentType: "application/rq-other", body: "hello", want: "hello\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &HttpResponse{Body: []byte(tt.body), Headers: map[string][]string{"Content-Type": {tt.contentType}}}
+			var out strings.Builder
+			resp.fprintBody(&out, PrintOptions{})
+			if out.String() != tt.want {
+				t.Errorf("fprintBody() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// pngFixture encodes a small image, the kind of body that must not reach
+// the terminal.
+func pngFixture(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestIsBinary(t *testing.T) {
+	pixel := pngFixture(t)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        bool
+	}{
+		{name: "png", contentType: "image/png", body: pixel, want: true},
+		{name: "png without a content type", body: pixel, want: true},
+		{name: "png labelled as text", contentType: "text/plain", body: pixel, want: true},
+		{name: "svg is text", contentType: "image/svg+xml", body: []byte("<svg/>"), want: false},
+		{name: "octet stream", contentType: "application/octet-stream", body: []byte("abc"), want: true},
+		{name: "pdf", contentType: "application/pdf", body: []byte("%PDF-1.7"), want: true},
+		{name: "nul byte", body: []byte("abc\x00def"), want: true},
+		{name: "invalid utf-8", body: []byte{0xff, 0xfe, 'a'}, want: true},
+		{name: "json", contentType: "application/json", body: []byte(`{"a":"é"}`), want: false},
+		{name: "plain text", body: []byte("hello"), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isBinary(tt.contentType, tt.body); got != tt.want {
+			t.Errorf("%s: isBinary() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFprintBodyBinary(t *testing.T) {
+	pixel := pngFixture(t)
+	resp := &HttpResponse{Body: pixel, Headers: map[string][]string{"Content-Type": {"image/png"}}}
+
+	var out strings.Builder
+	resp.fprintBody(&out, PrintOptions{})
+	want := "  (binary body, image/png, " + network.FormatBytes(int64(len(pixel))) + ", use --output to save it or --force-print to show it)\n"
+	if out.String() != want {
+		t.Errorf("fprintBody() = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	resp.fprintBody(&out, PrintOptions{Force: true})
+	if out.String() != string(pixel)+"\n" {
+		t.Errorf("fprintBody() with Force should print the raw bytes, got %q", out.String())
+	}
+
+	resp.Headers = map[string][]string{}
+	out.Reset()
+	resp.fprintBody(&out, PrintOptions{})
+	if !strings.Contains(out.String(), "(binary body, unknown type, ") {
+		t.Errorf("fprintBody() without a content type = %q", out.String())
+	}
+}
@@ -4,16 +4,24 @@
 package http
 
 import (
-	"crypto/tls"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"os"
+	"regexp"
+	"rq/request/network"
+	"rq/version"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type HttpRequest struct {
@@ -23,21 +31,116 @@ type HttpRequest struct {
 	Body    string
 	Version string
 	Timeout time.Duration
+
+	ConnectTimeout time.Duration // Dial and TLS handshake limit, 0 uses the default
+
+	NoContentLength bool // Send the body chunked instead of with a Content-Length
+
+	Jar http.CookieJar // Cookies kept between requests, nil disables them
+
+	AllowUnresolved bool // Send URLs that still contain placeholders
+
+	NoKeepAlive bool // Close the connection after the response
+
+	Compress bool // Gzip the body unless it already has a Content-Encoding
+
+	MaxResponseSize int64 // Body bytes kept in memory, the rest is dropped; 0 keeps everything
+
+	RedirectAuth string    // When Authorization survives a redirect, one of RedirectAuthModes
+	Log          io.Writer // Receives details like redirects when set
+
+	Retry RetryPolicy // From the @retry directive, used by ExecuteWithRetry
+
+	Context context.Context // Cancels the request when done, nil never cancels
+
+	Client *http.Client // Sends the request instead of a client built from ClientOptions
 }
 
 type HttpResponse struct {
+	Method     string
+	URL        string
 	StatusCode int
 	Status     string
+	Proto      string
 	Headers    map[string][]string
-	Body       string
+	Body       []byte // Raw body bytes, written unchanged when saving
 	Duration   time.Duration
 	Size       int64
+	Truncated  bool          // The body was cut at the request's MaxResponseSize
+	ConnWait   time.Duration // Part of Duration spent getting a connection from the pool
+	Started    time.Time
+	Timeline   []TraceEvent // httptrace events of the exchange, for --trace-file
+
+	SentHeaders int64 // Request line and headers
+	SentBody    int64 // Body bytes read by the transport, chunked bodies included
+	Received    int64 // Status line, headers and body
 }
 type ExecuteOptions struct {
-	Environment    string
-	OutputFile     string
-	OutputBodyOnly bool
-	Timeout        time.Duration
+	Name            string // Name of the request, used to auto-name output files
+	Environment     string
+	OutputFile      string
+	OutputBodyOnly  bool
+	HeadersFile     string            // When set, headers are saved here and OutputFile receives only the body
+	Timeout         time.Duration     // Deadline for the whole request, body included
+	ConnectTimeout  time.Duration     // Deadline for establishing the connection
+	EnvFiles        []string          // Config files merged over the dock config, in order
+	Variables       map[string]string // Extra variables that take precedence over the config
+	BaseURL         string            // Prepended to request URLs that start with "/"
+	HTTPVersion     string            // Used when the request line names no version
+	NoContentLength bool              // Send the body chunked, without a Content-Length header
+	Brief           bool              // Print a one-line summary instead of the full response
+	NDJSON          bool              // Print the body as newline-delimited JSON
+	MaxBodyPrint    int               // Bytes of body printed to the terminal, 0 uses DefaultMaxBodyPrint, negative prints all
+	Writer          io.Writer         // Destination of the printed output (defaults to stdout)
+	Jar             http.CookieJar    // Shared cookie jar, nil sends no stored cookies
+	AllowUnresolved bool              // Send URLs with leftover placeholders instead of failing
+	JUnitFile       string            // JUnit XML report written once the run finishes
+	ForcePrint      bool              // Print binary bodies to the terminal as they are
+	NoKeepAlive     bool              // Send Connection: close and do not reuse connections
+	AllowGetBody    bool              // Do not warn about bodies on GET and HEAD requests
+	Tee             bool              // Print the response even when it is saved to OutputFile
+	Verbose         bool              // Print details of the exchange, such as redirects
+	RedirectAuth    string            // When Authorization survives a redirect, see RedirectAuthModes
+	Retry           RetryPolicy       // Overrides the settings of the request's @retry directive
+	NoRetry         bool              // Send the request once, ignoring @retry
+	Context         context.Context   // Cancels the request, for example on Ctrl-C
+	Compress        bool              // Gzip the request body
+	DecodeJWT       bool              // Print the claims of JWTs found in the response
+	Captures        map[string]string // Variable names and the JSON paths saved from the response
+	CaptureFile     string            // Where captured variables are kept, defaults to .rq/session.json
+	MaxResponseSize int64             // Body bytes kept in memory, 0 uses DefaultMaxResponseSize, negative keeps all
+	OutputAppend    bool              // Append to OutputFile, after a separator, instead of overwriting it
+	TraceFile       string            // Where the httptrace timeline is written as JSON
+	ReportUnused    bool              // List the config keys the request never referenced
+	Record          bool              // Save the response as a snapshot for rq mock and docs examples
+}
+
+func (options ExecuteOptions) Output() io.Writer {
+	if options.Writer == nil {
+		return os.Stdout
+	}
+	return options.Writer
+}
+
+func (options ExecuteOptions) maxResponseSize() int64 {
+	switch {
+	case options.MaxResponseSize < 0:
+		return 0
+	case options.MaxResponseSize == 0:
+		return DefaultMaxResponseSize
+	}
+	return options.MaxResponseSize
+}
+
+func (options ExecuteOptions) maxBodyPrint() int {
+	switch {
+	case options.MaxBodyPrint == 0:
+		return DefaultMaxBodyPrint
+	case options.MaxBodyPrint < 0:
+		return 0
+	default:
+		return options.MaxBodyPrint
+	}
 }
 
 func HttpTemplate(name string) string {
@@ -49,7 +152,26 @@ Authorization: Bearer {{API_TOKEN}}
 `, name)
 }
 
+// StripComments drops the comment lines (doc blocks, tags) that precede the
+// request line, since the request line must come first.
+func StripComments(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "///") {
+			return strings.Join(lines[i:], "\n")
+		}
+	}
+	return ""
+}
+
 func Parse(content string) (*HttpRequest, error) {
+	content, retry, err := extractDirectives(content)
+	if err != nil {
+		return nil, err
+	}
+
+	content = StripComments(content)
 	if strings.TrimSpace(content) == "" {
 		return nil, fmt.Errorf("empty request content")
 	}
@@ -70,14 +192,19 @@ func Parse(content string) (*HttpRequest, error) {
 		Method:  strings.ToUpper(parts[0]),
 		URL:     parts[1],
 		Headers: make(map[string]string),
-		Version: "HTTP/1.1",
 		Timeout: 30 * time.Second,
+		Retry:   retry,
 	}
 
 	if len(parts) >= 3 {
 		req.Version = parts[2]
 	}
 
+	// Query lines ("?key=value" or "&key=value") between the request line
+	// and the body are encoded and appended to the URL, in order, so repeated
+	// keys become multi-value parameters.
+	var query []string
+
 	i := 1
 	for i < len(lines) {
 		line := strings.TrimSpace(lines[i])
@@ -92,6 +219,16 @@ func Parse(content string) (*HttpRequest, error) {
 			continue
 		}
 
+		if strings.HasPrefix(line, "?") || strings.HasPrefix(line, "&") {
+			key, value, _ := strings.Cut(line[1:], "=")
+			if strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("empty query parameter name at line %d", i+1)
+			}
+			query = append(query, url.QueryEscape(strings.TrimSpace(key))+"="+url.QueryEscape(strings.TrimSpace(value)))
+			i++
+			continue
+		}
+
 		colonIndex := strings.Index(line, ":")
 		if colonIndex == -1 {
 			return nil, fmt.Errorf("invalid header format at line %d: %s", i+1, line)
@@ -108,10 +245,22 @@ func Parse(content string) (*HttpRequest, error) {
 		i++
 	}
 
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(req.URL, "?") {
+			separator = "&"
+		}
+		req.URL += separator + strings.Join(query, "&")
+	}
+
 	if i < len(lines) {
 		bodyLines := lines[i:]
 		req.Body = strings.Join(bodyLines, "\n")
-		req.Body = strings.TrimSpace(req.Body)
+		req.Body = strings.TrimSuffix(strings.TrimSuffix(req.Body, "\n"), "\r")
+
+		if strings.TrimSpace(req.Body) == "" {
+			req.Body = ""
+		}
 	}
 
 	return req, nil
@@ -131,35 +280,71 @@ func (req *HttpRequest) Execute() (*HttpResponse, error) {
 
 	client := req.createHTTPClient()
 
+	var sentBody *countingBody
+	if httpReq.Body != nil {
+		sentBody = &countingBody{ReadCloser: httpReq.Body}
+		httpReq.Body = sentBody
+	}
+	sentHeaders := requestHeaderSize(httpReq)
+
+	trace := newTracer(start)
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), trace.clientTrace()))
+
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, req.formatNetworkError(err)
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	var reader io.Reader = resp.Body
+	if req.MaxResponseSize > 0 {
+		reader = io.LimitReader(resp.Body, req.MaxResponseSize+1)
+	}
+
+	bodyBytes, err := io.ReadAll(reader)
 	if err != nil {
+		if req.context().Err() != nil {
+			return nil, network.ErrCanceled
+		}
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	duration := time.Since(start)
+	trace.record("body_done", strconv.Itoa(len(bodyBytes))+" bytes")
 
 	response := &HttpResponse{
+		Method:     req.Method,
+		URL:        req.URL,
 		StatusCode: resp.StatusCode,
 		Status:     resp.Status,
+		Proto:      resp.Proto,
 		Headers:    resp.Header,
-		Body:       string(bodyBytes),
+		Body:       bodyBytes,
 		Duration:   duration,
 		Size:       int64(len(bodyBytes)),
+
+		ConnWait:    trace.connWait,
+		Started:     start,
+		Timeline:    trace.events,
+		SentHeaders: sentHeaders,
+		Received:    responseHeaderSize(resp) + int64(len(bodyBytes)),
+	}
+	if sentBody != nil {
+		response.SentBody = sentBody.n
+	}
+	if req.MaxResponseSize > 0 && int64(len(bodyBytes)) > req.MaxResponseSize {
+		response.Body = bodyBytes[:req.MaxResponseSize]
+		response.Size = req.MaxResponseSize
+		response.Truncated = true
 	}
 
 	return response, nil
 }
 
-func setDefaultVariables(config map[string]string) {
+func SetDefaultVariables(config map[string]string) {
 	defaults := map[string]string{
 		"HTTP_VERSION": "HTTP/1.1",
-		"USER_AGENT":   "rq/1.0.0",
+		"USER_AGENT":   version.UserAgent(),
 		"ACCEPT":       "application/json",
 	}
 
@@ -170,7 +355,32 @@ func setDefaultVariables(config map[string]string) {
 	}
 }
 
+// ResolveURL joins a relative request URL ("/users") onto the base URL.
+func ResolveURL(rawURL, baseURL string) string {
+	if baseURL == "" || !strings.HasPrefix(rawURL, "/") {
+		return rawURL
+	}
+	return strings.TrimRight(baseURL, "/") + rawURL
+}
+
+// placeholderPattern matches template leftovers ({{name}}, {name}) and
+// path parameters (/:name) that were never substituted.
+var placeholderPattern = regexp.MustCompile(`\{\{.*?\}\}|\{[A-Za-z_][A-Za-z0-9_.-]*\}|/:[A-Za-z_][A-Za-z0-9_]*`)
+
+// UnresolvedPlaceholders lists the placeholders left in a URL.
+func UnresolvedPlaceholders(rawURL string) []string {
+	var placeholders []string
+	for _, match := range placeholderPattern.FindAllString(rawURL, -1) {
+		placeholders = append(placeholders, strings.TrimPrefix(match, "/"))
+	}
+	return placeholders
+}
+
 func (req *HttpRequest) prepareURL() error {
+	if placeholders := UnresolvedPlaceholders(req.URL); len(placeholders) > 0 && !req.AllowUnresolved {
+		return fmt.Errorf("unresolved placeholders in URL: %s (use --allow-unresolved to send it anyway)", strings.Join(placeholders, ", "))
+	}
+
 	parsedURL, err := url.Parse(req.URL)
 	if err != nil {
 		return fmt.Errorf("invalid URL: %w", err)
@@ -198,7 +408,7 @@ func (req *HttpRequest) createHTTPRequest() (*http.Request, error) {
 		bodyReader = strings.NewReader(req.Body)
 	}
 
-	httpReq, err := http.NewRequest(req.Method, req.URL, bodyReader)
+	httpReq, err := http.NewRequestWithContext(req.context(), req.Method, req.URL, bodyReader)
 	if err != nil {
 		return nil, err
 	}
@@ -207,96 +417,262 @@ func (req *HttpRequest) createHTTPRequest() (*http.Request, error) {
 		httpReq.Header.Set(key, value)
 	}
 
-	if req.Body != "" && httpReq.Header.Get("Content-Length") == "" {
+	// net/http takes the framing from ContentLength and TransferEncoding
+	// rather than from the headers, so chunked mode is set on the request.
+	if req.NoContentLength || strings.Contains(strings.ToLower(httpReq.Header.Get("Transfer-Encoding")), "chunked") {
+		httpReq.Header.Del("Content-Length")
+		httpReq.Header.Del("Transfer-Encoding")
+		if req.Body != "" {
+			httpReq.ContentLength = -1
+			httpReq.TransferEncoding = []string{"chunked"}
+		}
+	} else if req.Body != "" && httpReq.Header.Get("Content-Length") == "" {
 		httpReq.Header.Set("Content-Length", strconv.Itoa(len(req.Body)))
 	}
 
 	if httpReq.Header.Get("User-Agent") == "" {
-		httpReq.Header.Set("User-Agent", "rq/1.0.0")
+		httpReq.Header.Set("User-Agent", version.UserAgent())
+	}
+
+	// An explicit Connection header from the request file wins over the flag.
+	if req.NoKeepAlive && httpReq.Header.Get("Connection") == "" {
+		httpReq.Close = true
 	}
 
 	return httpReq, nil
 }
 
+// wantsHTTP2 reports whether the request asked for HTTP/2. HTTP/2 is only
+// negotiated over TLS, anything else is sent as HTTP/1.1.
+func (req *HttpRequest) wantsHTTP2() bool {
+	switch strings.ToUpper(strings.TrimSpace(req.Version)) {
+	case "HTTP/2", "HTTP/2.0", "H2":
+		return true
+	}
+	return false
+}
+
+func (req *HttpRequest) connectTimeout() time.Duration {
+	if req.ConnectTimeout > 0 {
+		return req.ConnectTimeout
+	}
+	return defaultConnectTimeout
+}
+
 func (req *HttpRequest) createHTTPClient() *http.Client {
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   10 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ResponseHeaderTimeout: 10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-		MaxIdleConns:          100,
-		MaxIdleConnsPerHost:   10,
-		MaxConnsPerHost:       10,
-		IdleConnTimeout:       90 * time.Second,
-
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: false,
-		},
-	}
-
-	return &http.Client{
-		Timeout:   req.Timeout,
-		Transport: transport,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
+	if req.Client != nil {
+		return req.Client
 	}
+	return NewClient(req.ClientOptions())
 }
 
-func (req *HttpRequest) formatNetworkError(err error) error {
-	if netErr, ok := err.(net.Error); ok {
-		if netErr.Timeout() {
-			return fmt.Errorf("request timeout after %v", req.Timeout)
-		}
+// ClientOptions gathers the settings of the request that shape its client.
+func (req *HttpRequest) ClientOptions() ClientOptions {
+	return ClientOptions{
+		Timeout:        req.Timeout,
+		ConnectTimeout: req.ConnectTimeout,
+		NoKeepAlive:    req.NoKeepAlive,
+		HTTP2:          req.wantsHTTP2(),
+		Jar:            req.Jar,
+		CheckRedirect:  req.checkRedirect,
+	}
+}
+
+// Values of HttpRequest.RedirectAuth.
+const (
+	RedirectAuthCrossHost = "cross-host" // Drop Authorization when the host changes (the net/http default)
+	RedirectAuthAlways    = "always"     // Drop Authorization on every redirect
+	RedirectAuthNever     = "never"      // Keep Authorization wherever the redirect goes
+)
+
+var RedirectAuthModes = []string{RedirectAuthCrossHost, RedirectAuthAlways, RedirectAuthNever}
+
+func (req *HttpRequest) checkRedirect(next *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("too many redirects")
 	}
 
-	if strings.Contains(err.Error(), "connection refused") {
-		return fmt.Errorf("connection refused - server may be down or unreachable")
+	previous := via[len(via)-1]
+	switch req.RedirectAuth {
+	case RedirectAuthAlways:
+		next.Header.Del("Authorization")
+	case RedirectAuthNever:
+		if auth := via[0].Header.Get("Authorization"); auth != "" {
+			next.Header.Set("Authorization", auth)
+		}
 	}
 
-	if strings.Contains(err.Error(), "no such host") {
-		return fmt.Errorf("host not found - check the URL")
+	if req.Log != nil {
+		kept := "method kept"
+		switch {
+		case next.Method != previous.Method:
+			kept = "changed to " + next.Method + ", body dropped"
+		case previous.Body != nil && previous.Body != http.NoBody:
+			kept = "method and body kept"
+		}
+		status := 0
+		if next.Response != nil {
+			status = next.Response.StatusCode
+		}
+		if via[0].Header.Get("Authorization") != "" {
+			if next.Header.Get("Authorization") != "" {
+				kept += ", Authorization kept"
+			} else {
+				kept += ", Authorization dropped"
+			}
+		}
+		fmt.Fprintf(req.Log, "Redirect %d: %s %s -> %s %s (%s)\n", status, previous.Method, previous.URL, next.Method, next.URL, kept)
 	}
+	return nil
+}
 
-	if strings.Contains(err.Error(), "certificate") {
-		return fmt.Errorf("SSL/TLS certificate error: %w", err)
+func (req *HttpRequest) context() context.Context {
+	if req.Context == nil {
+		return context.Background()
 	}
+	return req.Context
+}
 
-	return fmt.Errorf("network error: %w", err)
+func (req *HttpRequest) formatNetworkError(err error) error {
+	if req.context().Err() != nil {
+		return network.ErrCanceled
+	}
+	// The TLS handshake shares the connect timeout, but net/http reports it
+	// with an unexported error type.
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout() || strings.Contains(err.Error(), "TLS handshake timeout") {
+		return fmt.Errorf("%w after %v", network.ErrConnectTimeout, req.connectTimeout())
+	}
+	return network.FormatError(err, req.Timeout)
 }
 
+// DefaultMaxResponseSize is how much of a body is kept in memory unless
+// --max-response-size says otherwise. Responses saved with --output are read
+// into memory too, so the limit applies to them as well.
+const DefaultMaxResponseSize = 256 << 20
+
+// DefaultMaxBodyPrint is how much of a body is printed to the terminal
+// unless --max-body-print says otherwise.
+const DefaultMaxBodyPrint = 1 << 20
+
 func (resp *HttpResponse) Print() {
+	resp.Fprint(os.Stdout)
+}
+
+func (resp *HttpResponse) Fprint(w io.Writer) {
+	resp.FprintWith(w, PrintOptions{MaxBody: DefaultMaxBodyPrint})
+}
+
+type PrintOptions struct {
+	MaxBody int  // Cut the body after this many bytes, 0 prints it whole
+	NDJSON  bool // Format the body as newline-delimited JSON whatever its content type
+	Force   bool // Print binary bodies instead of a summary
+	JWT     bool // Decode the JWTs found in headers and body
+}
+
+// FprintWith prints the response, cutting long bodies and noting how much
+// was left out.
+func (resp *HttpResponse) FprintWith(w io.Writer, options PrintOptions) {
 	statusColor := getStatusColor(resp.StatusCode)
-	fmt.Printf("Status: %s%s%s\n", statusColor, resp.Status, "\033[0m")
+	fmt.Fprintf(w, "Status: %s\n", network.Colorize(statusColor, resp.Status))
+	if resp.Proto != "" {
+		fmt.Fprintf(w, "Protocol: %s\n", resp.Proto)
+	}
 
-	fmt.Printf("Duration: %v\n", resp.Duration)
-	fmt.Printf("Size: %s\n", formatBytes(resp.Size))
+	fmt.Fprintf(w, "Duration: %v\n", resp.Duration)
+	fmt.Fprintf(w, "Size: %s\n", network.FormatBytes(resp.Size))
+	if resp.SentHeaders > 0 {
+		fmt.Fprintf(w, "Sent: %s (body %s)\n", network.FormatBytes(resp.SentHeaders+resp.SentBody), network.FormatBytes(resp.SentBody))
+		fmt.Fprintf(w, "Received: %s\n", network.FormatBytes(resp.Received))
+	}
 
-	fmt.Println("\nHeaders:")
+	fmt.Fprintln(w, "\nHeaders:")
 	for key, values := range resp.Headers {
 		for _, value := range values {
-			fmt.Printf("  %s: %s\n", key, value)
+			fmt.Fprintf(w, "  %s: %s\n", key, value)
 		}
 	}
 
-	fmt.Println("\nBody:")
-	if resp.Body == "" {
-		fmt.Println("  (empty)")
-	} else {
-		if contentType := resp.Headers["Content-Type"]; len(contentType) > 0 && strings.Contains(contentType[0], "json") {
-			if formatted := formatJSON(resp.Body); formatted != "" {
-				fmt.Println(formatted)
-				return
-			}
+	fmt.Fprintln(w, "\nBody:")
+	resp.fprintBody(w, options)
+
+	if options.JWT {
+		resp.fprintJWTs(w)
+	}
+}
+
+func (resp *HttpResponse) fprintBody(w io.Writer, options PrintOptions) {
+	limit := options.MaxBody
+	if len(resp.Body) == 0 {
+		fmt.Fprintln(w, "  (empty)")
+		return
+	}
+
+	contentType := strings.Join(resp.Header("Content-Type"), ";")
+	if !options.Force && isBinary(contentType, resp.Body) {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType == "" {
+			mediaType = "unknown type"
+		}
+		fmt.Fprintf(w, "  (binary body, %s, %s, use --output to save it or --force-print to show it)\n", mediaType, network.FormatBytes(int64(len(resp.Body))))
+		return
+	}
+
+	body := resp.BodyString()
+	if limit > 0 && len(body) > limit {
+		for limit > 0 && !utf8.RuneStart(body[limit]) {
+			limit--
+		}
+		fmt.Fprintln(w, body[:limit])
+		fmt.Fprintf(w, "... (%d bytes omitted, use --output to save the full body)\n", len(body)-limit)
+		return
+	}
+
+	formatter, ok := FormatterFor(contentType)
+	if options.NDJSON || looksLikeNDJSON(body) {
+		formatter, ok = formatNDJSON, true
+	}
+
+	if ok {
+		if formatted, err := formatter(body); err == nil {
+			fmt.Fprintln(w, formatted)
+			return
+		}
+	}
+	fmt.Fprintln(w, body)
+}
+
+// FprintBrief prints the response as a single line:
+// "200 OK  142ms  1.2 KB  GET /users".
+func (resp *HttpResponse) FprintBrief(w io.Writer) {
+	target := resp.URL
+	if parsed, err := url.Parse(resp.URL); err == nil && parsed.Path != "" {
+		target = parsed.RequestURI()
+	}
+
+	fmt.Fprintf(w, "%s  %v  %s  %s %s\n",
+		network.Colorize(getStatusColor(resp.StatusCode), resp.Status),
+		resp.Duration.Round(time.Millisecond),
+		network.FormatBytes(resp.Size),
+		resp.Method, target)
+}
+
+func (resp *HttpResponse) BodyString() string {
+	return string(resp.Body)
+}
+
+// Header returns the values of a response header, whatever the case of name
+// or of the stored key.
+func (resp *HttpResponse) Header(name string) []string {
+	if values, ok := resp.Headers[http.CanonicalHeaderKey(name)]; ok {
+		return values
+	}
+	for key, values := range resp.Headers {
+		if strings.EqualFold(key, name) {
+			return values
 		}
-		fmt.Println(resp.Body)
 	}
+	return nil
 }
 
 func (resp *HttpResponse) SaveToFile(filename string) error {
@@ -304,12 +680,32 @@ func (resp *HttpResponse) SaveToFile(filename string) error {
 	return os.WriteFile(filename, []byte(content), 0644)
 }
 
+func (resp *HttpResponse) SaveHeadersToFile(filename string) error {
+	keys := make([]string, 0, len(resp.Headers))
+	for key := range resp.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		for _, value := range resp.Headers[key] {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", key, value))
+		}
+	}
+
+	return os.WriteFile(filename, []byte(sb.String()), 0644)
+}
+
 func (resp *HttpResponse) formatForFile() string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("Status: %s\n", resp.Status))
+	sb.WriteString(fmt.Sprintf("Protocol: %s\n", resp.Proto))
 	sb.WriteString(fmt.Sprintf("Duration: %v\n", resp.Duration))
-	sb.WriteString(fmt.Sprintf("Size: %s\n", formatBytes(resp.Size)))
+	sb.WriteString(fmt.Sprintf("Size: %s\n", network.FormatBytes(resp.Size)))
+	sb.WriteString(fmt.Sprintf("Sent: %d bytes (body %d bytes)\n", resp.SentHeaders+resp.SentBody, resp.SentBody))
+	sb.WriteString(fmt.Sprintf("Received: %d bytes\n", resp.Received))
 	sb.WriteString("\nHeaders:\n")
 
 	for key, values := range resp.Headers {
@@ -319,7 +715,7 @@ func (resp *HttpResponse) formatForFile() string {
 	}
 
 	sb.WriteString("\nBody:\n")
-	sb.WriteString(resp.Body)
+	sb.Write(resp.Body)
 
 	return sb.String()
 }
@@ -327,144 +723,167 @@ func (resp *HttpResponse) formatForFile() string {
 func getStatusColor(statusCode int) string {
 	switch {
 	case statusCode >= 200 && statusCode < 300:
-		return "\033[32m"
+		return network.ColorGreen
 	case statusCode >= 300 && statusCode < 400:
-		return "\033[33m"
+		return network.ColorYellow
 	case statusCode >= 400 && statusCode < 500:
-		return "\033[31m"
+		return network.ColorRed
 	case statusCode >= 500:
-		return "\033[35m"
+		return network.ColorMagenta
 	default:
-		return "\033[0m"
+		return ""
 	}
 }
 
-func formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
+// Prepare parses a resolved request and applies the options that shape how
+// it is sent. The returned request is ready for Execute.
+func Prepare(content string, options ExecuteOptions) (*HttpRequest, error) {
+	httpReq, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTTP request: %w", err)
 	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+
+	if err := validate(httpReq); err != nil {
+		return nil, fmt.Errorf("invalid HTTP request: %w", err)
 	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
 
-func formatJSON(jsonStr string) string {
-	var formatted strings.Builder
-	var indent int
-	var inString bool
-	var escaped bool
+	if options.Timeout > 0 {
+		httpReq.Timeout = options.Timeout
+	}
+	if httpReq.Version == "" {
+		httpReq.Version = options.HTTPVersion
+	}
+	httpReq.ConnectTimeout = options.ConnectTimeout
+	httpReq.NoContentLength = options.NoContentLength
+	httpReq.Jar = options.Jar
+	httpReq.AllowUnresolved = options.AllowUnresolved
+	httpReq.NoKeepAlive = options.NoKeepAlive
+	httpReq.RedirectAuth = options.RedirectAuth
+	httpReq.Context = options.Context
+	httpReq.Retry = httpReq.Retry.Merge(options.Retry)
+	if options.NoRetry {
+		httpReq.Retry = RetryPolicy{}
+	}
+	if options.Verbose {
+		httpReq.Log = options.Output()
+	}
 
-	for _, char := range jsonStr {
-		if escaped {
-			formatted.WriteRune(char)
-			escaped = false
-			continue
-		}
+	httpReq.Compress = options.Compress
+	httpReq.MaxResponseSize = options.maxResponseSize()
+	if err := httpReq.compressBody(); err != nil {
+		return nil, err
+	}
 
-		if char == '\\' && inString {
-			formatted.WriteRune(char)
-			escaped = true
-			continue
-		}
+	httpReq.URL = ResolveURL(httpReq.URL, options.BaseURL)
+	return httpReq, nil
+}
 
-		if char == '"' {
-			inString = !inString
-			formatted.WriteRune(char)
-			continue
-		}
+// Run sends a resolved request and prints or saves the response as the
+// options ask.
+func Run(content string, options ExecuteOptions) (*HttpResponse, error) {
+	httpReq, err := Prepare(content, options)
+	if err != nil {
+		return nil, err
+	}
 
-		if inString {
-			formatted.WriteRune(char)
-			continue
-		}
+	out := options.Output()
+
+	if !options.Brief {
+		fmt.Fprintf(out, "Executing %s %s", httpReq.Method, httpReq.URL)
 
-		switch char {
-		case '{', '[':
-			formatted.WriteRune(char)
-			indent++
-			formatted.WriteRune('\n')
-			writeIndent(&formatted, indent)
-
-		case '}', ']':
-			formatted.WriteRune('\n')
-			indent--
-			writeIndent(&formatted, indent)
-			formatted.WriteRune(char)
-
-		case ',':
-			formatted.WriteRune(char)
-			formatted.WriteRune('\n')
-			writeIndent(&formatted, indent)
-
-		case ':':
-			formatted.WriteRune(char)
-			formatted.WriteRune(' ')
-
-		case ' ', '\t', '\n', '\r':
-			// Skip whitespace outside strings
-
-		default:
-			formatted.WriteRune(char)
+		if options.Environment != "" {
+			fmt.Fprintf(out, " (env: %s)", options.Environment)
 		}
+		fmt.Fprintln(out)
 	}
 
-	return formatted.String()
-}
-
-func writeIndent(sb *strings.Builder, level int) {
-	for i := 0; i < level*2; i++ {
-		sb.WriteRune(' ')
+	if warning := httpReq.bodyWarning(); warning != "" && !options.AllowGetBody {
+		fmt.Fprintf(out, "Warning: %s (use --allow-get-body to silence this)\n", warning)
 	}
-}
 
-func Run(content string, options ExecuteOptions) error {
-	httpReq, err := Parse(content)
+	response, err := httpReq.ExecuteWithRetry(out)
 	if err != nil {
-		return fmt.Errorf("failed to parse HTTP request: %w", err)
+		return nil, fmt.Errorf("request execution failed: %w", err)
 	}
 
-	if err := validate(httpReq); err != nil {
-		return fmt.Errorf("invalid HTTP request: %w", err)
+	if err := Present(response, options); err != nil {
+		return nil, err
 	}
+	return response, nil
+}
 
-	if options.Timeout > 0 {
-		httpReq.Timeout = options.Timeout
+// Present prints a response and saves the files the options ask for, the
+// way Run does after sending the request.
+func Present(response *HttpResponse, options ExecuteOptions) error {
+	out := options.Output()
+
+	if response.Truncated {
+		fmt.Fprintf(out, "%s\n", network.Colorize(network.ColorBoldYellow, fmt.Sprintf("Warning: response truncated at %s (use --max-response-size to raise the limit)", network.FormatBytes(response.Size))))
 	}
 
-	fmt.Printf("Executing %s %s\n", httpReq.Method, httpReq.URL)
+	if options.OutputFile != "" {
+		outputFile, err := options.outputPath(response)
+		if err != nil {
+			return fmt.Errorf("failed to save output: %w", err)
+		}
+
+		data := response.Body
+		if !options.OutputBodyOnly && options.HeadersFile == "" {
+			data = []byte(response.formatForFile())
+		}
 
-	if options.Environment != "" {
-		fmt.Printf(" (env: %s)", options.Environment)
-	}
-	fmt.Println()
+		if options.Context != nil && options.Context.Err() != nil {
+			return network.ErrCanceled
+		}
+		if err := writeOutput(outputFile, data, options.OutputAppend); err != nil {
+			return fmt.Errorf("failed to save output: %w", err)
+		}
 
-	response, err := httpReq.Execute()
-	if err != nil {
-		return fmt.Errorf("request execution failed: %w", err)
+		fmt.Fprintf(out, "Response saved to: %s\n", outputFile)
 	}
 
-	if options.OutputFile != "" {
-		if options.OutputBodyOnly {
-			err = os.WriteFile(options.OutputFile, []byte(response.Body), 0644)
+	if options.OutputFile == "" || options.Tee {
+		if options.Brief {
+			response.FprintBrief(out)
 		} else {
-			err = response.SaveToFile(options.OutputFile)
+			response.FprintWith(out, PrintOptions{MaxBody: options.maxBodyPrint(), NDJSON: options.NDJSON, Force: options.ForcePrint, JWT: options.DecodeJWT})
 		}
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to save output: %w", err)
+	if options.TraceFile != "" {
+		if err := response.SaveTrace(options.TraceFile); err != nil {
+			return err
 		}
+		fmt.Fprintf(out, "Trace saved to: %s\n", options.TraceFile)
+	}
 
-		fmt.Printf("Response saved to: %s\n", options.OutputFile)
-	} else {
-		response.Print()
+	if options.HeadersFile != "" {
+		if err := response.SaveHeadersToFile(options.HeadersFile); err != nil {
+			return fmt.Errorf("failed to save headers: %w", err)
+		}
+		fmt.Fprintf(out, "Headers saved to: %s\n", options.HeadersFile)
 	}
 	return nil
 }
 
+var validMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true,
+	"HEAD": true, "OPTIONS": true, "PATCH": true, "TRACE": true,
+}
+
+func IsValidMethod(method string) bool {
+	return validMethods[strings.ToUpper(method)]
+}
+
+// bodyWarning describes a body sent with a method that servers usually
+// ignore or reject it on. The request is still sent.
+func (req *HttpRequest) bodyWarning() string {
+	if req.Body == "" || (req.Method != "GET" && req.Method != "HEAD") {
+		return ""
+	}
+	return fmt.Sprintf("%s request has a body, most servers ignore it", req.Method)
+}
+
 func validate(req *HttpRequest) error {
 	if req.Method == "" {
 		return fmt.Errorf("HTTP method is required")
@@ -474,12 +893,7 @@ func validate(req *HttpRequest) error {
 		return fmt.Errorf("URL is required")
 	}
 
-	validMethods := map[string]bool{
-		"GET": true, "POST": true, "PUT": true, "DELETE": true,
-		"HEAD": true, "OPTIONS": true, "PATCH": true, "TRACE": true,
-	}
-
-	if !validMethods[strings.ToUpper(req.Method)] {
+	if !IsValidMethod(req.Method) {
 		return fmt.Errorf("invalid HTTP method: %s", req.Method)
 	}
 
@@ -0,0 +1,1020 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"rq/request/network"
+	"rq/version"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantMethod  string
+		wantURL     string
+		wantVersion string
+		wantHeaders map[string]string
+		wantBody    string
+	}{
+		{
+			name:        "request line only",
+			content:     "get http://localhost/users",
+			wantMethod:  "GET",
+			wantURL:     "http://localhost/users",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "comments, version and headers",
+			content:     "/// List users\n# @tag users\nGET http://localhost/users HTTP/2\nAccept: application/json\n# skipped\nX-Trace : abc:def\n",
+			wantMethod:  "GET",
+			wantURL:     "http://localhost/users",
+			wantVersion: "HTTP/2",
+			wantHeaders: map[string]string{"Accept": "application/json", "X-Trace": "abc:def"},
+		},
+		{
+			name:        "query lines",
+			content:     "GET http://localhost/search?page=1\n?q=a b\n&tag=x\n&tag=y\n",
+			wantMethod:  "GET",
+			wantURL:     "http://localhost/search?page=1&q=a+b&tag=x&tag=y",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "query lines are encoded and mixed with headers",
+			content:     "GET http://localhost/search\nAccept: */*\n?filter=a&b=c/d\n& flag\n?name=Zoë+co\n",
+			wantMethod:  "GET",
+			wantURL:     "http://localhost/search?filter=a%26b%3Dc%2Fd&flag=&name=Zo%C3%AB%2Bco",
+			wantHeaders: map[string]string{"Accept": "*/*"},
+		},
+		{
+			name:        "query lines stop at the body",
+			content:     "POST http://localhost/items\n?dry_run=true\n\n?not=query\n",
+			wantMethod:  "POST",
+			wantURL:     "http://localhost/items?dry_run=true",
+			wantHeaders: map[string]string{},
+			wantBody:    "?not=query",
+		},
+		{
+			name:        "body with internal blank lines",
+			content:     "POST http://localhost/upload\nContent-Type: multipart/form-data; boundary=X\n\n--X\nContent-Disposition: form-data; name=\"a\"\n\none\n\n\ntwo\n--X--\n",
+			wantMethod:  "POST",
+			wantURL:     "http://localhost/upload",
+			wantHeaders: map[string]string{"Content-Type": "multipart/form-data; boundary=X"},
+			wantBody:    "--X\nContent-Disposition: form-data; name=\"a\"\n\none\n\n\ntwo\n--X--",
+		},
+		{
+			name:        "body keeps indentation and only one trailing newline is dropped",
+			content:     "POST http://localhost\n\n  {\n    \"a\": 1\n  }\n\n",
+			wantMethod:  "POST",
+			wantURL:     "http://localhost",
+			wantHeaders: map[string]string{},
+			wantBody:    "  {\n    \"a\": 1\n  }\n",
+		},
+		{
+			name:        "blank body",
+			content:     "POST http://localhost\n\n  \n\n",
+			wantMethod:  "POST",
+			wantURL:     "http://localhost",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "crlf line endings",
+			content:     "POST http://localhost\r\nAccept: text/plain\r\n\r\nline one\r\n\r\nline two\r\n",
+			wantMethod:  "POST",
+			wantURL:     "http://localhost",
+			wantHeaders: map[string]string{"Accept": "text/plain"},
+			wantBody:    "line one\r\n\r\nline two",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := Parse(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Method != tt.wantMethod || req.URL != tt.wantURL || req.Version != tt.wantVersion {
+				t.Errorf("Parse() = %s %s %s, want %s %s %s", req.Method, req.URL, req.Version, tt.wantMethod, tt.wantURL, tt.wantVersion)
+			}
+			if !reflect.DeepEqual(req.Headers, tt.wantHeaders) {
+				t.Errorf("Parse() headers = %v, want %v", req.Headers, tt.wantHeaders)
+			}
+			if req.Body != tt.wantBody {
+				t.Errorf("Parse() body = %q, want %q", req.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "empty", content: "", wantErr: "empty request content"},
+		{name: "comments only", content: "# nothing\n/// here\n", wantErr: "empty request content"},
+		{name: "no url", content: "GET\n", wantErr: "invalid request line format"},
+		{name: "bad header", content: "GET http://localhost\nnot a header\n", wantErr: "invalid header format at line 2"},
+		{name: "empty header name", content: "GET http://localhost\n: value\n", wantErr: "empty header name at line 2"},
+		{name: "empty query name", content: "GET http://localhost\n?=1\n", wantErr: "empty query parameter name at line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteUserAgent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "default", content: "GET {{URL}}\n", want: version.UserAgent()},
+		{name: "explicit header wins", content: "GET {{URL}}\nUser-Agent: custom/1\n", want: "custom/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.UserAgent()
+			}))
+			defer server.Close()
+
+			req, err := Parse(strings.ReplaceAll(tt.content, "{{URL}}", server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := req.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteTimeouts(t *testing.T) {
+	// Accepts connections and never answers, so TLS handshakes stall.
+	stalled, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalled.Close()
+	go func() {
+		for {
+			conn, err := stalled.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(io.Discard, conn)
+		}
+	}()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		timeout        time.Duration
+		connectTimeout time.Duration
+		want           error
+		wantMessage    string
+	}{
+		{
+			name:           "slow to connect",
+			url:            "https://" + stalled.Addr().String(),
+			timeout:        5 * time.Second,
+			connectTimeout: 200 * time.Millisecond,
+			want:           network.ErrConnectTimeout,
+			wantMessage:    "connect timeout after 200ms",
+		},
+		{
+			name:           "slow to respond",
+			url:            slow.URL,
+			timeout:        200 * time.Millisecond,
+			connectTimeout: 5 * time.Second,
+			want:           network.ErrTimeout,
+			wantMessage:    "request timeout after 200ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := Parse("GET " + tt.url + "\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Timeout = tt.timeout
+			req.ConnectTimeout = tt.connectTimeout
+
+			start := time.Now()
+			_, err = req.Execute()
+			if !errors.Is(err, tt.want) || !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Fatalf("Execute() error = %v, want %q", err, tt.wantMessage)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Execute() took %v, want it to stop at the %s", elapsed, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestFprintBodyTruncation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		limit int
+		want  string
+	}{
+		{name: "under the limit", body: "hello", limit: 10, want: "hello\n"},
+		{name: "at the limit", body: "hello", limit: 5, want: "hello\n"},
+		{name: "over the limit", body: "hello world", limit: 5, want: "hello\n... (6 bytes omitted, use --output to save the full body)\n"},
+		{name: "no limit", body: strings.Repeat("a", 100), limit: 0, want: strings.Repeat("a", 100) + "\n"},
+		{name: "cut on a rune boundary", body: "aé€b", limit: 4, want: "aé\n... (4 bytes omitted, use --output to save the full body)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &HttpResponse{Body: []byte(tt.body), Headers: map[string][]string{"Content-Type": {"text/plain"}}}
+			var out strings.Builder
+			resp.fprintBody(&out, PrintOptions{MaxBody: tt.limit})
+			if out.String() != tt.want {
+				t.Errorf("fprintBody() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxBodyPrint(t *testing.T) {
+	tests := []struct {
+		value int
+		want  int
+	}{
+		{value: 0, want: DefaultMaxBodyPrint},
+		{value: -1, want: 0},
+		{value: 512, want: 512},
+	}
+	for _, tt := range tests {
+		if got := (ExecuteOptions{MaxBodyPrint: tt.value}).maxBodyPrint(); got != tt.want {
+			t.Errorf("maxBodyPrint() with %d = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPresentTruncatesOnlyThePrintedBody(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	resp := &HttpResponse{Status: "200 OK", Body: []byte(body), Headers: map[string][]string{"Content-Type": {"text/plain"}}}
+	path := filepath.Join(t.TempDir(), "body.txt")
+
+	var out strings.Builder
+	options := ExecuteOptions{OutputFile: path, OutputBodyOnly: true, Tee: true, MaxBodyPrint: 10, Writer: &out}
+	if err := Present(resp, options); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "xxxxxxxxxx\n... (90 bytes omitted") {
+		t.Errorf("printed body is not truncated:\n%s", out.String())
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved) != body {
+		t.Errorf("saved %d bytes, want the full %d", len(saved), len(body))
+	}
+}
+
+func TestFprintBrief(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	tests := []struct {
+		name string
+		resp HttpResponse
+		want string
+	}{
+		{
+			name: "path only",
+			resp: HttpResponse{Status: "200 OK", StatusCode: 200, Duration: 142*time.Millisecond + 400*time.Microsecond, Size: 1229, Method: "GET", URL: "https://api.test/users"},
+			want: "200 OK  142ms  1.2 KB  GET /users\n",
+		},
+		{
+			name: "query is kept",
+			resp: HttpResponse{Status: "404 Not Found", StatusCode: 404, Duration: 3 * time.Millisecond, Size: 12, Method: "DELETE", URL: "https://api.test/users/7?force=1"},
+			want: "404 Not Found  3ms  12 B  DELETE /users/7?force=1\n",
+		},
+		{
+			name: "no path keeps the url",
+			resp: HttpResponse{Status: "204 No Content", StatusCode: 204, Duration: 2 * time.Second, Method: "POST", URL: "https://api.test"},
+			want: "204 No Content  2s  0 B  POST https://api.test\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			tt.resp.FprintBrief(&out)
+			if out.String() != tt.want {
+				t.Errorf("FprintBrief() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBrief(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":1}`)
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	if _, err := Run("GET "+server.URL+"/users\n", ExecuteOptions{Writer: &out, Brief: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "200 OK  ") || !strings.HasSuffix(lines[0], "  8 B  GET /users") {
+		t.Errorf("Run() with Brief printed %q", out.String())
+	}
+}
+
+func TestExecuteContentLength(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		noContentLength bool
+		wantLength      int64
+		wantChunked     bool
+	}{
+		{name: "set for a body", content: "POST {{URL}}\n\nhello", wantLength: 5},
+		{name: "chunked header", content: "POST {{URL}}\nTransfer-Encoding: chunked\n\nhello", wantLength: -1, wantChunked: true},
+		{name: "chunked header wins over content-length", content: "POST {{URL}}\nTransfer-Encoding: chunked\nContent-Length: 5\n\nhello", wantLength: -1, wantChunked: true},
+		{name: "--no-content-length", content: "POST {{URL}}\n\nhello", noContentLength: true, wantLength: -1, wantChunked: true},
+		{name: "--no-content-length without a body", content: "GET {{URL}}\n", noContentLength: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotLength int64
+			var gotEncoding []string
+			var gotHeader, gotBody string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotLength, gotEncoding = r.ContentLength, r.TransferEncoding
+				gotHeader = r.Header.Get("Content-Length")
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+			}))
+			defer server.Close()
+
+			req, err := Parse(strings.ReplaceAll(tt.content, "{{URL}}", server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.NoContentLength = tt.noContentLength
+			if _, err := req.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			if gotLength != tt.wantLength {
+				t.Errorf("ContentLength = %d, want %d", gotLength, tt.wantLength)
+			}
+			if chunked := reflect.DeepEqual(gotEncoding, []string{"chunked"}); chunked != tt.wantChunked {
+				t.Errorf("TransferEncoding = %v, want chunked %v", gotEncoding, tt.wantChunked)
+			}
+			if tt.wantChunked && gotHeader != "" {
+				t.Errorf("Content-Length header = %q in chunked mode", gotHeader)
+			}
+			if want := req.Body; gotBody != want {
+				t.Errorf("body = %q, want %q", gotBody, want)
+			}
+		})
+	}
+}
+
+func TestHTTPVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	tests := []struct {
+		name   string
+		line   string
+		config string
+		want   string
+		wantH2 bool
+	}{
+		{name: "default", line: "GET {{URL}}", want: "HTTP/1.1"},
+		{name: "config http/2", line: "GET {{URL}}", config: "HTTP/2", want: "HTTP/2.0", wantH2: true},
+		{name: "config http/1.1", line: "GET {{URL}}", config: "HTTP/1.1", want: "HTTP/1.1"},
+		{name: "request line http/2", line: "GET {{URL}} HTTP/2", want: "HTTP/2.0", wantH2: true},
+		{name: "request line wins over config", line: "GET {{URL}} HTTP/1.1", config: "HTTP/2", want: "HTTP/1.1"},
+		{name: "h2 alias", line: "GET {{URL}} h2", want: "HTTP/2.0", wantH2: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := Prepare(strings.ReplaceAll(tt.line, "{{URL}}", server.URL)+"\n", ExecuteOptions{HTTPVersion: tt.config})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			options := req.ClientOptions()
+			if options.HTTP2 != tt.wantH2 {
+				t.Errorf("ClientOptions().HTTP2 = %v, want %v", options.HTTP2, tt.wantH2)
+			}
+			options.TLSConfig = &tls.Config{RootCAs: roots}
+			req.Client = NewClient(options)
+
+			resp, err := req.Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Proto != tt.want || string(resp.Body) != tt.want {
+				t.Errorf("negotiated %s (server saw %s), want %s", resp.Proto, resp.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnresolvedPlaceholders(t *testing.T) {
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://api.test:8080/users/7?page=2", nil},
+		{"https://api.test/users/{{userId}}", []string{"{{userId}}"}},
+		{"https://api.test/users/{{ uuid() }}/posts", []string{"{{ uuid() }}"}},
+		{"https://api.test/users/{id}/posts/{post.id}", []string{"{id}", "{post.id}"}},
+		{"https://api.test/users/:id/posts/:postId", []string{":id", ":postId"}},
+		{"https://api.test/search?q={\"a\":1}", nil},
+		{"{{BASE_URL}}/users/:id", []string{"{{BASE_URL}}", ":id"}},
+	}
+
+	for _, tt := range tests {
+		if got := UnresolvedPlaceholders(tt.url); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("UnresolvedPlaceholders(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareURLUnresolved(t *testing.T) {
+	tests := []struct {
+		name            string
+		url             string
+		allowUnresolved bool
+		wantErr         string
+	}{
+		{name: "leftover template", url: "https://api.test/users/{{userId}}", wantErr: "unresolved placeholders in URL: {{userId}} (use --allow-unresolved to send it anyway)"},
+		{name: "path placeholder", url: "https://api.test/users/:id/posts/{postId}", wantErr: "unresolved placeholders in URL: :id, {postId} (use --allow-unresolved to send it anyway)"},
+		{name: "allowed", url: "https://api.test/users/:id", allowUnresolved: true},
+		{name: "resolved", url: "https://api.test/users/7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &HttpRequest{Method: "GET", URL: tt.url, AllowUnresolved: tt.allowUnresolved}
+			err := req.prepareURL()
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("prepareURL() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResponseHeader(t *testing.T) {
+	resp := &HttpResponse{Headers: map[string][]string{
+		"Content-Type": {"application/json"},
+		"X-Request-Id": {"abc"},
+		"x-raw-key":    {"1", "2"},
+	}}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Content-Type", []string{"application/json"}},
+		{"content-type", []string{"application/json"}},
+		{"CONTENT-TYPE", []string{"application/json"}},
+		{"x-request-id", []string{"abc"}},
+		{"X-Raw-Key", []string{"1", "2"}},
+		{"x-raw-key", []string{"1", "2"}},
+		{"Accept", nil},
+	}
+
+	for _, tt := range tests {
+		if got := resp.Header(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Header(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFprintNoColor(t *testing.T) {
+	// /dev/null is a character device, so colors would otherwise be on.
+	stdout, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer stdout.Close()
+	defer func(original *os.File) { os.Stdout = original }(os.Stdout)
+	os.Stdout = stdout
+	t.Setenv("TERM", "xterm")
+
+	resp := &HttpResponse{
+		Status: "404 Not Found", StatusCode: 404, Proto: "HTTP/1.1", Method: "GET", URL: "https://api.test/users",
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    []byte(`{"error":"missing"}`),
+	}
+
+	tests := []struct {
+		name     string
+		noColor  string
+		wantANSI bool
+	}{
+		{name: "colored", wantANSI: true},
+		{name: "NO_COLOR", noColor: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+
+			var out strings.Builder
+			resp.Fprint(&out)
+			resp.FprintBrief(&out)
+			if hasANSI := strings.Contains(out.String(), "\x1b["); hasANSI != tt.wantANSI {
+				t.Errorf("output has ANSI codes = %v, want %v:\n%q", hasANSI, tt.wantANSI, out.String())
+			}
+		})
+	}
+}
+
+func TestNoKeepAlive(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		noKeepAlive    bool
+		wantDisabled   bool
+		wantConnection string
+		wantClose      bool
+	}{
+		{name: "default", content: "GET {{URL}}\n"},
+		{name: "--no-keepalive", content: "GET {{URL}}\n", noKeepAlive: true, wantDisabled: true, wantConnection: "close", wantClose: true},
+		{name: "explicit header is kept", content: "GET {{URL}}\nConnection: Upgrade\n", noKeepAlive: true, wantDisabled: true, wantConnection: "Upgrade", wantClose: true},
+		{name: "explicit close without the flag", content: "GET {{URL}}\nConnection: close\n", wantConnection: "close", wantClose: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotConnection string
+			var gotClose bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotConnection, gotClose = r.Header.Get("Connection"), r.Close
+			}))
+			defer server.Close()
+
+			req, err := Parse(strings.ReplaceAll(tt.content, "{{URL}}", server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.NoKeepAlive = tt.noKeepAlive
+
+			if transport := NewTransport(req.ClientOptions()); transport.DisableKeepAlives != tt.wantDisabled {
+				t.Errorf("DisableKeepAlives = %v, want %v", transport.DisableKeepAlives, tt.wantDisabled)
+			}
+			if _, err := req.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			// net/http moves Connection: close into Request.Close.
+			if gotClose != tt.wantClose {
+				t.Errorf("server saw close = %v, want %v", gotClose, tt.wantClose)
+			}
+			if tt.wantConnection != "close" && gotConnection != tt.wantConnection {
+				t.Errorf("Connection = %q, want %q", gotConnection, tt.wantConnection)
+			}
+		})
+	}
+}
+
+func TestRunGetBodyWarning(t *testing.T) {
+	const warning = "request has a body, most servers ignore it (use --allow-get-body to silence this)"
+
+	tests := []struct {
+		name         string
+		content      string
+		allowGetBody bool
+		wantWarning  string
+	}{
+		{name: "GET with a body", content: "GET {{URL}}\n\nhello", wantWarning: "Warning: GET " + warning},
+		{name: "HEAD with a body", content: "HEAD {{URL}}\n\nhello", wantWarning: "Warning: HEAD " + warning},
+		{name: "--allow-get-body", content: "GET {{URL}}\n\nhello", allowGetBody: true},
+		{name: "GET without a body", content: "GET {{URL}}\n"},
+		{name: "POST with a body", content: "POST {{URL}}\n\nhello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+			}))
+			defer server.Close()
+
+			var out strings.Builder
+			content := strings.ReplaceAll(tt.content, "{{URL}}", server.URL)
+			if _, err := Run(content, ExecuteOptions{Writer: &out, AllowGetBody: tt.allowGetBody}); err != nil {
+				t.Fatal(err)
+			}
+
+			hasWarning := strings.Contains(out.String(), warning)
+			if tt.wantWarning == "" && hasWarning || tt.wantWarning != "" && !strings.Contains(out.String(), tt.wantWarning) {
+				t.Errorf("output = %q, want warning %q", out.String(), tt.wantWarning)
+			}
+			if _, body, _ := strings.Cut(content, "\n\n"); gotBody != body {
+				t.Errorf("server received body %q, want %q", gotBody, body)
+			}
+		})
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s|%s|%s", r.Method, body, r.Header.Get("Authorization"))
+	}
+	target := httptest.NewServer(http.HandlerFunc(echo))
+	defer target.Close()
+	// Same server, reached under another host name.
+	crossHost := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/307":
+			http.Redirect(w, r, "/echo", http.StatusTemporaryRedirect)
+		case "/302":
+			http.Redirect(w, r, "/echo", http.StatusFound)
+		case "/cross":
+			http.Redirect(w, r, crossHost+"/echo", http.StatusTemporaryRedirect)
+		default:
+			echo(w, r)
+		}
+	}))
+	defer origin.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		redirectAuth string
+		want         string
+		wantLog      string
+	}{
+		{name: "307 keeps method and body", path: "/307", want: "POST|hello|Bearer x", wantLog: "Redirect 307: POST " + origin.URL + "/307 -> POST " + origin.URL + "/echo (method and body kept, Authorization kept)"},
+		{name: "302 switches to GET", path: "/302", want: "GET||Bearer x", wantLog: "(changed to GET, body dropped, Authorization kept)"},
+		{name: "cross host drops auth", path: "/cross", want: "POST|hello|", wantLog: "(method and body kept, Authorization dropped)"},
+		{name: "cross host with never", path: "/cross", redirectAuth: RedirectAuthNever, want: "POST|hello|Bearer x", wantLog: "Authorization kept"},
+		{name: "same host with always", path: "/307", redirectAuth: RedirectAuthAlways, want: "POST|hello|", wantLog: "Authorization dropped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := Parse("POST " + origin.URL + tt.path + "\nAuthorization: Bearer x\n\nhello")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var log strings.Builder
+			req.RedirectAuth, req.Log = tt.redirectAuth, &log
+
+			resp, err := req.Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(resp.Body) != tt.want {
+				t.Errorf("target saw %q, want %q", resp.Body, tt.want)
+			}
+			if !strings.Contains(log.String(), tt.wantLog) {
+				t.Errorf("log = %q, want %q", log.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+// countingConn counts the bytes a server connection reads and writes.
+type countingConn struct {
+	net.Conn
+	read, written *atomic.Int64
+}
+
+func (c countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+func (c countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.written.Add(int64(n))
+	return n, err
+}
+
+type countingListener struct {
+	net.Listener
+	read, written atomic.Int64
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{conn, &l.read, &l.written}, nil
+}
+
+func TestExecuteCountsTraffic(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		noContentLength bool
+		wantBody        int64
+	}{
+		{name: "no body", content: "GET {{URL}}/users\n"},
+		{name: "fixed length", content: "POST {{URL}}/users\nContent-Type: application/json\n\n{\"name\":\"ada\"}", wantBody: 14},
+		{name: "chunked", content: "POST {{URL}}/upload\n\n" + strings.Repeat("x", 1000), noContentLength: true, wantBody: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				w.Header().Set("Content-Type", "text/plain")
+				io.WriteString(w, "hello there")
+			}))
+			listener := &countingListener{Listener: server.Listener}
+			server.Listener = listener
+			server.Start()
+
+			req, err := Parse(strings.ReplaceAll(tt.content, "{{URL}}", server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.NoContentLength = tt.noContentLength
+			resp, err := req.Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.Close()
+
+			if resp.SentBody != tt.wantBody {
+				t.Errorf("SentBody = %d, want %d", resp.SentBody, tt.wantBody)
+			}
+			if resp.Size != 11 {
+				t.Errorf("Size = %d, want 11", resp.Size)
+			}
+			// Chunk framing is on the wire but not part of the body.
+			if sent := resp.SentHeaders + resp.SentBody; !tt.noContentLength && sent != listener.read.Load() {
+				t.Errorf("Sent = %d, server read %d", sent, listener.read.Load())
+			}
+			if resp.Received != listener.written.Load() {
+				t.Errorf("Received = %d, server wrote %d", resp.Received, listener.written.Load())
+			}
+
+			t.Setenv("NO_COLOR", "1")
+			var out strings.Builder
+			resp.Fprint(&out)
+			want := fmt.Sprintf("Sent: %s (body %s)\nReceived: %s\n", network.FormatBytes(resp.SentHeaders+resp.SentBody), network.FormatBytes(resp.SentBody), network.FormatBytes(resp.Received))
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Fprint() missing %q:\n%s", want, out.String())
+			}
+		})
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		handler func(w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:    "waiting for headers",
+			content: "GET {{URL}}\n",
+			handler: func(w http.ResponseWriter, r *http.Request) { <-r.Context().Done() },
+		},
+		{
+			name:    "reading the body",
+			content: "GET {{URL}}\n",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "partial")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			},
+		},
+		{
+			name:    "waiting to retry",
+			content: "@retry count=3 delay=10s\nGET {{URL}}\n",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(tt.handler))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(t.Context())
+			time.AfterFunc(100*time.Millisecond, cancel)
+			defer cancel()
+
+			output := filepath.Join(t.TempDir(), "out.txt")
+			start := time.Now()
+			content := strings.ReplaceAll(tt.content, "{{URL}}", server.URL)
+			_, err := Run(content, ExecuteOptions{Context: ctx, OutputFile: output, Writer: io.Discard})
+
+			if !errors.Is(err, network.ErrCanceled) {
+				t.Errorf("Run() error = %v, want %v", err, network.ErrCanceled)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Run() returned after %v", elapsed)
+			}
+			if _, err := os.Stat(output); !os.IsNotExist(err) {
+				t.Errorf("output file exists after cancellation: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunMaxResponseSize(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	body := strings.Repeat("0123456789", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		limit         int64
+		output        bool
+		wantSize      int
+		wantTruncated bool
+	}{
+		{name: "over the limit", limit: 100, wantSize: 100, wantTruncated: true},
+		{name: "at the limit", limit: 1000, wantSize: 1000},
+		{name: "under the limit", limit: 4096, wantSize: 1000},
+		{name: "default limit", wantSize: 1000},
+		{name: "unlimited", limit: -1, wantSize: 1000},
+		{name: "unlimited --output", limit: -1, output: true, wantSize: 1000},
+		{name: "explicit limit applies to --output", limit: 100, output: true, wantSize: 100, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := ExecuteOptions{Writer: &strings.Builder{}, MaxResponseSize: tt.limit}
+			if tt.output {
+				options.OutputFile = filepath.Join(t.TempDir(), "body.txt")
+				options.OutputBodyOnly = true
+			}
+
+			response, err := Run("GET "+server.URL+"\n", options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := options.Writer.(*strings.Builder).String()
+
+			if len(response.Body) != tt.wantSize || response.Size != int64(tt.wantSize) || response.Truncated != tt.wantTruncated {
+				t.Errorf("body = %d bytes, Size %d, Truncated %v, want %d bytes, truncated %v", len(response.Body), response.Size, response.Truncated, tt.wantSize, tt.wantTruncated)
+			}
+			if hasWarning := strings.Contains(out, "Warning: response truncated at "); hasWarning != tt.wantTruncated {
+				t.Errorf("truncation warning printed = %v, want %v:\n%s", hasWarning, tt.wantTruncated, out)
+			}
+			if tt.output {
+				saved, err := os.ReadFile(options.OutputFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(body, string(saved)) || len(saved) != tt.wantSize {
+					t.Errorf("saved %d bytes, want %d", len(saved), tt.wantSize)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ExecuteOptions
+		want    int64
+	}{
+		{name: "default", want: DefaultMaxResponseSize},
+		{name: "default with --output", options: ExecuteOptions{OutputFile: "body.bin"}, want: DefaultMaxResponseSize},
+		{name: "explicit", options: ExecuteOptions{MaxResponseSize: 10 << 20}, want: 10 << 20},
+		{name: "unlimited", options: ExecuteOptions{MaxResponseSize: -1, OutputFile: "body.bin"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.options.maxResponseSize(); got != tt.want {
+			t.Errorf("%s: maxResponseSize() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteConnWait(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		maxConns    int
+		wantAtLeast time.Duration // Bounds of the longest wait, 0 is unbounded
+		wantAtMost  time.Duration
+	}{
+		{name: "one connection", maxConns: 1, wantAtLeast: 2 * delay},
+		{name: "a connection each", maxConns: 3, wantAtMost: delay / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(ClientOptions{MaxConnsPerHost: tt.maxConns, NoKeepAlive: true})
+
+			responses := make([]*HttpResponse, 3)
+			errs := make([]error, len(responses))
+			var wg sync.WaitGroup
+			for i := range responses {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					req, err := Parse("GET " + server.URL + "\n")
+					if err != nil {
+						errs[i] = err
+						return
+					}
+					req.Client = client
+					responses[i], errs[i] = req.Execute()
+				}()
+			}
+			wg.Wait()
+
+			var maxWait time.Duration
+			for i, response := range responses {
+				if errs[i] != nil {
+					t.Fatal(errs[i])
+				}
+				if response.ConnWait < 0 || response.ConnWait > response.Duration {
+					t.Errorf("ConnWait = %v, outside of Duration %v", response.ConnWait, response.Duration)
+				}
+				maxWait = max(maxWait, response.ConnWait)
+			}
+			if tt.wantAtLeast > 0 && maxWait < tt.wantAtLeast {
+				t.Errorf("longest ConnWait = %v, want at least %v", maxWait, tt.wantAtLeast)
+			}
+			if tt.wantAtMost > 0 && maxWait > tt.wantAtMost {
+				t.Errorf("longest ConnWait = %v, want at most %v", maxWait, tt.wantAtMost)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"rq/request/network"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jwtPattern matches compact JWS tokens. Both the header and the payload of a
+// JWT are JSON objects, so they start with "eyJ" once encoded.
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*`)
+
+type JWT struct {
+	Raw     string
+	Header  map[string]any
+	Payload map[string]any
+}
+
+// DecodeJWT splits a token and decodes its header and payload. The
+// signature is not verified.
+func DecodeJWT(token string) (*JWT, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("a JWT has 3 parts, got %d", len(parts))
+	}
+
+	jwt := &JWT{Raw: token}
+	for i, target := range []*map[string]any{&jwt.Header, &jwt.Payload} {
+		data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[i], "="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT encoding: %w", err)
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, fmt.Errorf("invalid JWT JSON: %w", err)
+		}
+	}
+	return jwt, nil
+}
+
+// Expiry returns the "exp" claim, if the token has one.
+func (jwt *JWT) Expiry() (time.Time, bool) {
+	exp, ok := jwt.Payload["exp"].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
+// fprintJWTs prints the tokens found in the headers and body of the response.
+func (resp *HttpResponse) fprintJWTs(w io.Writer) {
+	type found struct {
+		source string
+		token  string
+	}
+	var tokens []found
+	seen := make(map[string]bool)
+	add := func(source, text string) {
+		for _, token := range jwtPattern.FindAllString(text, -1) {
+			if !seen[token] {
+				seen[token] = true
+				tokens = append(tokens, found{source, token})
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(resp.Headers))
+	for key := range resp.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range resp.Headers[key] {
+			add(key+" header", value)
+		}
+	}
+	add("body", resp.BodyString())
+
+	if len(tokens) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nJWT:")
+	for _, t := range tokens {
+		fmt.Fprintf(w, "  From %s: %s\n", t.source, t.token)
+
+		jwt, err := DecodeJWT(t.token)
+		if err != nil {
+			fmt.Fprintf(w, "    %v\n", err)
+			continue
+		}
+
+		fmt.Fprintf(w, "    Header: %s\n", indentJSON(jwt.Header, "    "))
+		fmt.Fprintf(w, "    Payload: %s\n", indentJSON(jwt.Payload, "    "))
+
+		if expiry, ok := jwt.Expiry(); ok {
+			if left := time.Until(expiry); left < 0 {
+				fmt.Fprintf(w, "    %s\n", network.Colorize(network.ColorBoldRed, fmt.Sprintf("Expired %v ago (%s)", (-left).Round(time.Second), expiry.Format(time.RFC3339))))
+			} else {
+				fmt.Fprintf(w, "    Expires in %v (%s)\n", left.Round(time.Second), expiry.Format(time.RFC3339))
+			}
+		}
+	}
+	fmt.Fprintln(w, "  Signatures are not verified")
+}
+
+func indentJSON(value map[string]any, prefix string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(prefix, "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
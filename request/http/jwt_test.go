@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeJWT encodes header and payload JSON into a token with a dummy signature.
+func makeJWT(header, payload string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(header)) + "." + encode([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func TestDecodeJWT(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		wantHeader  map[string]any
+		wantPayload map[string]any
+		wantErr     string
+	}{
+		{
+			name:        "valid",
+			token:       makeJWT(`{"alg":"HS256","typ":"JWT"}`, `{"sub":"42","admin":true}`),
+			wantHeader:  map[string]any{"alg": "HS256", "typ": "JWT"},
+			wantPayload: map[string]any{"sub": "42", "admin": true},
+		},
+		{
+			name:        "padded parts",
+			token:       base64.URLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + base64.URLEncoding.EncodeToString([]byte(`{"a":1}`)) + ".",
+			wantHeader:  map[string]any{"alg": "none"},
+			wantPayload: map[string]any{"a": float64(1)},
+		},
+		{name: "two parts", token: "eyJhIjoxfQ.eyJhIjoxfQ", wantErr: "a JWT has 3 parts, got 2"},
+		{name: "bad encoding", token: "eyJ!.eyJ.sig", wantErr: "invalid JWT encoding"},
+		{name: "not JSON", token: makeJWT(`{"alg"`, `{}`), wantErr: "invalid JWT JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwt, err := DecodeJWT(tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("DecodeJWT() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(jwt.Header, tt.wantHeader) || !reflect.DeepEqual(jwt.Payload, tt.wantPayload) {
+				t.Errorf("DecodeJWT() = %v %v, want %v %v", jwt.Header, jwt.Payload, tt.wantHeader, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestFprintJWTs(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	const header = `{"alg":"HS256","typ":"JWT"}`
+	expired := makeJWT(header, `{"sub":"ada","exp":946684800}`)
+	valid := makeJWT(header, `{"sub":"ada","exp":`+strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)+`}`)
+	noExpiry := makeJWT(header, `{"sub":"grace"}`)
+
+	tests := []struct {
+		name     string
+		headers  map[string][]string
+		body     string
+		decode   bool
+		want     []string
+		wantNone bool
+	}{
+		{
+			name:   "expired token in the body",
+			body:   `{"access_token":"` + expired + `"}`,
+			decode: true,
+			want:   []string{"JWT:\n  From body: " + expired, `"sub": "ada"`, "Expired ", "(" + time.Unix(946684800, 0).Format(time.RFC3339) + ")", "Signatures are not verified"},
+		},
+		{
+			name:    "valid token in a header",
+			headers: map[string][]string{"Authorization": {"Bearer " + valid}},
+			decode:  true,
+			want:    []string{"From Authorization header: " + valid, `"alg": "HS256"`, "Expires in "},
+		},
+		{
+			name:    "cookie and body share a token",
+			headers: map[string][]string{"Set-Cookie": {"session=" + noExpiry + "; HttpOnly"}},
+			body:    noExpiry,
+			decode:  true,
+			want:    []string{"From Set-Cookie header: " + noExpiry, `"sub": "grace"`},
+		},
+		{name: "no tokens", body: `{"token":"opaque"}`, decode: true, wantNone: true},
+		{name: "without --decode-jwt", body: expired, wantNone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &HttpResponse{Status: "200 OK", StatusCode: 200, Headers: tt.headers, Body: []byte(tt.body)}
+
+			var out strings.Builder
+			resp.FprintWith(&out, PrintOptions{JWT: tt.decode})
+
+			if tt.wantNone && strings.Contains(out.String(), "JWT:") {
+				t.Errorf("output decodes tokens:\n%s", out.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			if strings.Count(out.String(), "  From ") > 1 {
+				t.Errorf("a token was printed twice:\n%s", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// outputPath returns the file the response should be saved to. When
+// OutputFile is a directory (existing, or ending with a separator) the file
+// is named after the request and the current time.
+func (options ExecuteOptions) outputPath(response *HttpResponse) (string, error) {
+	target := options.OutputFile
+
+	isDir := strings.HasSuffix(target, "/") || strings.HasSuffix(target, string(os.PathSeparator))
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		isDir = true
+	}
+
+	if !isDir {
+		return target, nil
+	}
+
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	name := options.Name
+	if name == "" {
+		name = "response"
+	}
+	name = strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(name)
+
+	ext := ".txt"
+	if options.OutputBodyOnly {
+		ext = extensionFor(response.Header("Content-Type"))
+	}
+
+	filename := fmt.Sprintf("%s-%s%s", name, time.Now().Format("20060102-150405"), ext)
+	return filepath.Join(target, filename), nil
+}
+
+func extensionFor(contentType []string) string {
+	if len(contentType) == 0 {
+		return ".txt"
+	}
+
+	switch value := strings.ToLower(contentType[0]); {
+	case strings.Contains(value, "json"):
+		return ".json"
+	case strings.Contains(value, "html"):
+		return ".html"
+	case strings.Contains(value, "xml"):
+		return ".xml"
+	case strings.Contains(value, "csv"):
+		return ".csv"
+	default:
+		return ".txt"
+	}
+}
+
+// writeOutput saves data to path, or appends it after a separator line when
+// the file already has content. A failed write leaves the file as it was
+// before, instead of holding a partial response.
+func writeOutput(path string, data []byte, appendMode bool) error {
+	if !appendMode {
+		return replaceFile(path, data)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	entry := data
+	if info.Size() > 0 {
+		separator := fmt.Sprintf("\n--- %s ---\n", time.Now().Format(time.RFC3339))
+		entry = append([]byte(separator), data...)
+	}
+
+	if _, err := file.Write(entry); err != nil {
+		file.Truncate(info.Size())
+		return err
+	}
+	return nil
+}
+
+// replaceFile writes data to a temporary file next to path and renames it
+// over path, so the old file stays in place until the new one is complete.
+func replaceFile(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOutputPath(t *testing.T) {
+	root := t.TempDir()
+	existingDir := filepath.Join(root, "responses")
+	if err := os.Mkdir(existingDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	json := &HttpResponse{Headers: map[string][]string{"Content-Type": {"application/json; charset=utf-8"}}}
+
+	tests := []struct {
+		name    string
+		options ExecuteOptions
+		wantDir string
+		want    string // Exact path for files, pattern of the base name for directories
+	}{
+		{
+			name:    "file",
+			options: ExecuteOptions{OutputFile: filepath.Join(root, "out.txt"), Name: "users/list"},
+			want:    filepath.Join(root, "out.txt"),
+		},
+		{
+			name:    "existing directory",
+			options: ExecuteOptions{OutputFile: existingDir, Name: "users/list"},
+			wantDir: existingDir,
+			want:    `^users_list-\d{8}-\d{6}\.txt$`,
+		},
+		{
+			name:    "trailing separator creates the directory",
+			options: ExecuteOptions{OutputFile: filepath.Join(root, "new", "dir") + string(os.PathSeparator), Name: "login"},
+			wantDir: filepath.Join(root, "new", "dir"),
+			want:    `^login-\d{8}-\d{6}\.txt$`,
+		},
+		{
+			name:    "body only uses the content type",
+			options: ExecuteOptions{OutputFile: existingDir, Name: "login", OutputBodyOnly: true},
+			wantDir: existingDir,
+			want:    `^login-\d{8}-\d{6}\.json$`,
+		},
+		{
+			name:    "unnamed request",
+			options: ExecuteOptions{OutputFile: existingDir},
+			wantDir: existingDir,
+			want:    `^response-\d{8}-\d{6}\.txt$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.outputPath(json)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantDir == "" {
+				if got != tt.want {
+					t.Errorf("outputPath() = %q, want %q", got, tt.want)
+				}
+				return
+			}
+
+			if filepath.Dir(got) != tt.wantDir {
+				t.Errorf("outputPath() = %q, want a file in %s", got, tt.wantDir)
+			}
+			if !regexp.MustCompile(tt.want).MatchString(filepath.Base(got)) {
+				t.Errorf("outputPath() = %q, want a name matching %s", got, tt.want)
+			}
+			if info, err := os.Stat(tt.wantDir); err != nil || !info.IsDir() {
+				t.Errorf("directory %s was not created", tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestPresentSavesIntoDirectory(t *testing.T) {
+	dir := t.TempDir()
+	response := &HttpResponse{Status: "200 OK", Body: []byte(`{"ok":true}`), Headers: map[string][]string{"Content-Type": {"application/json"}}}
+
+	options := ExecuteOptions{OutputFile: dir, Name: "health", OutputBodyOnly: true, Writer: io.Discard}
+	if err := Present(response, options); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("directory holds %d files, want 1", len(entries))
+	}
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != `{"ok":true}` {
+		t.Errorf("saved %q, want the body", content)
+	}
+}
+
+func TestPresentSavesHeadersSeparately(t *testing.T) {
+	dir := t.TempDir()
+	response := &HttpResponse{
+		Status: "200 OK",
+		Headers: map[string][]string{
+			"Set-Cookie":   {"a=1; Path=/", "b=2; Path=/"},
+			"Content-Type": {"text/plain"},
+		},
+		Body: []byte("line one\n\nline two\n"),
+	}
+
+	tests := []struct {
+		name     string
+		options  ExecuteOptions
+		wantBody string
+	}{
+		{
+			name:     "with output",
+			options:  ExecuteOptions{OutputFile: filepath.Join(dir, "body.txt"), HeadersFile: filepath.Join(dir, "headers.txt")},
+			wantBody: "line one\n\nline two\n",
+		},
+		{
+			name:    "headers only",
+			options: ExecuteOptions{HeadersFile: filepath.Join(dir, "only-headers.txt")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Writer = io.Discard
+			if err := Present(response, tt.options); err != nil {
+				t.Fatal(err)
+			}
+
+			headers, err := os.ReadFile(tt.options.HeadersFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantHeaders := "Content-Type: text/plain\nSet-Cookie: a=1; Path=/\nSet-Cookie: b=2; Path=/\n"
+			if string(headers) != wantHeaders {
+				t.Errorf("headers file =\n%s\nwant\n%s", headers, wantHeaders)
+			}
+
+			if tt.options.OutputFile == "" {
+				return
+			}
+			body, err := os.ReadFile(tt.options.OutputFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("output file = %q, want only the body %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestBinaryDownloadIsByteExact(t *testing.T) {
+	fixture := make([]byte, 64<<10)
+	for i := range fixture {
+		fixture[i] = byte(i*7 + i/256)
+	}
+	copy(fixture, "\x89PNG\r\n\x1a\n\x00\xff\xfe\r\n\r\n")
+	want := sha256.Sum256(fixture)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	req, err := Parse("GET " + server.URL + "/logo.png\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := req.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "logo.png")
+	var out bytes.Buffer
+	if err := Present(resp, ExecuteOptions{OutputFile: path, OutputBodyOnly: true, Tee: true, Writer: &out}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sha256.Sum256(saved); got != want {
+		t.Errorf("saved file checksum %x, want %x (%d of %d bytes)", got, want, len(saved), len(fixture))
+	}
+	if !strings.Contains(out.String(), "(binary body, image/png, 64.0 KB") {
+		t.Errorf("binary body was not summarized:\n%s", out.String())
+	}
+}
+
+func TestPresentTee(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	response := &HttpResponse{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"ok":true}`),
+	}
+
+	tests := []struct {
+		name       string
+		options    ExecuteOptions
+		wantFile   string
+		wantStdout bool
+	}{
+		{name: "output only", options: ExecuteOptions{}, wantFile: "Status: 200 OK"},
+		{name: "tee", options: ExecuteOptions{Tee: true}, wantFile: "Status: 200 OK", wantStdout: true},
+		{name: "tee with output-body", options: ExecuteOptions{Tee: true, OutputBodyOnly: true}, wantFile: `{"ok":true}`, wantStdout: true},
+		{name: "tee with brief", options: ExecuteOptions{Tee: true, OutputBodyOnly: true, Brief: true}, wantFile: `{"ok":true}`, wantStdout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			tt.options.OutputFile = filepath.Join(t.TempDir(), "response.txt")
+			tt.options.Writer = &out
+			if err := Present(response, tt.options); err != nil {
+				t.Fatal(err)
+			}
+
+			saved, err := os.ReadFile(tt.options.OutputFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.options.OutputBodyOnly && string(saved) != tt.wantFile || !strings.HasPrefix(string(saved), tt.wantFile) {
+				t.Errorf("saved %q, want %q", saved, tt.wantFile)
+			}
+
+			if !strings.Contains(out.String(), "Response saved to: "+tt.options.OutputFile) {
+				t.Errorf("output does not report the saved file:\n%s", out.String())
+			}
+			printed := strings.Contains(out.String(), `"ok": true`) || strings.Contains(out.String(), "200 OK  ")
+			if printed != tt.wantStdout {
+				t.Errorf("response printed = %v, want %v:\n%s", printed, tt.wantStdout, out.String())
+			}
+		})
+	}
+}
+
+func TestPresentOutputAppend(t *testing.T) {
+	separator := `\n--- \d{4}-\d{2}-\d{2}T[^ ]+ ---\n`
+
+	tests := []struct {
+		name     string
+		existing string // Content of the file before saving, when exists
+		exists   bool
+		append   bool
+		want     string // Pattern for the whole file after saving {"n":1} then {"n":2}
+	}{
+		{name: "append to a new file", append: true, want: `^\{"n":1\}` + separator + `\{"n":2\}$`},
+		{name: "append to an existing file", existing: "earlier", exists: true, append: true, want: `^earlier` + separator + `\{"n":1\}` + separator + `\{"n":2\}$`},
+		{name: "append to an empty file", exists: true, append: true, want: `^\{"n":1\}` + separator + `\{"n":2\}$`},
+		{name: "overwrite", existing: "earlier", exists: true, want: `^\{"n":2\}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "log.json")
+			if tt.exists {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, body := range []string{`{"n":1}`, `{"n":2}`} {
+				response := &HttpResponse{Status: "200 OK", Body: []byte(body)}
+				options := ExecuteOptions{OutputFile: path, OutputBodyOnly: true, OutputAppend: tt.append, Writer: io.Discard}
+				if err := Present(response, options); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !regexp.MustCompile(tt.want).Match(content) {
+				t.Errorf("file = %q, want it to match %s", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteOutputReplaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // Content of the target before writing, "dir" makes it a directory
+		wantErr  bool
+		want     string
+	}{
+		{name: "new file", want: "new"},
+		{name: "over a file", existing: "old response", want: "new"},
+		{name: "rename fails", existing: "dir", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "response.txt")
+			switch tt.existing {
+			case "":
+			case "dir":
+				if err := os.MkdirAll(filepath.Join(path, "kept"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			default:
+				if err := os.WriteFile(path, []byte(tt.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := writeOutput(path, []byte("new"), false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("writeOutput() over a directory succeeded")
+				}
+				if _, err := os.Stat(filepath.Join(path, "kept")); err != nil {
+					t.Errorf("the existing target was touched: %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := os.ReadFile(path)
+				if err != nil || string(data) != tt.want {
+					t.Errorf("file = %q, %v, want %q", data, err, tt.want)
+				}
+				if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+					t.Errorf("file mode = %v, want 0644", info.Mode().Perm())
+				}
+			}
+
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Errorf("directory holds %d entries, want only the target", len(entries))
+			}
+		})
+	}
+}
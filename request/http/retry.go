@@ -0,0 +1,191 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"rq/directive"
+	"rq/request/network"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy says how often a request is sent again when it fails with a
+// transient network error or one of the listed status codes.
+type RetryPolicy struct {
+	Count   int           // Attempts after the first one
+	On      []int         // Status codes that trigger a retry, empty means any 5xx
+	Delay   time.Duration // Wait between attempts
+	MaxWait time.Duration // Cap on a server's Retry-After, 0 uses DefaultMaxRetryWait
+}
+
+// DefaultMaxRetryWait caps how long a Retry-After header can make rq wait.
+const DefaultMaxRetryWait = 60 * time.Second
+
+// ParseRetry reads the settings of a "@retry count=3 on=502,503 delay=1s"
+// directive.
+func ParseRetry(spec string) (RetryPolicy, error) {
+	var policy RetryPolicy
+
+	for _, field := range strings.Fields(spec) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return policy, fmt.Errorf("invalid @retry setting %q, expected key=value", field)
+		}
+
+		switch key {
+		case "count":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 0 {
+				return policy, fmt.Errorf("invalid @retry count %q", value)
+			}
+			policy.Count = count
+		case "on":
+			codes, err := ParseStatusCodes(value)
+			if err != nil {
+				return policy, err
+			}
+			policy.On = codes
+		case "delay":
+			delay, err := time.ParseDuration(value)
+			if err != nil || delay < 0 {
+				return policy, fmt.Errorf("invalid @retry delay %q", value)
+			}
+			policy.Delay = delay
+		case "max-wait":
+			wait, err := time.ParseDuration(value)
+			if err != nil || wait <= 0 {
+				return policy, fmt.Errorf("invalid @retry max-wait %q", value)
+			}
+			policy.MaxWait = wait
+		default:
+			return policy, fmt.Errorf("unknown @retry setting %q", key)
+		}
+	}
+
+	return policy, nil
+}
+
+// ParseStatusCodes reads a comma separated list of status codes.
+func ParseStatusCodes(value string) ([]int, error) {
+	var codes []int
+	for _, part := range strings.Split(value, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// Merge returns the policy with the fields set in override replacing its own.
+func (policy RetryPolicy) Merge(override RetryPolicy) RetryPolicy {
+	if override.Count > 0 {
+		policy.Count = override.Count
+	}
+	if len(override.On) > 0 {
+		policy.On = override.On
+	}
+	if override.Delay > 0 {
+		policy.Delay = override.Delay
+	}
+	if override.MaxWait > 0 {
+		policy.MaxWait = override.MaxWait
+	}
+	return policy
+}
+
+// wait is how long to wait before the next attempt: the Retry-After of the
+// response when it has one, capped at MaxWait, otherwise Delay.
+func (policy RetryPolicy) wait(response *HttpResponse) (time.Duration, bool) {
+	if response != nil {
+		for _, value := range response.Header("Retry-After") {
+			if after, ok := parseRetryAfter(value, time.Now()); ok {
+				limit := policy.MaxWait
+				if limit <= 0 {
+					limit = DefaultMaxRetryWait
+				}
+				return min(after, limit), true
+			}
+		}
+	}
+	return policy.Delay, false
+}
+
+// parseRetryAfter reads a Retry-After value, either seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func (policy RetryPolicy) retriesStatus(status int) bool {
+	if len(policy.On) == 0 {
+		return status >= 500
+	}
+	return slices.Contains(policy.On, status)
+}
+
+// extractDirectives removes the @retry lines that precede the request line.
+// The last one wins.
+func extractDirectives(content string) (string, RetryPolicy, error) {
+	content, directives := directive.Extract(content, "retry")
+
+	var policy RetryPolicy
+	for _, d := range directives {
+		parsed, err := ParseRetry(d.Args)
+		if err != nil {
+			return content, policy, fmt.Errorf("line %d: %w", d.Line, err)
+		}
+		policy = parsed
+	}
+
+	return content, policy, nil
+}
+
+// ExecuteWithRetry sends the request, sending it again as its Retry policy
+// allows. Each retry is reported to log.
+func (req *HttpRequest) ExecuteWithRetry(log io.Writer) (*HttpResponse, error) {
+	response, err := req.Execute()
+
+	for attempt := 1; attempt <= req.Retry.Count; attempt++ {
+		var reason string
+		switch {
+		case err != nil && network.IsTransient(err):
+			reason = err.Error()
+		case err == nil && req.Retry.retriesStatus(response.StatusCode):
+			reason = response.Status
+		default:
+			return response, err
+		}
+
+		delay, fromServer := req.Retry.wait(response)
+		if fromServer {
+			reason += ", as Retry-After asks"
+		}
+
+		fmt.Fprintf(log, "Retry %d/%d in %v after %s\n", attempt, req.Retry.Count, delay.Round(time.Millisecond), reason)
+		select {
+		case <-time.After(delay):
+		case <-req.context().Done():
+			return nil, network.ErrCanceled
+		}
+		response, err = req.Execute()
+	}
+
+	return response, err
+}
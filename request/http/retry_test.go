@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetry(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    RetryPolicy
+		wantErr string
+	}{
+		{spec: "", want: RetryPolicy{}},
+		{spec: "count=3", want: RetryPolicy{Count: 3}},
+		{spec: "count=3 on=502,503 delay=1s", want: RetryPolicy{Count: 3, On: []int{502, 503}, Delay: time.Second}},
+		{spec: "  delay=250ms   max-wait=5s ", want: RetryPolicy{Delay: 250 * time.Millisecond, MaxWait: 5 * time.Second}},
+		{spec: "count", wantErr: `invalid @retry setting "count", expected key=value`},
+		{spec: "count=-1", wantErr: `invalid @retry count "-1"`},
+		{spec: "on=502,abc", wantErr: `invalid status code "abc"`},
+		{spec: "on=999", wantErr: `invalid status code "999"`},
+		{spec: "delay=soon", wantErr: `invalid @retry delay "soon"`},
+		{spec: "max-wait=0s", wantErr: `invalid @retry max-wait "0s"`},
+		{spec: "backoff=2", wantErr: `unknown @retry setting "backoff"`},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRetry(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseRetry(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRetry(%q) = %+v, %v, want %+v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyMerge(t *testing.T) {
+	file := RetryPolicy{Count: 3, On: []int{503}, Delay: time.Second}
+
+	tests := []struct {
+		name     string
+		override RetryPolicy
+		want     RetryPolicy
+	}{
+		{name: "nothing set", want: file},
+		{name: "count", override: RetryPolicy{Count: 5}, want: RetryPolicy{Count: 5, On: []int{503}, Delay: time.Second}},
+		{name: "every field", override: RetryPolicy{Count: 1, On: []int{429}, Delay: time.Millisecond, MaxWait: time.Minute}, want: RetryPolicy{Count: 1, On: []int{429}, Delay: time.Millisecond, MaxWait: time.Minute}},
+	}
+
+	for _, tt := range tests {
+		if got := file.Merge(tt.override); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Merge() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "3", want: 3 * time.Second, wantOK: true},
+		{value: " 0 ", want: 0, wantOK: true},
+		{value: "-1"},
+		{value: "Wed, 01 Jan 2025 12:00:10 GMT", want: 10 * time.Second, wantOK: true},
+		{value: "Wed, 01 Jan 2025 11:00:00 GMT", want: 0, wantOK: true},
+		{value: "later"},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	soon := time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		name           string
+		policy         RetryPolicy
+		retryAfter     []string
+		wantMin        time.Duration
+		wantMax        time.Duration
+		wantFromServer bool
+	}{
+		{name: "no response", policy: RetryPolicy{Delay: time.Second}, wantMin: time.Second, wantMax: time.Second},
+		{name: "no header", policy: RetryPolicy{Delay: time.Second}, retryAfter: []string{}, wantMin: time.Second, wantMax: time.Second},
+		{name: "seconds", policy: RetryPolicy{Delay: time.Second}, retryAfter: []string{"5"}, wantMin: 5 * time.Second, wantMax: 5 * time.Second, wantFromServer: true},
+		{name: "HTTP date", policy: RetryPolicy{Delay: time.Second}, retryAfter: []string{soon}, wantMin: time.Second, wantMax: 3 * time.Second, wantFromServer: true},
+		{name: "capped at MaxWait", policy: RetryPolicy{Delay: time.Second, MaxWait: 2 * time.Second}, retryAfter: []string{"3600"}, wantMin: 2 * time.Second, wantMax: 2 * time.Second, wantFromServer: true},
+		{name: "default cap", retryAfter: []string{"86400"}, wantMin: DefaultMaxRetryWait, wantMax: DefaultMaxRetryWait, wantFromServer: true},
+		{name: "invalid value uses the delay", policy: RetryPolicy{Delay: time.Second}, retryAfter: []string{"soon"}, wantMin: time.Second, wantMax: time.Second},
+		{name: "first valid value", policy: RetryPolicy{Delay: time.Second}, retryAfter: []string{"soon", "4"}, wantMin: 4 * time.Second, wantMax: 4 * time.Second, wantFromServer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response *HttpResponse
+			if tt.retryAfter != nil {
+				response = &HttpResponse{StatusCode: 503, Headers: map[string][]string{"Retry-After": tt.retryAfter}}
+			}
+
+			got, fromServer := tt.policy.wait(response)
+			if got < tt.wantMin || got > tt.wantMax || fromServer != tt.wantFromServer {
+				t.Errorf("wait() = %v, %v, want %v to %v, %v", got, fromServer, tt.wantMin, tt.wantMax, tt.wantFromServer)
+			}
+		})
+	}
+}
+
+func TestRetryDirective(t *testing.T) {
+	tests := []struct {
+		name         string
+		directive    string
+		failures     int32
+		status       int
+		retryAfter   string
+		options      ExecuteOptions
+		wantStatus   int
+		wantAttempts int32
+		wantLog      string
+	}{
+		{name: "retries until success", directive: "@retry count=3 delay=1ms\n", failures: 2, status: 503, wantStatus: 200, wantAttempts: 3, wantLog: "Retry 2/3 in 1ms after 503 Service Unavailable\n"},
+		{name: "gives up after count", directive: "@retry count=2 delay=1ms\n", failures: 5, status: 502, wantStatus: 502, wantAttempts: 3},
+		{name: "only listed codes", directive: "@retry count=3 on=503 delay=1ms\n", failures: 5, status: 500, wantStatus: 500, wantAttempts: 1},
+		{name: "listed code", directive: "@retry count=3 on=429 delay=1ms\n", failures: 1, status: 429, wantStatus: 200, wantAttempts: 2},
+		{name: "no directive", failures: 1, status: 503, wantStatus: 503, wantAttempts: 1},
+		{name: "flag overrides the count", directive: "@retry count=1 delay=1ms\n", failures: 3, status: 503, options: ExecuteOptions{Retry: RetryPolicy{Count: 3}}, wantStatus: 200, wantAttempts: 4},
+		{name: "--no-retry", directive: "@retry count=3 delay=1ms\n", failures: 1, status: 503, options: ExecuteOptions{NoRetry: true}, wantStatus: 503, wantAttempts: 1},
+		{name: "Retry-After seconds", directive: "@retry count=1 delay=1h max-wait=1ms\n", failures: 1, status: 503, retryAfter: "30", wantStatus: 200, wantAttempts: 2, wantLog: "Retry 1/1 in 1ms after 503 Service Unavailable, as Retry-After asks\n"},
+		{name: "Retry-After date", directive: "@retry count=1 delay=1h max-wait=1ms\n", failures: 1, status: 429, retryAfter: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), options: ExecuteOptions{Retry: RetryPolicy{On: []int{429}}}, wantStatus: 200, wantAttempts: 2, wantLog: "Retry 1/1 in 1ms after 429 Too Many Requests, as Retry-After asks\n"},
+		{name: "Retry-After in the past", directive: "@retry count=1 delay=1h\n", failures: 1, status: 503, retryAfter: "Wed, 01 Jan 2025 11:00:00 GMT", wantStatus: 200, wantAttempts: 2, wantLog: "Retry 1/1 in 0s after 503 Service Unavailable, as Retry-After asks\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) > tt.failures {
+					return
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			req, err := Prepare(tt.directive+"GET "+server.URL+"\n", tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var log strings.Builder
+			resp, err := req.ExecuteWithRetry(&log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus || attempts.Load() != tt.wantAttempts {
+				t.Errorf("got %d after %d attempts, want %d after %d", resp.StatusCode, attempts.Load(), tt.wantStatus, tt.wantAttempts)
+			}
+			if !strings.Contains(log.String(), tt.wantLog) {
+				t.Errorf("log = %q, want %q", log.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestRetryDirectiveErrors(t *testing.T) {
+	_, err := Prepare("@retry count=2\n@retry every=1s\nGET http://localhost/\n", ExecuteOptions{})
+	if err == nil || !strings.Contains(err.Error(), `line 2: unknown @retry setting "every"`) {
+		t.Errorf("Prepare() error = %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http/httptrace"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TraceEvent is one step of the exchange, timed from the start of Execute
+// with the monotonic clock.
+type TraceEvent struct {
+	Event     string  `json:"event"`
+	ElapsedMS float64 `json:"elapsed_ms"`
+	Detail    string  `json:"detail,omitempty"`
+}
+
+// tracer records the httptrace callbacks. DNS and dial callbacks run on the
+// transport's goroutines, hence the lock.
+type tracer struct {
+	mu        sync.Mutex
+	start     time.Time
+	requested time.Time
+	connWait  time.Duration // Time between asking the pool for a connection and getting one
+	events    []TraceEvent
+}
+
+func newTracer(start time.Time) *tracer {
+	return &tracer{start: start}
+}
+
+func (t *tracer) record(event, detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	elapsed := time.Since(t.start)
+	t.events = append(t.events, TraceEvent{
+		Event:     event,
+		ElapsedMS: float64(elapsed.Microseconds()) / 1000,
+		Detail:    detail,
+	})
+}
+
+func errDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func (t *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.mu.Lock()
+			t.requested = time.Now()
+			t.mu.Unlock()
+			t.record("get_conn", hostPort)
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			t.record("dns_start", info.Host)
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			detail := errDetail(info.Err)
+			if detail == "" {
+				detail = fmt.Sprint(info.Addrs)
+			}
+			t.record("dns_done", detail)
+		},
+		ConnectStart: func(network, addr string) {
+			t.record("connect_start", network+" "+addr)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.record("connect_done", errDetail(err))
+		},
+		TLSHandshakeStart: func() {
+			t.record("tls_start", "")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			detail := errDetail(err)
+			if err == nil {
+				detail = tls.VersionName(state.Version)
+			}
+			t.record("tls_done", detail)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.connWait += time.Since(t.requested)
+			t.mu.Unlock()
+			t.record("got_conn", "reused="+strconv.FormatBool(info.Reused))
+		},
+		WroteHeaders: func() {
+			t.record("wrote_headers", "")
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			t.record("wrote_request", errDetail(info.Err))
+		},
+		GotFirstResponseByte: func() {
+			t.record("first_byte", "")
+		},
+	}
+}
+
+// SaveTrace writes the timeline of the exchange as JSON.
+func (resp *HttpResponse) SaveTrace(path string) error {
+	data, err := json.MarshalIndent(struct {
+		Method  string       `json:"method"`
+		URL     string       `json:"url"`
+		Started string       `json:"started"`
+		Events  []TraceEvent `json:"events"`
+	}{resp.Method, resp.URL, resp.Started.Format(time.RFC3339Nano), resp.Timeline}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trace: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write trace: %w", err)
+	}
+	return nil
+}
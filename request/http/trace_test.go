@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTraceFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "traced")
+	}))
+	defer server.Close()
+	// A host name, unlike the server's IP address, goes through DNS.
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name       string
+		content    string
+		wantEvents []string
+	}{
+		{
+			name:       "GET",
+			content:    "GET " + url + "/users\n",
+			wantEvents: []string{"get_conn", "dns_start", "dns_done", "connect_start", "connect_done", "got_conn", "wrote_headers", "wrote_request", "first_byte", "body_done"},
+		},
+		{
+			name:       "POST with a body",
+			content:    "POST " + url + "/users\nContent-Type: application/json\n\n{\"name\":\"ada\"}",
+			wantEvents: []string{"get_conn", "got_conn", "wrote_headers", "wrote_request", "first_byte", "body_done"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "trace.json")
+			var out strings.Builder
+			if _, err := Run(tt.content, ExecuteOptions{Writer: &out, TraceFile: path, NoKeepAlive: true}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), "Trace saved to: "+path) {
+				t.Errorf("output does not mention the trace file:\n%s", out.String())
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var trace struct {
+				Method  string       `json:"method"`
+				URL     string       `json:"url"`
+				Started string       `json:"started"`
+				Events  []TraceEvent `json:"events"`
+			}
+			if err := json.Unmarshal(data, &trace); err != nil {
+				t.Fatalf("trace is not JSON: %v\n%s", err, data)
+			}
+			if method, _, _ := strings.Cut(tt.content, " "); trace.Method != method || !strings.HasSuffix(trace.URL, "/users") {
+				t.Errorf("trace is for %s %s", trace.Method, trace.URL)
+			}
+			if _, err := time.Parse(time.RFC3339Nano, trace.Started); err != nil {
+				t.Errorf("started = %q: %v", trace.Started, err)
+			}
+
+			checkTimeline(t, trace.Events, tt.wantEvents)
+			if last := trace.Events[len(trace.Events)-1]; last.Event != "body_done" || last.Detail != "6 bytes" {
+				t.Errorf("last event = %+v, want body_done with 6 bytes", last)
+			}
+		})
+	}
+}
+
+func TestExecuteTraceTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	req, err := Parse("GET " + server.URL + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Client = NewClient(ClientOptions{TLSConfig: &tls.Config{RootCAs: roots}, NoKeepAlive: true})
+
+	resp, err := req.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTimeline(t, resp.Timeline, []string{"connect_done", "tls_start", "tls_done", "got_conn", "first_byte"})
+
+	for _, event := range resp.Timeline {
+		if event.Event == "tls_done" && !strings.HasPrefix(event.Detail, "TLS 1.") {
+			t.Errorf("tls_done detail = %q, want the TLS version", event.Detail)
+		}
+	}
+}
+
+// checkTimeline verifies that the events hold want in that order, with
+// elapsed times that never go back.
+func checkTimeline(t *testing.T, events []TraceEvent, want []string) {
+	t.Helper()
+	var names []string
+	for i, event := range events {
+		names = append(names, event.Event)
+		if i > 0 && event.ElapsedMS < events[i-1].ElapsedMS {
+			t.Errorf("%s at %vms comes before %s at %vms", event.Event, event.ElapsedMS, events[i-1].Event, events[i-1].ElapsedMS)
+		}
+	}
+
+	last := -1
+	for _, name := range want {
+		i := slices.Index(names, name)
+		if i == -1 {
+			t.Errorf("timeline %q has no %s", names, name)
+			continue
+		}
+		if i < last {
+			t.Errorf("timeline %q has %s out of order", names, name)
+		}
+		last = i
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// countingBody counts the body bytes the transport actually reads, which
+// is the only way to know the size of a chunked body.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (body *countingBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	body.n += int64(n)
+	return n, err
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// requestHeaderSize is the size of the request line and headers as net/http
+// writes them for HTTP/1.1.
+func requestHeaderSize(req *http.Request) int64 {
+	w := &countingWriter{}
+	io.WriteString(w, req.Method+" "+req.URL.RequestURI()+" HTTP/1.1\r\n")
+	io.WriteString(w, "Host: "+req.URL.Host+"\r\n")
+
+	header := req.Header.Clone()
+	if len(req.TransferEncoding) > 0 {
+		header.Set("Transfer-Encoding", strings.Join(req.TransferEncoding, ", "))
+	} else if req.ContentLength > 0 && header.Get("Content-Length") == "" {
+		header.Set("Content-Length", strconv.FormatInt(req.ContentLength, 10))
+	}
+	if header.Get("Accept-Encoding") == "" {
+		header.Set("Accept-Encoding", "gzip")
+	}
+	header.Write(w)
+
+	io.WriteString(w, "\r\n")
+	return w.n
+}
+
+// responseHeaderSize is the size of the status line and headers of resp.
+func responseHeaderSize(resp *http.Response) int64 {
+	w := &countingWriter{}
+	io.WriteString(w, resp.Proto+" "+resp.Status+"\r\n")
+	resp.Header.Write(w)
+	io.WriteString(w, "\r\n")
+	return w.n
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"rq/dock"
+	"rq/request/http"
+	"rq/request/network"
+	"rq/variable"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Inspect prints what running the request would send, without sending it:
+// the resolved request line and headers, the body size, the config files
+// that contributed variables and any placeholders left unresolved.
+func Inspect(ctx *dock.RqContext, request, env string) error {
+	requestPath := resolveRequestPath(ctx.Dock, request)
+	if requestPath == "" {
+		return fmt.Errorf("request file not found: %s", request)
+	}
+
+	options := http.ExecuteOptions{Environment: env}
+	config, err := loadRequestConfig(ctx, request, &options)
+	if err != nil {
+		return err
+	}
+	http.SetDefaultVariables(config)
+
+	raw, err := os.ReadFile(requestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+
+	resolver := variable.NewVariableResolver(config)
+	content, err := resolver.DefineLocals(string(raw))
+	if err != nil {
+		return err
+	}
+	content, unresolved := resolver.ResolvePartial(content)
+
+	relPath, _ := filepath.Rel(ctx.Dock, requestPath)
+	fmt.Printf("Request: %s\n", relPath)
+	if options.Environment != "" {
+		fmt.Printf("Environment: %s\n", options.Environment)
+	}
+
+	if filepath.Ext(requestPath) == ".http" {
+		parsed, err := http.Parse(content)
+		if err != nil {
+			return fmt.Errorf("failed to parse HTTP request: %w", err)
+		}
+
+		fmt.Printf("\n%s %s %s\n", parsed.Method, highlightUnresolved(http.ResolveURL(parsed.URL, config["BASE_URL"])), parsed.Version)
+
+		keys := make([]string, 0, len(parsed.Headers))
+		for key := range parsed.Headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Println("\nHeaders:")
+		if len(keys) == 0 {
+			fmt.Println("  (none)")
+		}
+		for _, key := range keys {
+			fmt.Printf("  %s: %s\n", key, highlightUnresolved(parsed.Headers[key]))
+		}
+
+		fmt.Printf("\nBody: %d bytes\n", len(parsed.Body))
+	} else {
+		fmt.Printf("\n%s\n", highlightUnresolved(strings.TrimRight(content, "\n")))
+	}
+
+	sources, err := ctx.GetConfigSources(filepath.Dir(request), options.Environment)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nConfig files:")
+	if len(sources) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, source := range sources {
+		rel, _ := filepath.Rel(ctx.Dock, source.Path)
+		fmt.Printf("  %s (%d variables)\n", rel, len(source.Keys))
+	}
+
+	if len(unresolved) == 0 {
+		fmt.Println("\nAll placeholders resolved")
+		return nil
+	}
+
+	slices.Sort(unresolved)
+	unresolved = slices.Compact(unresolved)
+
+	fmt.Println("\nUnresolved placeholders:")
+	for _, expression := range unresolved {
+		fmt.Printf("  %s\n", network.Colorize(network.ColorBoldRed, "{{"+expression+"}}"))
+	}
+	return fmt.Errorf("%d unresolved placeholders", len(unresolved))
+}
+
+var unresolvedPattern = regexp.MustCompile(`\{\{.*?\}\}`)
+
+func highlightUnresolved(value string) string {
+	return unresolvedPattern.ReplaceAllStringFunc(value, func(match string) string {
+		return network.Colorize(network.ColorBoldRed, match)
+	})
+}
+
+// ExplainConfig prints every config key used to run the request next to the
+// file that supplied its value.
+func ExplainConfig(ctx *dock.RqContext, request string, options http.ExecuteOptions) error {
+	if _, err := loadRequestConfig(ctx, request, &options); err != nil {
+		return err
+	}
+
+	provenance, err := ctx.GetConfigProvenance(filepath.Dir(request), options.Environment)
+	if err != nil {
+		return err
+	}
+	for _, envFile := range options.EnvFiles {
+		values, err := dock.LoadRawConfigFile(envFile)
+		if err != nil {
+			return err
+		}
+		path, _ := filepath.Abs(envFile)
+		for key := range values {
+			provenance[key] = path
+		}
+	}
+
+	keys := make([]string, 0, len(provenance))
+	width := 0
+	for key := range provenance {
+		keys = append(keys, key)
+		width = max(width, len(key))
+	}
+	sort.Strings(keys)
+
+	fmt.Println("Config:")
+	for _, key := range keys {
+		rel, _ := filepath.Rel(ctx.Dock, provenance[key])
+		fmt.Printf("  %-*s  %s\n", width, key, rel)
+	}
+	fmt.Println()
+	return nil
+}
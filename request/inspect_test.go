@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"io"
+	"os"
+	"rq/dock"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what fn printed to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestInspect(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	root := writeDock(t, map[string]string{
+		".env":         "BASE_URL=http://localhost:8080\nTOKEN=abc\n",
+		".env.staging": "BASE_URL=https://staging.test\n",
+		"users.http":   "POST /users?team={{TEAM}}\nAuthorization: Bearer {{TOKEN}}\nX-Trace: {{TRACE_ID}}\n\n{\"name\":\"{{TEAM}}\"}\n",
+		"health.http":  "GET /health\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		request string
+		env     string
+		want    []string
+		notWant []string
+		wantErr string
+	}{
+		{
+			name:    "unresolved placeholders are flagged",
+			request: "users",
+			want: []string{
+				"POST http://localhost:8080/users?team={{TEAM}}",
+				"Authorization: Bearer abc",
+				"X-Trace: {{TRACE_ID}}",
+				"Body: 19 bytes",
+				"Unresolved placeholders:\n  {{TEAM}}\n  {{TRACE_ID}}\n",
+			},
+			wantErr: "2 unresolved placeholders",
+		},
+		{
+			name:    "environment sources",
+			request: "health",
+			env:     "staging",
+			want:    []string{"Environment: staging", "GET https://staging.test/health", ".env (2 variables)", ".env.staging (1 variables)", "All placeholders resolved"},
+			notWant: []string{"Unresolved"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			out := captureStdout(t, func() {
+				err = Inspect(ctx, tt.request, tt.env)
+			})
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Inspect() error = %v, want %q", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output has %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
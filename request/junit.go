@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"rq/dock"
+	"rq/request/http"
+	"rq/request/network"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// writeJUnit saves the results as a JUnit XML report, one testcase per run.
+// Network errors are reported as <error>, any other failure as <failure>.
+func writeJUnit(path, suite string, results []*batchResult, started time.Time, wall time.Duration) error {
+	report := junitSuite{
+		Name:      suite,
+		Tests:     len(results),
+		Time:      seconds(wall),
+		Timestamp: started.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, result := range results {
+		testcase := junitCase{
+			Name:      result.label(),
+			ClassName: suite,
+			Time:      seconds(result.Duration),
+			SystemOut: ansiPattern.ReplaceAllString(result.Output.String(), ""),
+		}
+
+		if result.Err != nil {
+			problem := &junitProblem{Message: result.Err.Error(), Text: result.Err.Error()}
+			if network.IsNetworkError(result.Err) {
+				problem.Type = "NetworkError"
+				testcase.Error = problem
+				report.Errors++
+			} else {
+				problem.Type = "RequestFailure"
+				testcase.Failure = problem
+				report.Failures++
+			}
+		}
+
+		report.Cases = append(report.Cases, testcase)
+	}
+
+	suites := junitSuites{
+		Tests:    report.Tests,
+		Failures: report.Failures,
+		Errors:   report.Errors,
+		Time:     report.Time,
+		Suites:   []junitSuite{report},
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+
+	data = append([]byte(xml.Header), data...)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// runWithReport runs a single request and records it in the JUnit report.
+func runWithReport(ctx *dock.RqContext, name string, options http.ExecuteOptions, run func() error) error {
+	started := time.Now()
+	result := &batchResult{Name: name}
+	result.Err = run()
+	result.Duration = time.Since(started)
+
+	if err := writeJUnit(options.JUnitFile, filepath.Base(ctx.Dock), []*batchResult{result}, started, result.Duration); err != nil {
+		return err
+	}
+	return result.Err
+}
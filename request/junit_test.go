@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bytes"
+	"encoding/xml"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rq/dock"
+	"rq/request/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunManyJUnit(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.WriteHeader(nethttp.StatusNotFound)
+	}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env":         "BASE_URL=" + server.URL + "\n",
+		"found.http":   "@assert status == 404\nGET {{BASE_URL}}/found\n",
+		"missing.http": "@assert status == 200\nGET {{BASE_URL}}/missing\n",
+		"down.http":    "GET http://" + closedPort(t) + "/\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := filepath.Join(t.TempDir(), "report.xml")
+	options := http.ExecuteOptions{Writer: &bytes.Buffer{}, JUnitFile: report, NoRetry: true}
+	if err := RunMany(ctx, []string{"found", "missing", "down"}, options, 2); err == nil {
+		t.Fatal("RunMany() should fail when a request fails")
+	}
+
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(xml.Header)) {
+		t.Errorf("report does not start with the XML header:\n%s", data)
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, data)
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Errors != 1 || len(suites.Suites) != 1 {
+		t.Fatalf("testsuites = %d tests, %d failures, %d errors, %d suites", suites.Tests, suites.Failures, suites.Errors, len(suites.Suites))
+	}
+
+	suite := suites.Suites[0]
+	if suite.Name != filepath.Base(root) || suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 {
+		t.Errorf("testsuite = %+v", suite)
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05", suite.Timestamp); err != nil {
+		t.Errorf("timestamp %q: %v", suite.Timestamp, err)
+	}
+
+	tests := []struct {
+		name        string
+		wantFailure string
+		wantError   string
+	}{
+		{name: "found"},
+		{name: "missing", wantFailure: "RequestFailure"},
+		{name: "down", wantError: "NetworkError"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testcase := suite.Cases[i]
+			if testcase.Name != tt.name || testcase.ClassName != suite.Name {
+				t.Errorf("testcase %d = %s (%s), want %s (%s)", i, testcase.Name, testcase.ClassName, tt.name, suite.Name)
+			}
+			if _, err := strconv.ParseFloat(testcase.Time, 64); err != nil {
+				t.Errorf("time %q is not in seconds", testcase.Time)
+			}
+			if strings.Contains(testcase.SystemOut, "\x1b[") {
+				t.Errorf("system-out has ANSI codes: %q", testcase.SystemOut)
+			}
+
+			checkProblem(t, "failure", testcase.Failure, tt.wantFailure)
+			checkProblem(t, "error", testcase.Error, tt.wantError)
+		})
+	}
+}
+
+func checkProblem(t *testing.T, element string, problem *junitProblem, wantType string) {
+	t.Helper()
+	switch {
+	case wantType == "" && problem != nil:
+		t.Errorf("unexpected <%s>: %+v", element, problem)
+	case wantType != "" && problem == nil:
+		t.Errorf("missing <%s type=%q>", element, wantType)
+	case wantType != "" && (problem.Type != wantType || problem.Message == "" || problem.Text != problem.Message):
+		t.Errorf("<%s> = %+v, want type %q with a message", element, problem, wantType)
+	}
+}
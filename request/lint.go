@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"rq/directive"
+	"rq/dock"
+	"rq/request/http"
+	"rq/variable"
+	"sort"
+	"strings"
+)
+
+type severity string
+
+const (
+	severityError   severity = "error"
+	severityWarning severity = "warning"
+)
+
+type diagnostic struct {
+	Line     int
+	Severity severity
+	Message  string
+}
+
+// Lint checks the named request, or every request of the dock when name is
+// empty, and prints the problems found. It fails when any of them is an
+// error; warnings are only reported.
+func Lint(ctx *dock.RqContext, name, env string) error {
+	var requests []string
+	if name == "" {
+		requests = findAllRequests(ctx.Dock)
+	} else {
+		requestPath := resolveRequestPath(ctx.Dock, name)
+		if requestPath == "" {
+			return fmt.Errorf("request file not found: %s", name)
+		}
+		requests = []string{requestPath}
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, requestPath := range requests {
+		relPath, _ := filepath.Rel(ctx.Dock, requestPath)
+
+		diagnostics, err := lintRequest(ctx, relPath, requestPath, env)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range diagnostics {
+			fmt.Printf("%s:%d: %s: %s\n", relPath, d.Line, d.Severity, d.Message)
+			if d.Severity == severityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+	}
+
+	if errorCount+warningCount == 0 {
+		fmt.Printf("No problems found in %d requests\n", len(requests))
+		return nil
+	}
+
+	fmt.Printf("\n%d errors, %d warnings in %d requests\n", errorCount, warningCount, len(requests))
+	if errorCount > 0 {
+		return fmt.Errorf("%d errors found", errorCount)
+	}
+	return nil
+}
+
+func lintRequest(ctx *dock.RqContext, relPath, requestPath, env string) ([]diagnostic, error) {
+	raw, err := os.ReadFile(requestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+
+	options := http.ExecuteOptions{Environment: env}
+	config, err := loadRequestConfig(ctx, relPath, &options)
+	if err != nil {
+		return nil, err
+	}
+	http.SetDefaultVariables(config)
+
+	var diagnostics []diagnostic
+	report := func(line int, level severity, format string, a ...any) {
+		diagnostics = append(diagnostics, diagnostic{Line: line, Severity: level, Message: fmt.Sprintf(format, a...)})
+	}
+
+	lines := strings.Split(string(raw), "\n")
+
+	resolver := variable.NewVariableResolver(config)
+	if _, err := resolver.DefineLocals(string(raw)); err != nil {
+		report(1, severityError, "%v", err)
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if d, ok := directive.Parse(line); ok && d.Name == "set" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "///") {
+			continue
+		}
+		_, unresolved := resolver.ResolvePartial(line)
+		for _, expression := range unresolved {
+			report(i+1, severityError, "undefined variable {{%s}}", expression)
+		}
+	}
+
+	if filepath.Ext(requestPath) == ".http" {
+		lintHTTP(requestPath, lines, report)
+	}
+
+	sort.SliceStable(diagnostics, func(a, b int) bool {
+		return diagnostics[a].Line < diagnostics[b].Line
+	})
+	return diagnostics, nil
+}
+
+// directiveChecks validates the arguments of each directive a request may
+// start with. @set is checked by DefineLocals.
+var directiveChecks = map[string]func(requestPath string, d directive.Directive) error{
+	"assert": func(_ string, d directive.Directive) error {
+		_, err := parseAssertion(d.Args)
+		return err
+	},
+	"retry": func(_ string, d directive.Directive) error {
+		_, err := http.ParseRetry(d.Args)
+		return err
+	},
+	"headers": func(requestPath string, d directive.Directive) error {
+		path, err := directivePath(requestPath, d)
+		if err == nil {
+			_, err = readHeadersFile(path)
+		}
+		return err
+	},
+	"form-file": func(requestPath string, d directive.Directive) error {
+		path, err := directivePath(requestPath, d)
+		if err == nil {
+			_, err = readFormFile(path)
+		}
+		return err
+	},
+	"set": nil,
+}
+
+// lintHTTP checks the structure of an .http file: the request line, the
+// headers and the body, following the same rules as http.Parse.
+func lintHTTP(requestPath string, lines []string, report func(int, severity, string, ...any)) {
+	directives, i := directive.Preamble(lines)
+	for _, d := range directives {
+		check, ok := directiveChecks[d.Name]
+		if !ok {
+			report(d.Line, severityError, "unknown directive @%s", d.Name)
+			continue
+		}
+		if check == nil {
+			continue
+		}
+		if err := check(requestPath, d); err != nil {
+			report(d.Line, severityError, "%v", err)
+		}
+	}
+
+	if i == len(lines) {
+		report(1, severityError, "missing request line")
+		return
+	}
+
+	requestLine := i + 1
+	parts := strings.Fields(lines[i])
+	method := parts[0]
+
+	if !strings.Contains(method, "{{") && !http.IsValidMethod(method) {
+		report(requestLine, severityError, "invalid HTTP method %q", method)
+	}
+	if len(parts) < 2 {
+		report(requestLine, severityError, "missing URL after %s", method)
+	}
+
+	i++
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			i++
+			break
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "?") || strings.HasPrefix(trimmed, "&") {
+			if name, _, _ := strings.Cut(trimmed[1:], "="); strings.TrimSpace(name) == "" {
+				report(i+1, severityError, "query parameter with an empty name")
+			}
+			continue
+		}
+
+		colon := strings.Index(trimmed, ":")
+		switch {
+		case colon == -1:
+			report(i+1, severityError, "header without a colon: %s", trimmed)
+		case strings.TrimSpace(trimmed[:colon]) == "":
+			report(i+1, severityError, "header with an empty name")
+		}
+	}
+
+	bodyStart := i
+	hasBody := false
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "###") {
+			report(i+1, severityWarning, "request after '###' is never run, rq only runs the first request of a file")
+			break
+		}
+		if trimmed != "" {
+			hasBody = true
+		}
+	}
+
+	upper := strings.ToUpper(method)
+	if hasBody && (upper == "GET" || upper == "HEAD") {
+		report(bodyStart+1, severityWarning, "%s request with a body, most servers ignore it", upper)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"path/filepath"
+	"reflect"
+	"rq/dock"
+	"strings"
+	"testing"
+)
+
+func TestLintRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []diagnostic
+	}{
+		{name: "clean", content: "GET {{BASE_URL}}/users\nAccept: application/json\n"},
+		{
+			name:    "invalid method",
+			content: "FETCH /users\n",
+			want:    []diagnostic{{1, severityError, `invalid HTTP method "FETCH"`}},
+		},
+		{
+			name:    "missing url",
+			content: "# list users\nGET\n",
+			want:    []diagnostic{{2, severityError, "missing URL after GET"}},
+		},
+		{
+			name:    "missing request line",
+			content: "# nothing here\n",
+			want:    []diagnostic{{1, severityError, "missing request line"}},
+		},
+		{
+			name:    "header without a colon",
+			content: "GET /users\nAccept application/json\n: empty\n",
+			want: []diagnostic{
+				{2, severityError, "header without a colon: Accept application/json"},
+				{3, severityError, "header with an empty name"},
+			},
+		},
+		{
+			name:    "query parameter without a name",
+			content: "GET /users\n?=1\n",
+			want:    []diagnostic{{2, severityError, "query parameter with an empty name"}},
+		},
+		{
+			name:    "body on GET",
+			content: "GET /users\n\n{\"a\":1}\n",
+			want:    []diagnostic{{3, severityWarning, "GET request with a body, most servers ignore it"}},
+		},
+		{
+			name:    "undefined variable",
+			content: "GET {{BASE_URL}}/users/{{userId}}\nAuthorization: Bearer {{TOKEN}}\n",
+			want: []diagnostic{
+				{1, severityError, "undefined variable {{userId}}"},
+				{2, severityError, "undefined variable {{TOKEN}}"},
+			},
+		},
+		{
+			name:    "set variables are defined",
+			content: "@set userId = 7\nGET {{BASE_URL}}/users/{{userId}}\n",
+		},
+		{
+			name:    "unreachable block",
+			content: "GET /users\n\n###\nPOST /users\n",
+			want:    []diagnostic{{3, severityWarning, "request after '###' is never run, rq only runs the first request of a file"}},
+		},
+		{
+			name:    "unknown directive",
+			content: "@retyr 3\nGET /users\n",
+			want:    []diagnostic{{1, severityError, "unknown directive @retyr"}},
+		},
+		{
+			name:    "undefined variable in another protocol",
+			file:    "ping.tcp",
+			content: "{{HOST}}:7\nhello\n",
+			want:    []diagnostic{{1, severityError, "undefined variable {{HOST}}"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.file == "" {
+				tt.file = "users.http"
+			}
+			root := writeDock(t, map[string]string{
+				".env":  "BASE_URL=http://localhost\n",
+				tt.file: tt.content,
+			})
+			ctx, err := dock.ContextAt(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := lintRequest(ctx, tt.file, filepath.Join(root, tt.file), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintDock(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		request string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "clean dock",
+			files: map[string]string{"a.http": "GET /a\n", "b.http": "GET /b\n"},
+			want:  []string{"No problems found in 2 requests"},
+		},
+		{
+			name:  "warnings only",
+			files: map[string]string{"a.http": "GET /a\n\nbody\n", "b.http": "GET /b\n"},
+			want:  []string{"a.http:3: warning: GET request with a body", "0 errors, 1 warnings in 2 requests"},
+		},
+		{
+			name:    "errors fail",
+			files:   map[string]string{"a.http": "FETCH /a\n", "users/b.http": "GET {{ID}}\n\nbody\n"},
+			want:    []string{"a.http:1: error: invalid HTTP method", "users/b.http:1: error: undefined variable {{ID}}", "2 errors, 1 warnings in 2 requests"},
+			wantErr: "2 errors found",
+		},
+		{
+			name:    "single request",
+			files:   map[string]string{"a.http": "FETCH /a\n", "b.http": "GET /b\n"},
+			request: "b",
+			want:    []string{"No problems found in 1 requests"},
+		},
+		{
+			name:    "missing request",
+			files:   map[string]string{"a.http": "GET /a\n"},
+			request: "nope",
+			wantErr: "request file not found: nope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := dock.ContextAt(writeDock(t, tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out := captureStdout(t, func() {
+				err = Lint(ctx, tt.request, "")
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Lint() error = %v, want %q", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	ColorReset      = "\033[0m"
+	ColorBold       = "\033[1m"
+	ColorRed        = "\033[31m"
+	ColorGreen      = "\033[32m"
+	ColorYellow     = "\033[33m"
+	ColorMagenta    = "\033[35m"
+	ColorCyan       = "\033[36m"
+	ColorGray       = "\033[90m"
+	ColorBoldRed    = "\033[1;31m"
+	ColorBoldYellow = "\033[1;33m"
+)
+
+// NoColor disables colors regardless of the environment, set by --no-color.
+var NoColor = false
+
+// ColorEnabled follows the NO_COLOR convention (https://no-color.org) and
+// disables colors on dumb terminals and when stdout is not a terminal.
+func ColorEnabled() bool {
+	if NoColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func Colorize(color, text string) string {
+	if !ColorEnabled() || color == "" {
+		return text
+	}
+	return color + text + ColorReset
+}
+
+// PrintMessage prints data exchanged over a stream protocol (TCP,
+// WebSocket) the way HTTP bodies are printed: a colored header with the
+// byte count, then every line, pretty-printing the ones holding JSON.
+func PrintMessage(w io.Writer, label string, data []byte) {
+	fmt.Fprintln(w, Colorize(ColorCyan, fmt.Sprintf("%s (%s)", label, FormatBytes(int64(len(data))))))
+
+	if len(data) == 0 {
+		fmt.Fprintln(w, Colorize(ColorGray, "  (empty)"))
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\r\n"), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if trimmed := strings.TrimSpace(line); json.Valid([]byte(trimmed)) && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) {
+			fmt.Fprintln(w, FormatJSON(trimmed))
+			continue
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseBytes reads a size like "512", "64KB" or "10M", in the same 1024
+// based units FormatBytes prints.
+func ParseBytes(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "B"), "I")
+
+	multiplier := int64(1)
+	if i := strings.IndexAny(text, "KMGT"); i != -1 && i == len(text)-1 {
+		multiplier = int64(1) << (10 * (strings.IndexByte("KMGT", text[i]) + 1))
+		text = strings.TrimSpace(text[:i])
+	}
+
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' is not a positive size like 512, 64KB or 10MB", value)
+	}
+	return n * multiplier, nil
+}
+
+// SplitJSONValues splits a body holding one or more top-level JSON values
+// (e.g. NDJSON) into the individual values. It fails on anything else.
+func SplitJSONValues(body string) ([]string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	var values []string
+
+	for {
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		values = append(values, string(value))
+	}
+
+	return values, len(values) > 0
+}
+
+func FormatJSON(jsonStr string) string {
+	var formatted strings.Builder
+	var indent int
+	var inString bool
+	var escaped bool
+
+	for _, char := range jsonStr {
+		if escaped {
+			formatted.WriteRune(char)
+			escaped = false
+			continue
+		}
+
+		if char == '\\' && inString {
+			formatted.WriteRune(char)
+			escaped = true
+			continue
+		}
+
+		if char == '"' {
+			inString = !inString
+			formatted.WriteRune(char)
+			continue
+		}
+
+		if inString {
+			formatted.WriteRune(char)
+			continue
+		}
+
+		switch char {
+		case '{', '[':
+			formatted.WriteRune(char)
+			indent++
+			formatted.WriteRune('\n')
+			writeIndent(&formatted, indent)
+
+		case '}', ']':
+			formatted.WriteRune('\n')
+			indent--
+			writeIndent(&formatted, indent)
+			formatted.WriteRune(char)
+
+		case ',':
+			formatted.WriteRune(char)
+			formatted.WriteRune('\n')
+			writeIndent(&formatted, indent)
+
+		case ':':
+			formatted.WriteRune(char)
+			formatted.WriteRune(' ')
+
+		case ' ', '\t', '\n', '\r':
+			// Skip whitespace outside strings
+
+		default:
+			formatted.WriteRune(char)
+		}
+	}
+
+	return formatted.String()
+}
+
+func writeIndent(sb *strings.Builder, level int) {
+	for i := 0; i < level*2; i++ {
+		sb.WriteRune(' ')
+	}
+}
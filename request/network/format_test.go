@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package network
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrintMessage(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "empty",
+			want: "Received (0 B)\n  (empty)\n",
+		},
+		{
+			name: "plain text",
+			data: "PONG\r\n",
+			want: "Received (6 B)\nPONG\n",
+		},
+		{
+			name: "json object",
+			data: `{"ok":true,"items":[1,2]}` + "\n",
+			want: "Received (26 B)\n{\n  \"ok\": true,\n  \"items\": [\n    1,\n    2\n  ]\n}\n",
+		},
+		{
+			name: "json lines mixed with text",
+			data: "hello\n[\"a\", \"b\"]\n{not json}\n",
+			want: "Received (28 B)\nhello\n[\n  \"a\",\n  \"b\"\n]\n{not json}\n",
+		},
+		{
+			name: "scalars are not reformatted",
+			data: "42\n\"quoted\"",
+			want: "Received (11 B)\n42\n\"quoted\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			PrintMessage(&out, "Received", []byte(tt.data))
+			if out.String() != tt.want {
+				t.Errorf("PrintMessage() =\n%q\nwant\n%q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestColorize(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if got := Colorize(ColorRed, "text"); got != "text" {
+		t.Errorf("Colorize() with NO_COLOR = %q", got)
+	}
+
+	t.Setenv("NO_COLOR", "")
+	defer func(original bool) { NoColor = original }(NoColor)
+	NoColor = true
+	if got := Colorize(ColorRed, "text"); got != "text" {
+		t.Errorf("Colorize() with --no-color = %q", got)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal bool
+		noColor  bool
+		env      map[string]string
+		want     bool
+	}{
+		{name: "terminal", terminal: true, want: true},
+		{name: "piped", want: false},
+		{name: "NO_COLOR", terminal: true, env: map[string]string{"NO_COLOR": "1"}, want: false},
+		{name: "dumb terminal", terminal: true, env: map[string]string{"TERM": "dumb"}, want: false},
+		{name: "--no-color", terminal: true, noColor: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("TERM", "xterm")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			defer func(original bool) { NoColor = original }(NoColor)
+			NoColor = tt.noColor
+
+			// /dev/null is a character device, so it passes for a terminal.
+			path := "/dev/null"
+			if !tt.terminal {
+				path = filepath.Join(t.TempDir(), "stdout")
+			}
+			stdout, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Skip(err)
+			}
+			defer stdout.Close()
+			defer func(original *os.File) { os.Stdout = original }(os.Stdout)
+			os.Stdout = stdout
+
+			if got := ColorEnabled(); got != tt.want {
+				t.Errorf("ColorEnabled() = %v, want %v", got, tt.want)
+			}
+			if colored := Colorize(ColorRed, "text") != "text"; colored != tt.want {
+				t.Errorf("Colorize() colored = %v, want %v", colored, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:       "0 B",
+		1023:    "1023 B",
+		1024:    "1.0 KB",
+		1536:    "1.5 KB",
+		1 << 20: "1.0 MB",
+		5 << 30: "5.0 GB",
+	}
+	for n, want := range tests {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "512", want: 512},
+		{value: "512B", want: 512},
+		{value: "64KB", want: 64 << 10},
+		{value: "64kb", want: 64 << 10},
+		{value: "10M", want: 10 << 20},
+		{value: " 2 GiB ", want: 2 << 30},
+		{value: "1T", want: 1 << 40},
+		{value: "0", wantErr: true},
+		{value: "-5MB", wantErr: true},
+		{value: "1.5MB", wantErr: true},
+		{value: "MB", wantErr: true},
+		{value: "10XB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseBytes(%q) = %d, want error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrTimeout           = errors.New("request timeout")
+	ErrConnectTimeout    = errors.New("connect timeout")
+	ErrConnectionRefused = errors.New("connection refused - server may be down or unreachable")
+	ErrHostNotFound      = errors.New("host not found - check the URL")
+	ErrCertificate       = errors.New("SSL/TLS certificate error")
+	ErrInvalidAddress    = errors.New("invalid address")
+	ErrNetwork           = errors.New("network error")
+	ErrCanceled          = errors.New("request canceled")
+)
+
+var networkErrors = []error{ErrTimeout, ErrConnectTimeout, ErrConnectionRefused, ErrHostNotFound, ErrCertificate, ErrInvalidAddress, ErrNetwork}
+
+// IsNetworkError reports whether err is one of the errors FormatError
+// returns, as opposed to a problem with the request itself.
+func IsNetworkError(err error) bool {
+	for _, target := range networkErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTransient reports whether sending the same request again may succeed.
+func IsTransient(err error) bool {
+	for _, target := range []error{ErrTimeout, ErrConnectTimeout, ErrConnectionRefused, ErrNetwork} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatError turns low level network errors into the human friendly
+// messages shared by every protocol executor.
+func FormatError(err error, timeout time.Duration) error {
+	if errors.Is(err, context.Canceled) {
+		return ErrCanceled
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w after %v", ErrTimeout, timeout)
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || strings.Contains(err.Error(), "connection refused") {
+		return ErrConnectionRefused
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) || strings.Contains(err.Error(), "no such host") {
+		return ErrHostNotFound
+	}
+
+	if strings.Contains(err.Error(), "certificate") {
+		return fmt.Errorf("%w: %w", ErrCertificate, err)
+	}
+
+	var addrErr *net.AddrError
+	if errors.As(err, &addrErr) {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, addrErr.Err)
+	}
+
+	return fmt.Errorf("%w: %w", ErrNetwork, err)
+}
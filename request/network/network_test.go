@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestFormatError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		want      error
+		message   string
+		transient bool
+	}{
+		{
+			name:    "canceled",
+			err:     context.Canceled,
+			want:    ErrCanceled,
+			message: "request canceled",
+		},
+		{
+			name:      "timeout",
+			err:       &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}},
+			want:      ErrTimeout,
+			message:   "request timeout after 5s",
+			transient: true,
+		},
+		{
+			name:      "connection refused",
+			err:       &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			want:      ErrConnectionRefused,
+			message:   "connection refused - server may be down or unreachable",
+			transient: true,
+		},
+		{
+			name:    "dns",
+			err:     &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "api.invalid"}},
+			want:    ErrHostNotFound,
+			message: "host not found - check the URL",
+		},
+		{
+			name:    "certificate",
+			err:     errors.New("tls: failed to verify certificate: x509: certificate signed by unknown authority"),
+			want:    ErrCertificate,
+			message: "SSL/TLS certificate error: tls: failed to verify certificate: x509: certificate signed by unknown authority",
+		},
+		{
+			name:    "invalid address",
+			err:     &net.OpError{Op: "dial", Net: "tcp", Err: &net.AddrError{Err: "missing port in address", Addr: "localhost"}},
+			want:    ErrInvalidAddress,
+			message: "invalid address: missing port in address",
+		},
+		{
+			name:      "other",
+			err:       errors.New("broken pipe"),
+			want:      ErrNetwork,
+			message:   "network error: broken pipe",
+			transient: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatError(tt.err, 5*time.Second)
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("FormatError() = %v, want %v", got, tt.want)
+			}
+			if got.Error() != tt.message {
+				t.Errorf("FormatError() message = %q, want %q", got.Error(), tt.message)
+			}
+			if IsNetworkError(got) != (tt.want != ErrCanceled) {
+				t.Errorf("IsNetworkError() = %v", IsNetworkError(got))
+			}
+			if IsTransient(got) != tt.transient {
+				t.Errorf("IsTransient() = %v, want %v", IsTransient(got), tt.transient)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSTarget turns "host", "host:port" or an https URL into the address to
+// dial and the server name to verify.
+func TLSTarget(target string) (address, serverName string, err error) {
+	if strings.Contains(target, "://") {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid URL: %w", err)
+		}
+		target = parsed.Host
+	}
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = strings.Trim(target, "[]"), "443"
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("missing host in %q", target)
+	}
+	return net.JoinHostPort(host, port), host, nil
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// InspectTLS performs a TLS handshake with address, without sending
+// anything over the connection, and prints the negotiated parameters and
+// certificate chain. The chain is verified against roots, or the system
+// pool when roots is nil. It returns an error when verification fails.
+func InspectTLS(w io.Writer, address, serverName string, roots *x509.CertPool, timeout time.Duration) error {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true, // Verified below so that broken chains can still be shown
+	})
+	if err != nil {
+		return fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+
+	fmt.Fprintf(w, "Address: %s\n", address)
+	fmt.Fprintf(w, "Version: %s\n", tls.VersionName(state.Version))
+	fmt.Fprintf(w, "Cipher: %s\n", tls.CipherSuiteName(state.CipherSuite))
+	if state.NegotiatedProtocol != "" {
+		fmt.Fprintf(w, "ALPN: %s\n", state.NegotiatedProtocol)
+	}
+
+	fmt.Fprintln(w, "\nCertificates:")
+	now := time.Now()
+	for i, cert := range state.PeerCertificates {
+		fmt.Fprintf(w, "  [%d] %s\n", i, cert.Subject)
+		fmt.Fprintf(w, "      Issuer: %s\n", cert.Issuer)
+		fmt.Fprintf(w, "      Valid: %s to %s", cert.NotBefore.Format(time.DateOnly), cert.NotAfter.Format(time.DateOnly))
+		switch {
+		case now.After(cert.NotAfter):
+			fmt.Fprint(w, Colorize(ColorRed, " (expired)"))
+		case now.Before(cert.NotBefore):
+			fmt.Fprint(w, Colorize(ColorRed, " (not yet valid)"))
+		default:
+			fmt.Fprintf(w, " (%d days left)", int(cert.NotAfter.Sub(now).Hours()/24))
+		}
+		fmt.Fprintln(w)
+
+		names := append([]string{}, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			names = append(names, ip.String())
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(w, "      SANs: %s\n", strings.Join(names, ", "))
+		}
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("server sent no certificates")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		fmt.Fprintf(w, "\nVerification: %s\n", Colorize(ColorRed, "failed"))
+		return fmt.Errorf("%w: %v", ErrCertificate, err)
+	}
+
+	fmt.Fprintf(w, "\nVerification: %s\n", Colorize(ColorGreen, "ok"))
+	return nil
+}
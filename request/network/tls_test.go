@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package network
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTLSTarget(t *testing.T) {
+	tests := []struct {
+		target         string
+		wantAddress    string
+		wantServerName string
+		wantErr        bool
+	}{
+		{target: "example.com", wantAddress: "example.com:443", wantServerName: "example.com"},
+		{target: "example.com:8443", wantAddress: "example.com:8443", wantServerName: "example.com"},
+		{target: "https://example.com/users?page=2", wantAddress: "example.com:443", wantServerName: "example.com"},
+		{target: "https://example.com:9443", wantAddress: "example.com:9443", wantServerName: "example.com"},
+		{target: "[::1]:8443", wantAddress: "[::1]:8443", wantServerName: "::1"},
+		{target: "::1", wantAddress: "[::1]:443", wantServerName: "::1"},
+		{target: ":443", wantErr: true},
+		{target: "https://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		address, serverName, err := TLSTarget(tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("TLSTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			continue
+		}
+		if address != tt.wantAddress || serverName != tt.wantServerName {
+			t.Errorf("TLSTarget(%q) = %q, %q, want %q, %q", tt.target, address, serverName, tt.wantAddress, tt.wantServerName)
+		}
+	}
+}
+
+func TestInspectTLS(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	address := server.Listener.Addr().String()
+
+	tests := []struct {
+		name       string
+		serverName string
+		roots      *x509.CertPool
+		wantErr    bool
+		want       []string
+	}{
+		{
+			name:       "custom CA",
+			serverName: "127.0.0.1",
+			roots:      roots,
+			want: []string{
+				"Address: " + address,
+				"Version: TLS 1.3",
+				"Cipher: TLS_",
+				"[0] O=Acme Co",
+				"Issuer: O=Acme Co",
+				"days left)",
+				"SANs: example.com, *.example.com, 127.0.0.1, ::1",
+				"Verification: ok",
+			},
+		},
+		{name: "system roots", serverName: "127.0.0.1", wantErr: true, want: []string{"[0] O=Acme Co", "Verification: failed"}},
+		{name: "wrong name", serverName: "api.test", roots: roots, wantErr: true, want: []string{"Verification: failed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			err := InspectTLS(&out, address, tt.serverName, tt.roots, 5*time.Second)
+			if tt.wantErr != errors.Is(err, ErrCertificate) {
+				t.Errorf("InspectTLS() error = %v, want a certificate error %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("InspectTLS sent %d HTTP requests", n)
+	}
+}
+
+func TestInspectTLSHandshakeFailure(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	err := InspectTLS(&strings.Builder{}, server.Listener.Addr().String(), "127.0.0.1", nil, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "TLS handshake with "+server.Listener.Addr().String()+" failed") {
+		t.Errorf("InspectTLS() against plain HTTP error = %v", err)
+	}
+}
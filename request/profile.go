@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	"rq/dock"
+	"sort"
+
+	"github.com/marcomit/args"
+)
+
+// applyProfile fills r with the options and flags of a profile from the
+// global config, leaving those given on the command line untouched. The
+// profile goes through the same parser as the command line, so unknown names
+// are rejected and values are read the same way.
+func applyProfile(app *args.Parser, r *args.Result, name string) error {
+	profile, err := dock.LoadProfile(name)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(profile))
+	for key := range profile {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	argv := append([]string{}, r.Command...)
+	for _, key := range keys {
+		if profile[key] == "" {
+			argv = append(argv, "--"+key)
+		} else {
+			argv = append(argv, "--"+key+"="+profile[key])
+		}
+	}
+
+	expanded, err := app.Parse(argv)
+	if err != nil {
+		return fmt.Errorf("Invalid profile %s: %w", name, err)
+	}
+	if _, ok := expanded.Options["profile"]; ok {
+		return fmt.Errorf("Invalid profile %s: profiles cannot select another profile", name)
+	}
+
+	for flag, value := range expanded.Flags {
+		if !r.Flags[flag] {
+			r.Flags[flag] = value
+		}
+	}
+	for option, value := range expanded.Options {
+		if _, ok := r.Options[option]; !ok {
+			r.Options[option] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rq/dock"
+	"strings"
+	"testing"
+
+	"github.com/marcomit/args"
+)
+
+func TestRunProfile(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		fmt.Fprintf(w, "served %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	t.Setenv(dock.ConfigHomeEnv, home)
+	config := "[profile staging]\nbrief\nbase-url=" + server.URL + "/staging\n" +
+		"[profile typo]\nbreif\n" +
+		"[profile nested]\nprofile=staging\n"
+	if err := os.WriteFile(filepath.Join(home, dock.GlobalConfigFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(writeDock(t, map[string]string{
+		".env":       "BASE_URL=" + server.URL + "/default\n",
+		"users.http": "GET {{BASE_URL}}/users\n",
+	}))
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantOutput  []string
+		wantMissing []string
+		wantErr     string
+	}{
+		{
+			name:        "profile sets its options",
+			args:        []string{"run", "users", "--profile", "staging"},
+			wantOutput:  []string{"GET /staging/users"},
+			wantMissing: []string{"served"},
+		},
+		{
+			name:        "flags win over the profile",
+			args:        []string{"run", "users", "--profile", "staging", "--base-url", server.URL + "/local"},
+			wantOutput:  []string{"GET /local/users"},
+			wantMissing: []string{"served", "/staging"},
+		},
+		{
+			name:       "without a profile",
+			args:       []string{"run", "users"},
+			wantOutput: []string{"served /default/users"},
+		},
+		{name: "unknown profile", args: []string{"run", "users", "--profile", "prod"}, wantErr: `profile "prod" not found`},
+		{name: "unknown option", args: []string{"run", "users", "--profile", "typo"}, wantErr: "Invalid profile typo: "},
+		{name: "nested profile", args: []string{"run", "users", "--profile", "nested"}, wantErr: "Invalid profile nested: profiles cannot select another profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = app.Run(tt.args)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(out, missing) {
+					t.Errorf("output has %q:\n%s", missing, out)
+				}
+			}
+		})
+	}
+}
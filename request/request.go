@@ -4,15 +4,26 @@
 package request
 
 import (
+	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"rq/dock"
+	"rq/docs"
+	"rq/environment"
+	"rq/request/http"
+	"rq/request/network"
+	"rq/snapshot"
 	"rq/variable"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/marcomit/args"
 )
@@ -26,17 +37,11 @@ func New(ctx *dock.RqContext, file string, protocol string) error {
 		protocol = "http"
 	}
 
-	validProtocols := map[string]bool{
-		"http":      true,
-		"ws":        true,
-		"tcp":       true,
-		"websocket": true,
-		"grpc":      true,
-	}
-
-	if !validProtocols[protocol] {
-		return fmt.Errorf("unsupported protocol: %s (supported: http, tcp, ftp)", protocol)
+	normalized, ok := dock.NormalizeProtocol(protocol)
+	if !ok {
+		return fmt.Errorf("unsupported protocol: %s (supported: %s)", protocol, strings.Join(dock.Protocols, ", "))
 	}
+	protocol = normalized
 
 	dir := filepath.Dir(file)
 	if dir != "." && dir != "" {
@@ -67,57 +72,302 @@ func New(ctx *dock.RqContext, file string, protocol string) error {
 	return nil
 }
 
+func Copy(ctx *dock.RqContext, source, destination string, force bool) (string, error) {
+	sourcePath := resolveRequestPath(ctx.Dock, source)
+	if sourcePath == "" {
+		return "", fmt.Errorf("request file not found: %s", source)
+	}
+
+	ext := filepath.Ext(sourcePath)
+	destination = strings.TrimSuffix(destination, ext)
+
+	dir := filepath.Dir(destination)
+	if dir != "." && dir != "" {
+		fullDir := filepath.Join(ctx.Path, dir)
+		if err := os.MkdirAll(fullDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create subdirectory %s: %w", dir, err)
+		}
+	}
+
+	fullPath := filepath.Join(ctx.Path, destination+ext)
+	if _, err := os.Stat(fullPath); err == nil && !force {
+		return "", fmt.Errorf("request file already exists: %s%s (use --force to overwrite)", destination, ext)
+	}
+
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read request file: %w", err)
+	}
+
+	if err := os.WriteFile(fullPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write request file: %w", err)
+	}
+
+	return destination + ext, nil
+}
+
 func Setup(app *args.Parser) {
 	app.
 		Command("run", "Runs the specified request").
 		Positional("name").
-		Option("env", "e", "Environment").
+		Option("env", "e", "Environment, or several comma separated to compare them").
+		Option("env-file", "ef", "Load extra variables from these files (comma separated, later files win)").
 		Option("output", "o", "Choose the file to write the response").
-		Option("timeout", "t", "Set the timeout to abort the request").
+		Option("output-headers", "oh", "Save the response headers to this file (--output then receives only the body)").
+		Option("timeout", "t", "Set the timeout to abort the request (same as --max-time)").
+		Option("max-time", "mt", "Maximum time for the whole request, in seconds or as a duration like 1m30s").
+		Option("connect-timeout", "ct", "Maximum time to establish the connection, in seconds or as a duration like 500ms").
+		Option("max-body-print", "mbp", "Print at most N bytes of the body (0 prints everything, default 1MB)").
+		Option("parallel", "p", "Run up to N of the named requests at the same time").
+		Option("data-file", "df", "Run the request once per row of a CSV or JSON file, with the row as variables").
+		Option("tag", "tg", "Run every request tagged with '/// @tag <name>'").
+		Option("junit", "ju", "Write a JUnit XML report of the run to this file").
+		Option("base-url", "bu", "Override BASE_URL for this run").
+		Option("retry", "r", "Send the request again up to N times on 5xx or network errors (overrides @retry)").
+		Option("retry-on", "ro", "Status codes that trigger a retry, comma separated").
+		Option("retry-delay", "rd", "Wait between retries, in seconds or as a duration like 500ms").
+		Option("max-retry-wait", "mrw", "Longest wait a Retry-After header can ask for (default 60s)").
+		Option("redirect-strip-auth", "rsa", "When to drop Authorization on redirects: cross-host (default), always or never", http.RedirectAuthModes...).
 		Flag("output-body", "ob", "If flagged it saves only the body (avoid saving headers)").
+		Flag("watch", "w", "Rerun the request every time the request or its .env files change").
+		Flag("no-clear", "nc", "Do not clear the screen between watch runs").
+		Flag("no-content-length", "ncl", "Send the body chunked instead of setting Content-Length").
+		Flag("brief", "b", "Print a one-line summary of the response").
+		Flag("ndjson", "nd", "Format the body as newline-delimited JSON regardless of its content type").
+		Flag("lenient", "l", "Accept .env keys that are not valid identifiers").
+		Flag("explain-config", "ec", "Print each config key with the .env file it came from").
+		Flag("allow-unresolved", "au", "Send the request even if its URL still contains placeholders").
+		Flag("force-print", "fp", "Print binary bodies to the terminal instead of a summary").
+		Flag("no-keepalive", "nk", "Send Connection: close and open a new connection for every request").
+		Flag("allow-get-body", "agb", "Do not warn when a GET or HEAD request has a body").
+		Flag("tee", "te", "Print the response as well when saving it with --output").
+		Flag("verbose", "vb", "Print details of the exchange, such as redirects").
+		Flag("no-retry", "nr", "Send the request once, ignoring its @retry directive").
+		Flag("compress", "gz", "Gzip the request body and send Content-Encoding: gzip").
+		Option("capture", "cp", "Save JSON fields of the response for later runs, as name=$.path[,name=$.path]").
+		Option("capture-file", "cf", "File that keeps captured values (default .rq/session.json in the dock)").
+		Option("trace-file", "tf", "Write the timeline of DNS, connect, TLS and transfer events as JSON").
+		Flag("output-append", "oa", "Append to the --output file instead of overwriting it").
+		Option("max-response-size", "mrs", "Largest response body kept in memory, like 10MB (default 256MB, 0 keeps everything)").
+		Flag("decode-jwt", "dj", "Decode JWTs found in the response headers and body, without verifying them").
+		Flag("record", "rc", "Save the response under .rq/snapshots for rq mock and docs examples").
+		Flag("report-unused-vars", "ruv", "List config variables the request never references").
+		Option("profile", "pf", "Apply a named bundle of options from the global config file (flags still win)").
 		Action(func(r *args.Result) error {
-			if len(r.Positionals) == 0 {
-				return errors.New("Missing name of the request to run")
+			if profile, ok := r.Options["profile"]; ok {
+				if err := applyProfile(app, r, profile); err != nil {
+					return err
+				}
 			}
-			name := r.Positionals[0]
 
-			options := ExecuteOptions{
-				Timeout: 30 * time.Second,
-			}
+			tag, byTag := r.Options["tag"]
+
+			var options http.ExecuteOptions
 
 			if env, ok := r.Options["env"]; ok {
 				options.Environment = env
 			}
 
+			if envFiles, ok := r.Options["env-file"]; ok {
+				for _, envFile := range strings.Split(envFiles, ",") {
+					if envFile = strings.TrimSpace(envFile); envFile != "" {
+						options.EnvFiles = append(options.EnvFiles, envFile)
+					}
+				}
+			}
+
 			if output, ok := r.Options["output"]; ok {
 				options.OutputFile = output
 			}
 			if r.Flag("output-body") {
 				options.OutputBodyOnly = true
 			}
+			options.NDJSON = r.Flag("ndjson")
+			options.Brief = r.Flag("brief")
+			options.NoContentLength = r.Flag("no-content-length")
+			options.AllowUnresolved = r.Flag("allow-unresolved")
+			options.ForcePrint = r.Flag("force-print")
+			options.NoKeepAlive = r.Flag("no-keepalive")
+			options.AllowGetBody = r.Flag("allow-get-body")
+			options.Tee = r.Flag("tee")
+			options.Verbose = r.Flag("verbose")
+			options.NoRetry = r.Flag("no-retry")
+			options.Compress = r.Flag("compress")
+			options.DecodeJWT = r.Flag("decode-jwt")
+			options.CaptureFile = r.Options["capture-file"]
+			options.OutputAppend = r.Flag("output-append")
+			options.TraceFile = r.Options["trace-file"]
+			options.ReportUnused = r.Flag("report-unused-vars")
+			options.Record = r.Flag("record")
+			if value, ok := r.Options["max-response-size"]; ok {
+				size, err := network.ParseBytes(value)
+				if err != nil {
+					return fmt.Errorf("Invalid --max-response-size: %w", err)
+				}
+				if size == 0 {
+					size = -1
+				}
+				options.MaxResponseSize = size
+			}
+			if spec, ok := r.Options["capture"]; ok {
+				captures, err := ParseCaptures(spec)
+				if err != nil {
+					return fmt.Errorf("Invalid --capture: %w", err)
+				}
+				options.Captures = captures
+			}
+			if value, ok := r.Options["retry"]; ok {
+				count, err := strconv.Atoi(value)
+				if err != nil || count < 1 {
+					return errors.New("Retry must be a positive number")
+				}
+				options.Retry.Count = count
+			}
+			if value, ok := r.Options["retry-on"]; ok {
+				codes, err := http.ParseStatusCodes(value)
+				if err != nil {
+					return fmt.Errorf("Invalid --retry-on: %w", err)
+				}
+				options.Retry.On = codes
+			}
+			if value, ok := r.Options["retry-delay"]; ok {
+				delay, err := dock.ParseTimeout(value)
+				if err != nil {
+					return fmt.Errorf("Invalid --retry-delay: %w", err)
+				}
+				options.Retry.Delay = delay
+			}
+			if value, ok := r.Options["max-retry-wait"]; ok {
+				wait, err := dock.ParseTimeout(value)
+				if err != nil {
+					return fmt.Errorf("Invalid --max-retry-wait: %w", err)
+				}
+				options.Retry.MaxWait = wait
+			}
+			if mode, ok := r.Options["redirect-strip-auth"]; ok {
+				if !slices.Contains(http.RedirectAuthModes, mode) {
+					return fmt.Errorf("Invalid --redirect-strip-auth: %s (use %s)", mode, strings.Join(http.RedirectAuthModes, ", "))
+				}
+				options.RedirectAuth = mode
+			}
+			dock.LenientKeys = r.Flag("lenient")
+			if headers, ok := r.Options["output-headers"]; ok {
+				options.HeadersFile = headers
+			}
+			options.JUnitFile = r.Options["junit"]
+			if baseURL, ok := r.Options["base-url"]; ok {
+				options.Variables = map[string]string{"BASE_URL": baseURL}
+			}
+
+			for _, name := range []string{"timeout", "max-time"} {
+				if timeout, ok := r.Options[name]; ok {
+					val, err := dock.ParseTimeout(timeout)
+					if err != nil {
+						return fmt.Errorf("Invalid --%s: %w", name, err)
+					}
+					options.Timeout = val
+				}
+			}
 
-			if timeout, ok := r.Options["timeout"]; ok {
-				val, err := strconv.Atoi(timeout)
+			if value, ok := r.Options["max-body-print"]; ok {
+				val, err := strconv.Atoi(value)
+				if err != nil || val < 0 {
+					return errors.New("Max body print must be a non-negative number")
+				}
+				options.MaxBodyPrint = val
+				if val == 0 {
+					options.MaxBodyPrint = -1
+				}
+			}
+
+			if timeout, ok := r.Options["connect-timeout"]; ok {
+				val, err := dock.ParseTimeout(timeout)
 				if err != nil {
-					return errors.New("Timeout must be a number")
+					return fmt.Errorf("Invalid --connect-timeout: %w", err)
 				}
-				options.Timeout = (time.Duration(val) * time.Second)
+				options.ConnectTimeout = val
 			}
 
-			ctx := dock.GetContext()
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+
+			interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			options.Context = interrupted
+
+			if len(r.Positionals) == 0 && !byTag {
+				name, err := defaultRequest(ctx)
+				if err != nil {
+					return err
+				}
+				r.Positionals = []string{name}
+			}
 
-			var err error
-			if options.Environment != "" || options.OutputFile != "" || options.Timeout != 30*time.Second {
-				err = EvaluateWithOptions(ctx, name, options)
-			} else {
-				err = Evaluate(ctx, name)
+			parallel := 0
+			if value, ok := r.Options["parallel"]; ok {
+				val, err := strconv.Atoi(value)
+				if err != nil || val < 1 {
+					return errors.New("Parallel must be a positive number")
+				}
+				parallel = val
+			}
+
+			if byTag {
+				names := findTaggedRequests(ctx.Dock, tag)
+				if len(names) == 0 {
+					return fmt.Errorf("No requests tagged '%s'", tag)
+				}
+				return RunMany(ctx, names, options, parallel)
+			}
+
+			if dataFile, ok := r.Options["data-file"]; ok {
+				if len(r.Positionals) != 1 {
+					return errors.New("--data-file runs exactly one request")
+				}
+				return RunData(ctx, r.Positionals[0], dataFile, options, parallel)
+			}
+
+			if envs := strings.Split(options.Environment, ","); len(envs) > 1 {
+				if len(r.Positionals) != 1 {
+					return errors.New("Several environments run exactly one request")
+				}
+				for i := range envs {
+					envs[i] = strings.TrimSpace(envs[i])
+				}
+				return RunEnvironments(ctx, r.Positionals[0], envs, options, parallel)
 			}
-			return err
+
+			if len(r.Positionals) > 1 || parallel > 0 {
+				return RunMany(ctx, r.Positionals, options, parallel)
+			}
+
+			name := r.Positionals[0]
+
+			run := func() error {
+				if r.Flag("explain-config") {
+					if err := ExplainConfig(ctx, name, options); err != nil {
+						return err
+					}
+				}
+				_, err := Execute(ctx, name, options)
+				return err
+			}
+
+			if r.Flag("watch") {
+				return Watch(ctx, name, options.Environment, !r.Flag("no-clear"), run)
+			}
+			if options.JUnitFile != "" {
+				return runWithReport(ctx, name, options, run)
+			}
+			return run()
 		})
 
 	app.Command("new", "Create a new request").
 		Positional("name").
-		Option("protocol", "p", "Set the protocol for the request", "http", "tcp").
+		Option("protocol", "p", "Set the protocol for the request", dock.Protocols...).
+		Option("envs", "es", "Also create .env.<env> stubs for these environments (comma separated)").
 		Action(func(r *args.Result) error {
 			if len(r.Positionals) == 0 {
 				return errors.New("Missing name of the request")
@@ -128,35 +378,192 @@ func Setup(app *args.Parser) {
 				protocol = r.Options["protocol"]
 			}
 
-			ctx := dock.GetContext()
-			err := New(ctx, name, protocol)
+			ctx, err := dock.GetContext()
 			if err != nil {
-				fmt.Printf("Error creating request: %v\n", err)
-				os.Exit(1)
+				return err
+			}
+			if err := New(ctx, name, protocol); err != nil {
+				return fmt.Errorf("Error creating request: %w", err)
 			}
 
 			fmt.Printf("Created request: %s.%s\n", name, protocol)
+
+			if value, ok := r.Options["envs"]; ok {
+				var envs []string
+				for _, env := range strings.Split(value, ",") {
+					if env = strings.TrimSpace(env); env != "" {
+						envs = append(envs, env)
+					}
+				}
+				created, err := environment.Scaffold(ctx, envs)
+				for _, path := range created {
+					rel, _ := filepath.Rel(ctx.Dock, path)
+					fmt.Printf("Created environment: %s\n", rel)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
 			fmt.Printf("Edit the file to customize your request\n")
 			return nil
 		})
 
+	app.Command("cp", "Copy a request to a new name").
+		Positional("source").
+		Positional("destination").
+		Flag("force", "f", "Overwrite the destination if it exists").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) < 2 {
+				return errors.New("Missing source or destination of the request")
+			}
+
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			dest, err := Copy(ctx, r.Positionals[0], r.Positionals[1], r.Flag("force"))
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Copied request to: %s\n", dest)
+			return nil
+		})
+
 	app.Command("show", "Shows the raw content to execute").
 		Positional("name").
 		Action(func(r *args.Result) error {
-			ctx := dock.GetContext()
-
-			if ctx == nil {
-				return errors.New("You're not inside a valid dock")
+			if _, err := dock.GetContext(); err != nil {
+				return err
 			}
 
 			return nil
 		})
+
+	app.Command("inspect", "Show what a request would send, without sending it").
+		Positional("name").
+		Option("env", "e", "Environment").
+		Flag("lenient", "l", "Accept .env keys that are not valid identifiers").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Missing name of the request to inspect")
+			}
+			dock.LenientKeys = r.Flag("lenient")
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			return Inspect(ctx, r.Positionals[0], r.Options["env"])
+		})
+
+	app.Command("tls", "Show the TLS handshake and certificate chain of a host, without sending a request").
+		Positional("host").
+		Option("ca", "", "Verify against the CA certificates in this PEM file instead of the system ones").
+		Option("timeout", "t", "Maximum time for the connection and handshake, in seconds or as a duration").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Missing host to inspect")
+			}
+
+			address, serverName, err := network.TLSTarget(r.Positionals[0])
+			if err != nil {
+				return err
+			}
+
+			timeout := dock.FallbackTimeout
+			if value, ok := r.Options["timeout"]; ok {
+				if timeout, err = dock.ParseTimeout(value); err != nil {
+					return fmt.Errorf("Invalid --timeout: %w", err)
+				}
+			}
+
+			var roots *x509.CertPool
+			if path, ok := r.Options["ca"]; ok {
+				if roots, err = network.LoadCertPool(path); err != nil {
+					return err
+				}
+			}
+			return network.InspectTLS(os.Stdout, address, serverName, roots, timeout)
+		})
+
+	app.Command("bench", "Send a request repeatedly and report latency percentiles").
+		Positional("name").
+		Option("env", "e", "Environment").
+		Option("requests", "n", "Number of measured requests (default 100)").
+		Option("concurrency", "c", "Requests in flight at once (default 10)").
+		Option("warmup", "w", "Requests sent before measuring, to prime DNS, TLS and connections").
+		Option("timeout", "t", "Maximum time for each request, in seconds or as a duration").
+		Action(func(r *args.Result) error {
+			if len(r.Positionals) == 0 {
+				return errors.New("Missing name of the request to benchmark")
+			}
+
+			bench := BenchOptions{Requests: 100, Concurrency: 10}
+			for name, target := range map[string]*int{"requests": &bench.Requests, "concurrency": &bench.Concurrency, "warmup": &bench.Warmup} {
+				value, ok := r.Options[name]
+				if !ok {
+					continue
+				}
+				val, err := strconv.Atoi(value)
+				if err != nil || val < 0 || (val == 0 && name != "warmup") {
+					return fmt.Errorf("Invalid --%s: %s", name, value)
+				}
+				*target = val
+			}
+
+			options := http.ExecuteOptions{Environment: r.Options["env"]}
+			if value, ok := r.Options["timeout"]; ok {
+				timeout, err := dock.ParseTimeout(value)
+				if err != nil {
+					return fmt.Errorf("Invalid --timeout: %w", err)
+				}
+				options.Timeout = timeout
+			}
+
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+
+			interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			options.Context = interrupted
+
+			return Bench(ctx, r.Positionals[0], options, bench)
+		})
+
+	app.Command("list", "List the requests of the dock, grouped and ordered by .rq/manifest when present").
+		Action(func(r *args.Result) error {
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			return List(ctx, os.Stdout)
+		})
+
+	app.Command("lint", "Check requests for common mistakes, the whole dock when no name is given").
+		Positional("name").
+		Option("env", "e", "Environment used to check variables").
+		Flag("lenient", "l", "Accept .env keys that are not valid identifiers").
+		Action(func(r *args.Result) error {
+			dock.LenientKeys = r.Flag("lenient")
+			ctx, err := dock.GetContext()
+			if err != nil {
+				return err
+			}
+			name := ""
+			if len(r.Positionals) > 0 {
+				name = r.Positionals[0]
+			}
+			return Lint(ctx, name, r.Options["env"])
+		})
 }
 
 func getRequestTemplate(protocol, name string) string {
 	switch protocol {
 	case "http":
-		return HttpTemplate(name)
+		return http.HttpTemplate(name)
 	case "ftp":
 		return FtpTemplate()
 	default:
@@ -166,54 +573,6 @@ func getRequestTemplate(protocol, name string) string {
 	}
 }
 
-func Run(ctx *dock.RqContext, path string) {
-	fmt.Printf("Searching for request: %s\n", path)
-
-	requests, err := retrieveRequests(ctx.Path, path)
-	if err != nil {
-		fmt.Printf("Error searching for requests: %v\n", err)
-		os.Exit(1)
-	}
-
-	switch len(requests) {
-	case 0:
-		fmt.Printf("Request '%s' not found\n", path)
-		fmt.Println("Available requests:")
-		showAvailableRequests(ctx.Path)
-		os.Exit(1)
-
-	case 1:
-		fmt.Printf("Executing request: %s\n", requests[0])
-		if err := Evaluate(ctx, path); err != nil {
-			fmt.Printf("Execution failed: %v\n", err)
-			os.Exit(1)
-		}
-
-	default:
-		fmt.Printf("Multiple requests found matching '%s':\n", path)
-		for i, req := range requests {
-			fmt.Printf("  %d. %s\n", i+1, req)
-		}
-		fmt.Println("Please be more specific.")
-		os.Exit(1)
-	}
-}
-
-func showAvailableRequests(basePath string) {
-	requests := findAllRequests(basePath)
-	if len(requests) == 0 {
-		fmt.Println("  No requests found in current dock")
-		fmt.Println("  Run 'rq new <name>' to create a new request")
-		return
-	}
-
-	for _, req := range requests {
-		relPath, _ := filepath.Rel(basePath, req)
-		name := strings.TrimSuffix(relPath, filepath.Ext(relPath))
-		fmt.Printf("  %s\n", name)
-	}
-}
-
 func findAllRequests(basePath string) []string {
 	var requests []string
 
@@ -223,8 +582,7 @@ func findAllRequests(basePath string) []string {
 		}
 
 		if !info.IsDir() {
-			ext := filepath.Ext(path)
-			if ext == ".http" || ext == ".tcp" {
+			if dock.IsRequestFile(path) {
 				requests = append(requests, path)
 			}
 		}
@@ -235,91 +593,134 @@ func findAllRequests(basePath string) []string {
 	return requests
 }
 
-func retrieveRequests(basePath string, reqPath string) ([]string, error) {
-	var results []string
-
-	exactPath := filepath.Join(basePath, reqPath)
+// List prints the requests of the dock by group: the groups and order of the
+// manifest first, then the remaining requests by directory.
+func List(ctx *dock.RqContext, w io.Writer) error {
+	manifest, err := dock.LoadManifest(ctx.Dock)
+	if err != nil {
+		return err
+	}
 
-	extensions := []string{".http", ".tcp"}
-	for _, ext := range extensions {
-		fullPath := exactPath + ext
-		if _, err := os.Stat(fullPath); err == nil {
-			results = append(results, fullPath)
+	groups := make(map[string][]string)
+	var order []string
+	for _, name := range RequestNames(ctx) {
+		dir := filepath.Dir(name)
+		if dir == "." {
+			dir = "Root"
 		}
+		group := manifest.Group(name, dir)
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], name)
 	}
+	manifest.SortGroups(order)
 
-	if len(results) > 0 {
-		return results, nil
-	}
+	for i, group := range order {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, network.Colorize(network.ColorBold, group))
 
-	_, err := os.ReadDir(basePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+		names := groups[group]
+		sort.SliceStable(names, func(a, b int) bool {
+			return manifest.Compare(names[a], names[b]) < 0
+		})
+		for _, name := range names {
+			fmt.Fprintf(w, "  %s\n", name)
+		}
 	}
+	return nil
+}
 
-	pathSegments := strings.Split(strings.Trim(reqPath, string(os.PathSeparator)), string(os.PathSeparator))
-	currentPath := basePath
-
-	for i, segment := range pathSegments {
-		found := false
-
-		entries, err := os.ReadDir(currentPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read directory %s: %w", currentPath, err)
+// defaultRequest picks the request to run when none is named: default or
+// index at the root of the dock, or the only request there is.
+func defaultRequest(ctx *dock.RqContext) (string, error) {
+	names := RequestNames(ctx)
+	for _, candidate := range []string{"default", "index"} {
+		if slices.Contains(names, candidate) {
+			return candidate, nil
 		}
+	}
 
-		for _, entry := range entries {
-			if i == len(pathSegments)-1 {
-				if !entry.IsDir() {
-					name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
-					if strings.HasPrefix(name, segment) {
-						fullPath := filepath.Join(currentPath, entry.Name())
-						results = append(results, fullPath)
-						found = true
-					}
-				}
-			} else {
-				if entry.IsDir() && strings.HasPrefix(entry.Name(), segment) {
-					currentPath = filepath.Join(currentPath, entry.Name())
-					found = true
-					break
-				}
-			}
-		}
+	switch len(names) {
+	case 0:
+		return "", errors.New("Missing name of the request to run, and the dock has no requests")
+	case 1:
+		return names[0], nil
+	}
+	return "", fmt.Errorf("Missing name of the request to run, available requests:\n  %s", strings.Join(names, "\n  "))
+}
 
-		if !found && i < len(pathSegments)-1 {
-			break
+func findTaggedRequests(basePath, tag string) []string {
+	var names []string
+
+	for _, req := range findAllRequests(basePath) {
+		if !docs.HasTag(req, tag) {
+			continue
 		}
+		relPath, _ := filepath.Rel(basePath, req)
+		names = append(names, strings.TrimSuffix(relPath, filepath.Ext(relPath)))
 	}
 
-	return results, nil
+	sort.Strings(names)
+	return names
 }
 
-func Evaluate(ctx *dock.RqContext, request string) error {
-	requestPath := resolveRequestPath(ctx.Dock, request)
-	if requestPath == "" {
-		return fmt.Errorf("request file not found: %s", request)
-	}
+func Evaluate(ctx *dock.RqContext, request string) (*http.HttpResponse, error) {
+	return EvaluateWithOptions(ctx, request, http.ExecuteOptions{})
+}
 
-	config, err := ctx.GetConfig(filepath.Dir(request))
+// EvaluateWithOptions sends an HTTP request of the dock and returns its
+// response without printing, saving or capturing anything, for use from Go
+// code. Requests of other protocols run as they do with Execute and return a
+// nil response. Execute runs a request the way the CLI does.
+func EvaluateWithOptions(ctx *dock.RqContext, request string, options http.ExecuteOptions) (*http.HttpResponse, error) {
+	requestPath, content, err := resolveRequest(ctx, request, &options)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return nil, err
+	}
+	if filepath.Ext(requestPath) != ".http" {
+		return nil, executeProtocol(requestPath, content, options)
 	}
 
-	setDefaultVariables(config)
+	req, err := prepareHTTP(content, options)
+	if err != nil {
+		return nil, err
+	}
+	response, err := req.ExecuteWithRetry(io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("request execution failed: %w", err)
+	}
+	return response, nil
+}
 
-	resolver := variable.NewVariableResolver(config)
-	content, err := resolver.ResolveFile(requestPath)
+// Execute runs a request, printing the response and applying the captures,
+// recording and assertions the options and the request ask for. The response
+// is nil for protocols other than HTTP. Options left unset fall back to the
+// dock config: DEFAULT_ENV picks the environment and DEFAULT_TIMEOUT the
+// timeout, so command line flags always win.
+func Execute(ctx *dock.RqContext, request string, options http.ExecuteOptions) (*http.HttpResponse, error) {
+	requestPath, content, err := resolveRequest(ctx, request, &options)
 	if err != nil {
-		return fmt.Errorf("failed to resolve variables: %w", err)
+		return nil, err
 	}
 
+	if filepath.Ext(requestPath) == ".http" {
+		return runHTTP(ctx, request, content, options)
+	}
+	return nil, executeProtocol(requestPath, content, options)
+}
+
+// executeProtocol runs a resolved request that is not HTTP, printing what it
+// receives.
+func executeProtocol(requestPath, content string, options http.ExecuteOptions) error {
 	ext := filepath.Ext(requestPath)
 	switch ext {
-	case ".http":
-		return executeHTTPRequest(content)
 	case ".tcp":
-		return executeTCPRequest(content)
+		return executeTCPRequest(content, options.Output())
+	case ".ws":
+		return executeWebSocketRequest(content, options)
 	case ".grpc":
 		return fmt.Errorf("gRPC requests not yet implemented")
 	default:
@@ -327,48 +728,202 @@ func Evaluate(ctx *dock.RqContext, request string) error {
 	}
 }
 
-func EvaluateWithOptions(ctx *dock.RqContext, request string, options ExecuteOptions) error {
+// Load resolves an HTTP request of the dock without sending or printing
+// anything, for use from Go code: call Execute on the result to send it.
+func Load(ctx *dock.RqContext, request string) (*http.HttpRequest, error) {
+	return LoadWithOptions(ctx, request, http.ExecuteOptions{})
+}
+
+// LoadWithOptions is Load with the options the CLI flags would set, such as
+// the environment and the timeout.
+func LoadWithOptions(ctx *dock.RqContext, request string, options http.ExecuteOptions) (*http.HttpRequest, error) {
+	requestPath, content, err := resolveRequest(ctx, request, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	if ext := filepath.Ext(requestPath); ext != ".http" {
+		return nil, fmt.Errorf("only HTTP requests can be loaded, got %s", ext)
+	}
+	return prepareHTTP(content, options)
+}
+
+// prepareHTTP builds the request from resolved content, leaving out the
+// assertions only Execute checks.
+func prepareHTTP(content string, options http.ExecuteOptions) (*http.HttpRequest, error) {
+	content, _, err := extractAssertions(content)
+	if err != nil {
+		return nil, err
+	}
+	return http.Prepare(content, options)
+}
+
+// resolveRequest finds the request file and resolves its variables against
+// the config, filling the options that come from the config.
+func resolveRequest(ctx *dock.RqContext, request string, options *http.ExecuteOptions) (string, string, error) {
 	requestPath := resolveRequestPath(ctx.Dock, request)
 	if requestPath == "" {
-		return fmt.Errorf("request file not found: %s", request)
+		return "", "", fmt.Errorf("request file not found: %s", request)
+	}
+
+	config, err := loadRequestConfig(ctx, request, options)
+	if err != nil {
+		return "", "", err
+	}
+
+	maps.Copy(config, options.Variables)
+	loaded := slices.Collect(maps.Keys(config))
+
+	if options.Timeout == 0 {
+		options.Timeout, err = dock.DefaultTimeout(config)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	http.SetDefaultVariables(config)
+	options.BaseURL = config["BASE_URL"]
+	options.HTTPVersion = config["HTTP_VERSION"]
+	if session != nil && options.Jar == nil {
+		options.Jar = session.Jar
 	}
 
-	var config map[string]string
-	var err error
+	raw, err := os.ReadFile(requestPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read request: %w", err)
+	}
+
+	content := string(raw)
+	if filepath.Ext(requestPath) == ".http" {
+		content, err = includeHeaders(requestPath, content)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to include headers: %w", err)
+		}
+	}
+
+	resolver := variable.NewVariableResolver(config)
+	content, err = resolver.DefineLocals(content)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve variables: %w", err)
+	}
+	content, err = resolver.Resolve(content)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve variables: %w", err)
+	}
+
+	if filepath.Ext(requestPath) == ".http" {
+		content, err = includeForm(requestPath, content, resolver.Resolve)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to load form: %w", err)
+		}
+	}
+
+	if options.ReportUnused {
+		printUnusedVariables(options.Output(), loaded, resolver.AccessedKeys())
+	}
+
+	return requestPath, content, nil
+}
+
+// printUnusedVariables lists the loaded config keys the request never read,
+// leaving out those rq reads itself.
+func printUnusedVariables(w io.Writer, loaded, accessed []string) {
+	builtin := []string{"BASE_URL", "HTTP_VERSION", dock.DefaultEnvKey, dock.DefaultTimeoutKey}
 
+	var unused []string
+	for _, key := range loaded {
+		if !slices.Contains(accessed, key) && !slices.Contains(builtin, key) {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+
+	if len(unused) == 0 {
+		fmt.Fprintln(w, "Unused variables: none")
+		return
+	}
+	fmt.Fprintf(w, "Unused variables: %s\n", strings.Join(unused, ", "))
+}
+
+// loadRequestConfig merges the config for a request, picking DEFAULT_ENV when
+// no environment was requested. Files passed with --env-file win over the
+// dock config, and values captured by earlier runs win over both.
+func loadRequestConfig(ctx *dock.RqContext, request string, options *http.ExecuteOptions) (map[string]string, error) {
+	config, err := ctx.GetConfig(filepath.Dir(request))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if options.Environment == "" {
+		options.Environment = config[dock.DefaultEnvKey]
+	}
 	if options.Environment != "" {
+		options.Environment = ctx.ResolveEnv(filepath.Dir(request), options.Environment)
 		config, err = ctx.GetConfigForEnv(filepath.Dir(request), options.Environment)
-	} else {
-		config, err = ctx.GetConfig(filepath.Dir(request))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
+	for _, envFile := range options.EnvFiles {
+		if _, err := os.Stat(envFile); err != nil {
+			return nil, fmt.Errorf("failed to load env file: %w", err)
+		}
+		values, err := dock.LoadConfigFile(envFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load env file %s: %w", envFile, err)
+		}
+		maps.Copy(config, values)
 	}
 
+	captured, err := loadCaptures(captureFile(ctx, *options))
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return nil, err
 	}
+	maps.Copy(config, captured)
 
-	setDefaultVariables(config)
+	return config, nil
+}
 
-	resolver := variable.NewVariableResolver(config)
-	content, err := resolver.ResolveFile(requestPath)
+func runHTTP(ctx *dock.RqContext, request, content string, options http.ExecuteOptions) (*http.HttpResponse, error) {
+	options.Name = request
+
+	content, assertions, err := extractAssertions(content)
 	if err != nil {
-		return fmt.Errorf("failed to resolve variables: %w", err)
+		return nil, err
 	}
 
-	ext := filepath.Ext(requestPath)[1:]
-	switch ext {
-	case "http":
-		return executeHTTPRequestWithOptions(content, options)
-	default:
-		return fmt.Errorf("unsupported request type: %s", ext)
+	response, err := http.Run(content, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if session != nil {
+		session.record(request, response)
 	}
+
+	if len(options.Captures) > 0 {
+		captured, err := saveCaptures(captureFile(ctx, options), options.Captures, response)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(options.Output(), "Captured: %s\n", strings.Join(capturedNames(captured), ", "))
+	}
+
+	if options.Record {
+		snap := snapshot.New(request, response.Method, response.URL, response.StatusCode, response.Status, response.Headers, response.Body)
+		if err := snapshot.Save(ctx.Dock, snap); err != nil {
+			fmt.Fprintf(options.Output(), "Warning: failed to record response: %v\n", err)
+		}
+	}
+
+	return response, checkAssertions(options.Output(), response, assertions)
 }
 
 func resolveRequestPath(dockPath, request string) string {
-	extensions := []string{".http", ".ws", ".grpc"}
-
 	basePath := filepath.Join(dockPath, request)
 
-	for _, ext := range extensions {
+	for _, ext := range dock.RequestExtensions() {
 		fullPath := basePath + ext
 		if _, err := os.Stat(fullPath); err == nil {
 			return fullPath
@@ -0,0 +1,874 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"rq/cli"
+	"rq/dock"
+	"rq/request/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcomit/args"
+)
+
+func TestCopy(t *testing.T) {
+	const source = "GET {{BASE_URL}}/users\n"
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		source      string
+		destination string
+		force       bool
+		want        string
+		wantContent string // Defaults to the users request
+		wantErr     string
+	}{
+		{name: "into a subdirectory", source: "users", destination: "admin/v2/users", want: "admin/v2/users.http"},
+		{name: "destination with extension", source: "users", destination: "copy.http", want: "copy.http"},
+		{name: "source with extension", source: "users.http", destination: "copy", want: "copy.http"},
+		{
+			name:        "keeps the source extension",
+			files:       map[string]string{"echo.tcp": "localhost:7\nPING\n"},
+			source:      "echo",
+			destination: "tools/echo2",
+			want:        "tools/echo2.tcp",
+			wantContent: "localhost:7\nPING\n",
+		},
+		{
+			name:        "refuses to overwrite",
+			files:       map[string]string{"copy.http": "GET /old\n"},
+			source:      "users",
+			destination: "copy",
+			wantErr:     "request file already exists: copy.http (use --force to overwrite)",
+		},
+		{
+			name:        "force overwrites",
+			files:       map[string]string{"copy.http": "GET /old\n"},
+			source:      "users",
+			destination: "copy",
+			force:       true,
+			want:        "copy.http",
+		},
+		{name: "missing source", source: "missing", destination: "copy", wantErr: "request file not found: missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"users.http": source}
+			for name, content := range tt.files {
+				files[name] = content
+			}
+			root := writeDock(t, files)
+			ctx, err := dock.ContextAt(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Copy(ctx, tt.source, tt.destination, tt.force)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Copy() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("Copy() = %q, want %q", got, tt.want)
+			}
+
+			content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(tt.want)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.wantContent
+			if want == "" {
+				want = source
+			}
+			if string(content) != want {
+				t.Errorf("copy holds %q, want %q", content, want)
+			}
+		})
+	}
+}
+
+func TestFindTaggedRequests(t *testing.T) {
+	root := writeDock(t, map[string]string{
+		"health.http":       "/// @tag smoke\nGET /health\n",
+		"users/list.http":   "/// @tags smoke, users\nGET /users\n",
+		"users/delete.http": "/// @tag users\nDELETE /users/1\n",
+		"auth/login.http":   "# @tag smoke\nPOST /login\n",
+		"echo.tcp":          "## @tag smoke\nlocalhost:7\n",
+		"notes/readme.txt":  "/// @tag smoke\n",
+		"reports/slow.http": "GET /reports\n",
+	})
+
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{tag: "smoke", want: []string{"echo", "health", filepath.Join("users", "list")}},
+		{tag: "users", want: []string{filepath.Join("users", "delete"), filepath.Join("users", "list")}},
+		{tag: "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got := findTaggedRequests(root, tt.tag)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findTaggedRequests(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	root := writeDock(t, map[string]string{
+		".env":         "BASE_URL=http://root.test\nDEFAULT_ENV=staging\nDEFAULT_TIMEOUT=10\n",
+		".env.staging": "BASE_URL=http://staging.test\n",
+		".env.prod":    "BASE_URL=http://prod.test\nDEFAULT_TIMEOUT=2m\n",
+		"users.http":   "GET {{BASE_URL}}/users\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		options     http.ExecuteOptions
+		wantURL     string
+		wantTimeout time.Duration
+	}{
+		{name: "config defaults", wantURL: "http://staging.test/users", wantTimeout: 10 * time.Second},
+		{name: "flags win", options: http.ExecuteOptions{Environment: "prod", Timeout: 3 * time.Second}, wantURL: "http://prod.test/users", wantTimeout: 3 * time.Second},
+		{name: "environment default timeout", options: http.ExecuteOptions{Environment: "prod"}, wantURL: "http://prod.test/users", wantTimeout: 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := LoadWithOptions(ctx, "users", tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.URL != tt.wantURL || req.Timeout != tt.wantTimeout {
+				t.Errorf("LoadWithOptions() = %s with timeout %v, want %s with %v", req.URL, req.Timeout, tt.wantURL, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestLoadInvalidDefaultTimeout(t *testing.T) {
+	root := writeDock(t, map[string]string{
+		".env":       "DEFAULT_TIMEOUT=soon\n",
+		"users.http": "GET http://localhost/users\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(ctx, "users"); err == nil || !strings.Contains(err.Error(), "invalid DEFAULT_TIMEOUT") {
+		t.Errorf("Load() error = %v, want invalid DEFAULT_TIMEOUT", err)
+	}
+	if _, err := LoadWithOptions(ctx, "users", http.ExecuteOptions{Timeout: time.Second}); err != nil {
+		t.Errorf("LoadWithOptions() with --timeout error = %v, want the flag to skip the config value", err)
+	}
+}
+
+func TestEnvironmentBaseURL(t *testing.T) {
+	hosts := make(map[string]string)
+	for _, name := range []string{"root", "staging", "local"} {
+		server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			fmt.Fprintf(w, "%s %s", name, r.URL.Path)
+		}))
+		defer server.Close()
+		hosts[name] = server.URL
+	}
+
+	root := writeDock(t, map[string]string{
+		".env":            "BASE_URL=" + hosts["root"] + "\n",
+		".env.staging":    "BASE_URL=" + hosts["staging"] + "\n",
+		"users/list.http": "GET /users\n",
+		"health.http":     "GET /health\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		request string
+		env     string
+		local   bool
+		want    string
+	}{
+		{name: "root", request: "health", want: "root /health"},
+		{name: "staging", request: "health", env: "staging", want: "staging /health"},
+		{name: "staging in a subdirectory", request: "users/list", env: "staging", want: "staging /users"},
+		{name: "local override of staging", request: "users/list", env: "staging", local: true, want: "local /users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.local {
+				writeFile(t, filepath.Join(root, ".env.staging.local"), "BASE_URL="+hosts["local"]+"\n")
+				defer os.Remove(filepath.Join(root, ".env.staging.local"))
+			}
+
+			response, err := EvaluateWithOptions(ctx, tt.request, http.ExecuteOptions{Environment: tt.env})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(response.Body) != tt.want {
+				t.Errorf("response = %q, want %q", response.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRequestsAreDiscovered(t *testing.T) {
+	root := writeDock(t, map[string]string{})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, protocol string
+		want           string
+	}{
+		{name: "users", protocol: "", want: "users.http"},
+		{name: "echo", protocol: "tcp", want: "echo.tcp"},
+		{name: "chat/room", protocol: "websocket", want: "chat/room.ws"},
+		{name: "billing/invoices", protocol: "grpc", want: "billing/invoices.grpc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if err := New(ctx, tt.name, tt.protocol); err != nil {
+				t.Fatal(err)
+			}
+			want := filepath.Join(root, filepath.FromSlash(tt.want))
+
+			if !slices.Contains(findAllRequests(root), want) {
+				t.Errorf("findAllRequests() does not list %s", tt.want)
+			}
+			if got := resolveRequestPath(root, tt.name); got != want {
+				t.Errorf("resolveRequestPath(%q) = %q, want %q", tt.name, got, want)
+			}
+		})
+	}
+
+	if err := New(ctx, "users", "http"); err == nil {
+		t.Error("New() over an existing request succeeded, want error")
+	}
+	if err := New(ctx, "legacy", "ftp"); err == nil {
+		t.Error("New() with an unsupported protocol succeeded, want error")
+	}
+}
+
+func TestLoadRequestConfigEnvFiles(t *testing.T) {
+	root := writeDock(t, map[string]string{
+		".env":         "A=dock\nB=dock\nC=dock\n",
+		".env.staging": "C=staging\n",
+		"health.http":  "GET /health\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ci.env"), "A=ci\nD=ci\n")
+	writeFile(t, filepath.Join(dir, "second.env"), "A=second\n")
+	writeFile(t, filepath.Join(dir, "broken.env"), "NOT A PAIR\n")
+
+	tests := []struct {
+		name     string
+		env      string
+		envFiles []string
+		want     map[string]string
+		wantErr  string
+	}{
+		{name: "dock only", want: map[string]string{"A": "dock", "B": "dock", "C": "dock"}},
+		{name: "env file wins", envFiles: []string{"ci.env"}, want: map[string]string{"A": "ci", "B": "dock", "C": "dock", "D": "ci"}},
+		{name: "applied in order", envFiles: []string{"ci.env", "second.env"}, want: map[string]string{"A": "second", "B": "dock", "C": "dock", "D": "ci"}},
+		{name: "over an environment", env: "staging", envFiles: []string{"ci.env"}, want: map[string]string{"A": "ci", "B": "dock", "C": "staging", "D": "ci"}},
+		{name: "missing file", envFiles: []string{"missing.env"}, wantErr: "failed to load env file"},
+		{name: "invalid file", envFiles: []string{"broken.env"}, wantErr: "failed to load env file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := http.ExecuteOptions{Environment: tt.env}
+			for _, envFile := range tt.envFiles {
+				options.EnvFiles = append(options.EnvFiles, filepath.Join(dir, envFile))
+			}
+
+			got, err := loadRequestConfig(ctx, "health", &options)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("loadRequestConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("loadRequestConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTimeoutFlag(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env":        "BASE_URL=" + server.URL + "\n",
+		"health.http": "GET /health\n",
+	})
+	t.Chdir(root)
+
+	tests := []struct {
+		name    string
+		flags   []string
+		wantErr string
+	}{
+		{name: "seconds", flags: []string{"--timeout", "30"}},
+		{name: "duration", flags: []string{"--timeout", "1m30s"}},
+		{name: "milliseconds abort the request", flags: []string{"--timeout", "50ms"}, wantErr: "request timeout after 50ms"},
+		{name: "max-time alias", flags: []string{"--max-time", "50ms"}, wantErr: "request timeout after 50ms"},
+		{name: "invalid", flags: []string{"--timeout", "soon"}, wantErr: "Invalid --timeout: 'soon' is not a positive number of seconds or a duration like 500ms or 1m30s"},
+		{name: "zero", flags: []string{"--timeout", "0"}, wantErr: "Invalid --timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			captureStdout(t, func() {
+				err = app.Run(append([]string{"run", "health", "--no-retry"}, tt.flags...))
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("run error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunBaseURLFlag(t *testing.T) {
+	hosts := make(map[string]string)
+	for _, name := range []string{"dock", "override", "fixed"} {
+		server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			fmt.Fprintf(w, "%s %s", name, r.URL.Path)
+		}))
+		defer server.Close()
+		hosts[name] = server.URL
+	}
+
+	root := writeDock(t, map[string]string{
+		".env":          "BASE_URL=" + hosts["dock"] + "\n",
+		"relative.http": "GET /users\n",
+		"template.http": "GET {{BASE_URL}}/users\n",
+		"absolute.http": "GET " + hosts["fixed"] + "/users\n",
+	})
+	envFile := filepath.Join(t.TempDir(), "ci.env")
+	writeFile(t, envFile, "BASE_URL=http://ci.invalid\n")
+	t.Chdir(root)
+
+	tests := []struct {
+		name    string
+		request string
+		flags   []string
+		want    string
+	}{
+		{name: "dock base url", request: "relative", want: "dock /users"},
+		{name: "relative url", request: "relative", flags: []string{"--base-url", hosts["override"]}, want: "override /users"},
+		{name: "BASE_URL placeholder", request: "template", flags: []string{"--base-url", hosts["override"]}, want: "override /users"},
+		{name: "wins over --env-file", request: "template", flags: []string{"--env-file", envFile, "--base-url", hosts["override"]}, want: "override /users"},
+		{name: "absolute url is kept", request: "absolute", flags: []string{"--base-url", hosts["override"]}, want: "fixed /users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = app.Run(append([]string{"run", tt.request}, tt.flags...))
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := writeDock(t, map[string]string{
+		".env":              "BASE_URL=http://api.test\nTOKEN=abc\n",
+		"users/create.http": "@assert status == 201\nPOST /users\nAuthorization: Bearer {{TOKEN}}\n\n{\"name\":\"ada\"}\n",
+		"ping.tcp":          "localhost:7\nhello\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		request    string
+		wantMethod string
+		wantURL    string
+		wantErr    string
+	}{
+		{name: "http", request: "users/create", wantMethod: "POST", wantURL: "http://api.test/users"},
+		{name: "other protocol", request: "ping", wantErr: "only HTTP requests can be loaded, got .tcp"},
+		{name: "missing", request: "nope", wantErr: "request file not found: nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := Load(ctx, tt.request)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Method != tt.wantMethod || req.URL != tt.wantURL {
+				t.Errorf("Load() = %s %s, want %s %s", req.Method, req.URL, tt.wantMethod, tt.wantURL)
+			}
+			if req.Headers["Authorization"] != "Bearer abc" || strings.TrimSpace(req.Body) != `{"name":"ada"}` {
+				t.Errorf("Load() headers = %v, body = %q", req.Headers, req.Body)
+			}
+		})
+	}
+}
+
+func TestEvaluatePrintsNothing(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(nethttp.StatusCreated)
+		fmt.Fprint(w, `{"id":7}`)
+	}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env":        "BASE_URL=" + server.URL + "\n",
+		"create.http": "@assert status == 200\nPOST /users\n\n{}\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response *http.HttpResponse
+	out := captureStdout(t, func() {
+		response, err = Evaluate(ctx, "create")
+	})
+	if err != nil {
+		t.Fatalf("Evaluate() should not check assertions, got %v", err)
+	}
+	if out != "" {
+		t.Errorf("Evaluate() printed %q", out)
+	}
+	if response.StatusCode != nethttp.StatusCreated || string(response.Body) != `{"id":7}` || response.Header("X-Method")[0] != "POST" {
+		t.Errorf("Evaluate() = %d %s %v", response.StatusCode, response.Body, response.Headers)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".rq")); !os.IsNotExist(err) {
+		t.Errorf("Evaluate() should not record or capture anything, .rq: %v", err)
+	}
+}
+
+func TestEvaluateOtherProtocols(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 64)
+		n, _ := conn.Read(buf)
+		conn.Write(bytes.ToUpper(buf[:n]))
+	}()
+
+	root := writeDock(t, map[string]string{
+		".env":         "HOST=" + ln.Addr().String() + "\n",
+		"ping.tcp":     "{{HOST}}\nping\n",
+		"service.grpc": "localhost:50051\n",
+	})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		request string
+		want    string
+		wantErr string
+	}{
+		{request: "ping", want: "PING"},
+		{request: "service", wantErr: "gRPC requests not yet implemented"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.request, func(t *testing.T) {
+			var out strings.Builder
+			response, err := EvaluateWithOptions(ctx, tt.request, http.ExecuteOptions{Writer: &out})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("EvaluateWithOptions() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response != nil {
+				t.Errorf("EvaluateWithOptions() = %+v, want no HTTP response", response)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestNewWithEnvs(t *testing.T) {
+	root := writeDock(t, map[string]string{".env.staging": "BASE_URL=https://staging.test\n"})
+	t.Chdir(root)
+
+	app := args.New("rq")
+	Setup(app)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Run([]string{"new", "users", "--envs", "dev, staging,prod"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"users.http", ".env.dev", ".env.prod"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+		}
+	}
+	if staging, _ := os.ReadFile(filepath.Join(root, ".env.staging")); string(staging) != "BASE_URL=https://staging.test\n" {
+		t.Errorf(".env.staging was overwritten: %q", staging)
+	}
+	for _, want := range []string{"Created request: users.http", "Created environment: .env.dev", "Created environment: .env.prod"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Created environment: .env.staging") {
+		t.Errorf("output reports the existing .env.staging:\n%s", out)
+	}
+}
+
+func TestDefaultRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr string
+	}{
+		{name: "only request", files: map[string]string{"users/list.http": "GET /users\n"}, want: "users/list"},
+		{name: "default.http", files: map[string]string{"default.http": "GET /\n", "index.http": "GET /\n", "users.http": "GET /users\n"}, want: "default"},
+		{name: "index.http", files: map[string]string{"index.http": "GET /\n", "users.http": "GET /users\n"}, want: "index"},
+		{name: "no requests", files: map[string]string{}, wantErr: "Missing name of the request to run, and the dock has no requests"},
+		{
+			name:    "several requests",
+			files:   map[string]string{"users.http": "GET /users\n", "orders.http": "GET /orders\n"},
+			wantErr: "Missing name of the request to run, available requests:\n  orders\n  users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := dock.ContextAt(writeDock(t, tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := defaultRequest(ctx)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("defaultRequest() error = %q, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("defaultRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWithoutName(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		fmt.Fprintf(w, "served %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{name: "only request", files: map[string]string{"health.http": "GET {{BASE_URL}}/health\n"}, want: "served /health"},
+		{name: "default request", files: map[string]string{"default.http": "GET {{BASE_URL}}/\n", "health.http": "GET {{BASE_URL}}/health\n"}, want: "served /\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.files[".env"] = "BASE_URL=" + server.URL + "\n"
+			t.Chdir(writeDock(t, tt.files))
+
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = app.Run([]string{"run"})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestRunQueryLines(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		got = r.URL.Query()
+	}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env":        "BASE_URL=" + server.URL + "\nTERM=rock & roll\nTAG=go\n",
+		"search.http": "GET {{BASE_URL}}/search?page=2\n?q={{TERM}}\n&tag=${TAG}\n&tag=http\n",
+	})
+	t.Chdir(root)
+
+	app := args.New("rq")
+	Setup(app)
+	var err error
+	captureStdout(t, func() {
+		err = app.Run([]string{"run", "search"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := url.Values{"page": {"2"}, "q": {"rock & roll"}, "tag": {"go", "http"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("server got query %v, want %v", got, want)
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	files := func() map[string]string {
+		return map[string]string{
+			"health.http":       "GET /health\n",
+			"login.http":        "POST /login\n",
+			"users/list.http":   "GET /users\n",
+			"users/create.http": "POST /users\n",
+			"orders/list.http":  "GET /orders\n",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+		wantErr  string
+	}{
+		{
+			name: "by directory",
+			want: "Root\n  health\n  login\n\norders\n  orders/list\n\nusers\n  users/create\n  users/list\n",
+		},
+		{
+			name:     "manifest overrides the default order",
+			manifest: "[Users]\nusers/list\nusers/create\n[Authentication]\nlogin\n",
+			want:     "Users\n  users/list\n  users/create\n\nAuthentication\n  login\n\nRoot\n  health\n\norders\n  orders/list\n",
+		},
+		{
+			name:     "manifest without groups",
+			manifest: "users/list\nhealth\n",
+			want:     "Root\n  health\n  login\n\norders\n  orders/list\n\nusers\n  users/list\n  users/create\n",
+		},
+		{name: "invalid manifest", manifest: "[Users\n", wantErr: "invalid group title [Users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockFiles := files()
+			if tt.manifest != "" {
+				dockFiles[".rq/manifest"] = tt.manifest
+			}
+			ctx, err := dock.ContextAt(writeDock(t, dockFiles))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out strings.Builder
+			err = List(ctx, &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("List() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("List() printed\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFromOutsideTheDock(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		fmt.Fprintf(w, "served %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	root := writeDock(t, map[string]string{
+		".env":            "BASE_URL=" + server.URL + "\n",
+		"users/list.http": "GET {{BASE_URL}}/users\n",
+	})
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		name      string
+		arguments []string
+		want      string
+		wantErr   string
+	}{
+		{name: "--dock", arguments: []string{"--dock", root, "run", "users/list"}, want: "served /users"},
+		{name: "--dock=", arguments: []string{"run", "users/list", "--dock=" + root}, want: "served /users"},
+		{name: "without --dock", arguments: []string{"run", "users/list"}, wantErr: "not inside an rq dock"},
+		{name: "not a dock", arguments: []string{"--dock", filepath.Dir(root), "run", "users/list"}, wantErr: "not a dock (missing .dock file)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = cli.Run(app, tt.arguments)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestRunReportUnusedVars(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {}))
+	defer server.Close()
+
+	t.Chdir(writeDock(t, map[string]string{
+		".env":       "BASE_URL=" + server.URL + "\nDEFAULT_TIMEOUT=5s\nTOKEN=secret\nUSER=ada\nLEGACY_KEY=old\n",
+		"me.http":    "GET {{BASE_URL}}/me\nAuthorization: Bearer {{ TOKEN }}\n",
+		"all.http":   "@set AUTH = {{ base64(join(USER, TOKEN, ':')) }}\nGET {{BASE_URL}}/users/${LEGACY_KEY}\nAuthorization: Basic {{AUTH}}\n",
+		"plain.http": "GET " + server.URL + "/health\n",
+	}))
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "unused keys", args: []string{"run", "me", "--report-unused-vars"}, want: "Unused variables: LEGACY_KEY, USER\n"},
+		{name: "every key used", args: []string{"run", "all", "--report-unused-vars"}, want: "Unused variables: none\n"},
+		{name: "no placeholders", args: []string{"run", "plain", "--report-unused-vars"}, want: "Unused variables: LEGACY_KEY, TOKEN, USER\n"},
+		{name: "without the flag", args: []string{"run", "me"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := args.New("rq")
+			Setup(app)
+
+			var err error
+			out := captureStdout(t, func() {
+				err = app.Run(tt.args)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" && strings.Contains(out, "Unused variables") {
+				t.Errorf("output reports unused variables without the flag:\n%s", out)
+			}
+			if tt.want != "" && !strings.Contains(out, tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	"maps"
+	"net/http/cookiejar"
+	"path/filepath"
+	"rq/dock"
+	"rq/request/http"
+	"rq/variable"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Session keeps the cookies and responses of every request run while it is
+// active, so that an interactive session behaves like a single client.
+type Session struct {
+	Jar *cookiejar.Jar
+
+	mu        sync.Mutex
+	responses map[string]*http.HttpResponse
+	last      string
+}
+
+var session *Session
+
+// StartSession makes every following request share one cookie jar and
+// record its response in the returned session.
+func StartSession() (*Session, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	session = &Session{
+		Jar:       jar,
+		responses: make(map[string]*http.HttpResponse),
+	}
+	return session, nil
+}
+
+// EndSession stops sharing state between requests.
+func EndSession() {
+	session = nil
+}
+
+func (s *Session) record(name string, response *http.HttpResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[name] = response
+	s.last = name
+}
+
+// Response returns the latest response of the named request. An empty name
+// returns the last response of the session.
+func (s *Session) Response(name string) (*http.HttpResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name == "" {
+		name = s.last
+	}
+	response, ok := s.responses[name]
+	return response, ok
+}
+
+// Names lists the requests that have a stored response.
+func (s *Session) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.responses))
+	for name := range s.responses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InlineName is the name responses of ad-hoc requests are stored under.
+const InlineName = "inline"
+
+// RunInline sends a request written directly on the prompt, such as
+// "GET {{BASE_URL}}/users", using the dock root config.
+func RunInline(ctx *dock.RqContext, content string, options http.ExecuteOptions) error {
+	config, err := loadRequestConfig(ctx, "", &options)
+	if err != nil {
+		return err
+	}
+
+	maps.Copy(config, options.Variables)
+
+	if options.Timeout == 0 {
+		options.Timeout, err = dock.DefaultTimeout(config)
+		if err != nil {
+			return err
+		}
+	}
+
+	http.SetDefaultVariables(config)
+	options.BaseURL = config["BASE_URL"]
+	options.HTTPVersion = config["HTTP_VERSION"]
+	options.Name = InlineName
+
+	content, err = variable.NewVariableResolver(config).Resolve(strings.ReplaceAll(content, `\n`, "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to resolve variables: %w", err)
+	}
+
+	if session != nil && options.Jar == nil {
+		options.Jar = session.Jar
+	}
+
+	response, err := http.Run(content, options)
+	if err != nil {
+		return err
+	}
+
+	if session != nil {
+		session.record(InlineName, response)
+	}
+	return nil
+}
+
+// RequestNames lists the requests of the dock without their extension.
+func RequestNames(ctx *dock.RqContext) []string {
+	var names []string
+	for _, req := range findAllRequests(ctx.Dock) {
+		relPath, err := filepath.Rel(ctx.Dock, req)
+		if err != nil {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(relPath, filepath.Ext(relPath)))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -4,27 +4,111 @@
 package request
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"rq/request/network"
 	"strings"
+	"time"
 )
 
 var EMPTY_TCP_MESSAGE = fmt.Errorf("The request should contain at least one line (the connection url)")
-var SOCKET_CONNECTION_REFUSED = fmt.Errorf("Connection refused")
+var SOCKET_CONNECTION_REFUSED = network.ErrConnectionRefused
+var SOCKET_HOST_NOT_FOUND = network.ErrHostNotFound
+var SOCKET_TIMEOUT = network.ErrTimeout
+var SOCKET_INVALID_ADDRESS = network.ErrInvalidAddress
 
-func executeTCPRequest(content string) error {
+const tcpDialTimeout = 10 * time.Second
+
+// tcpIdleTimeout ends the read once the server has been quiet this long,
+// since raw TCP has no notion of a complete response.
+const tcpIdleTimeout = 2 * time.Second
+
+// rawDirective, on the line after the address, sends the payload exactly as
+// written, for protocols where '#' lines or blank lines are significant.
+const rawDirective = "@raw"
+
+// parseTCPRequest returns the address, the first line that is not blank or
+// a comment, and the payload. Comment and blank lines of the payload are not
+// sent unless it starts with @raw; a line starting with `\#` sends a literal
+// '#'.
+func parseTCPRequest(content string) (string, []byte) {
 	lines := strings.Split(content, "\n")
 
-	if len(lines) == 0 {
+	start := 0
+	for start < len(lines) && isCommentOrBlank(lines[start]) {
+		start++
+	}
+	if start == len(lines) {
+		return "", nil
+	}
+
+	address := strings.TrimSpace(lines[start])
+	rest := lines[start+1:]
+
+	if len(rest) > 0 && strings.TrimSpace(rest[0]) == rawDirective {
+		return address, []byte(strings.Join(rest[1:], "\n"))
+	}
+
+	var kept []string
+	for _, line := range rest {
+		if isCommentOrBlank(line) {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(line, " \t"), `\#`) {
+			line = strings.Replace(line, `\#`, "#", 1)
+		}
+		kept = append(kept, line)
+	}
+	return address, []byte(strings.Join(kept, "\n"))
+}
+
+func isCommentOrBlank(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// executeTCPRequest connects to the address on the first line, sends the
+// remaining lines as the payload and prints whatever the server replies.
+func executeTCPRequest(content string, w io.Writer) error {
+	address, payload := parseTCPRequest(content)
+	if address == "" {
 		return EMPTY_TCP_MESSAGE
 	}
 
-	conn, err := net.Dial("tcp", lines[0])
+	conn, err := net.DialTimeout("tcp", address, tcpDialTimeout)
 
 	if err != nil {
-		return SOCKET_CONNECTION_REFUSED
+		return network.FormatError(err, tcpDialTimeout)
 	}
 	defer conn.Close()
 
+	fmt.Fprintf(w, "Connected to %s\n", address)
+
+	if len(strings.TrimSpace(string(payload))) > 0 {
+		if _, err := conn.Write(payload); err != nil {
+			return network.FormatError(err, tcpDialTimeout)
+		}
+		network.PrintMessage(w, "Sent", payload)
+	}
+
+	var received bytes.Buffer
+	buf := make([]byte, 4096)
+	for {
+		conn.SetReadDeadline(time.Now().Add(tcpIdleTimeout))
+		n, err := conn.Read(buf)
+		received.Write(buf[:n])
+		if err != nil {
+			var netErr net.Error
+			if err == io.EOF || (errors.As(err, &netErr) && netErr.Timeout()) {
+				break
+			}
+			return network.FormatError(err, tcpIdleTimeout)
+		}
+	}
+
+	network.PrintMessage(w, "Received", received.Bytes())
 	return nil
 }
@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"rq/dock"
+	"rq/request/http"
+	"rq/request/network"
+	"strings"
+	"testing"
+	"time"
+)
+
+// closedPort returns an address nothing is listening on.
+func closedPort(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+	return address
+}
+
+func TestParseTCPRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantAddress string
+		wantPayload string
+	}{
+		{name: "empty", content: ""},
+		{name: "whitespace only", content: "  \n\t\n   "},
+		{name: "comments only", content: "# echo server\n\n# nothing else"},
+		{name: "address only", content: "localhost:7\n", wantAddress: "localhost:7"},
+		{
+			name:        "payload skips comments and blank lines",
+			content:     "# echo\n  localhost:7  \nPING\n\n# not sent\n\\# sent\n",
+			wantAddress: "localhost:7",
+			wantPayload: "PING\n# sent",
+		},
+		{
+			name:        "raw payload is sent as written",
+			content:     "localhost:7\n@raw\n# kept\n\nline",
+			wantAddress: "localhost:7",
+			wantPayload: "# kept\n\nline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, payload := parseTCPRequest(tt.content)
+			if address != tt.wantAddress || string(payload) != tt.wantPayload {
+				t.Errorf("parseTCPRequest() = %q, %q, want %q, %q", address, payload, tt.wantAddress, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestExecuteTCPRequestEmpty(t *testing.T) {
+	for _, content := range []string{"", "   ", "\n\n", " \t\n  \n", "# only a comment\n"} {
+		if err := executeTCPRequest(content, io.Discard); !errors.Is(err, EMPTY_TCP_MESSAGE) {
+			t.Errorf("executeTCPRequest(%q) error = %v, want EMPTY_TCP_MESSAGE", content, err)
+		}
+	}
+}
+
+func TestExecuteTCPRequestDialErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		want    error
+	}{
+		{name: "closed port", address: closedPort(t), want: SOCKET_CONNECTION_REFUSED},
+		{name: "unresolvable host", address: "rq-test.invalid:80", want: SOCKET_HOST_NOT_FOUND},
+		{name: "missing port", address: "127.0.0.1", want: SOCKET_INVALID_ADDRESS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executeTCPRequest(tt.address+"\nPING\n", io.Discard)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("executeTCPRequest() error = %v, want %v", err, tt.want)
+			}
+			for _, other := range []error{SOCKET_CONNECTION_REFUSED, SOCKET_HOST_NOT_FOUND, SOCKET_INVALID_ADDRESS, network.ErrTimeout} {
+				if other != tt.want && errors.Is(err, other) {
+					t.Errorf("executeTCPRequest() error = %v, also matches %v", err, other)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteTCPRequestEcho(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 64)
+		n, _ := conn.Read(buf)
+		conn.Write(bytes.ToUpper(buf[:n]))
+	}()
+
+	var out bytes.Buffer
+	if err := executeTCPRequest(ln.Addr().String()+"\nping", &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "PING") {
+		t.Errorf("output does not hold the reply:\n%s", out.String())
+	}
+}
+
+func TestNetworkErrorsMatchAcrossProtocols(t *testing.T) {
+	address := closedPort(t)
+	want := executeTCPRequest(address+"\n", io.Discard)
+	if !errors.Is(want, network.ErrConnectionRefused) {
+		t.Fatalf("tcp error = %v, want connection refused", want)
+	}
+
+	ctx, err := dock.ContextAt(writeDock(t, map[string]string{"closed.http": "GET http://" + address + "/\n"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, httpErr := Execute(ctx, "closed", http.ExecuteOptions{Writer: io.Discard, NoRetry: true})
+	wsErr := executeWebSocketRequest("ws://"+address+"/\n", http.ExecuteOptions{Writer: io.Discard})
+
+	for protocol, err := range map[string]error{"http": httpErr, "websocket": wsErr} {
+		if !errors.Is(err, network.ErrConnectionRefused) || !strings.HasSuffix(err.Error(), want.Error()) {
+			t.Errorf("%s error = %v, want the tcp wording %q", protocol, err, want)
+		}
+	}
+}
+
+func TestExecuteTCPRequestFormatsJSON(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Read(make([]byte, 64))
+		conn.Write([]byte(`{"status":"ok","count":2}` + "\nbye\n"))
+	}()
+
+	var out bytes.Buffer
+	if err := executeTCPRequest(ln.Addr().String()+"\n{\"cmd\":\"stats\"}", &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Connected to " + ln.Addr().String() + "\n" +
+		"Sent (15 B)\n{\n  \"cmd\": \"stats\"\n}\n" +
+		"Received (30 B)\n{\n  \"status\": \"ok\",\n  \"count\": 2\n}\nbye\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestExecuteTCPRequestSkipsComments(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{name: "comments and blank lines", payload: "# greet the server\nHELO rq\n\n# then leave\nQUIT", want: "HELO rq\nQUIT"},
+		{name: "escaped hash", payload: "\\#channel\nPING", want: "#channel\nPING"},
+		{name: "raw", payload: "@raw\n# sent as is\n\nPING", want: "# sent as is\n\nPING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+
+			received := make(chan string, 1)
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+				data, _ := io.ReadAll(conn)
+				received <- string(data)
+			}()
+
+			content := "# local echo server\n" + ln.Addr().String() + "\n" + tt.payload
+			if err := executeTCPRequest(content, io.Discard); err != nil {
+				t.Fatal(err)
+			}
+			if got := <-received; got != tt.want {
+				t.Errorf("server received %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"rq/dock"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchDebounce = 300 * time.Millisecond
+
+// watchedFiles returns the request file and every .env file that can
+// contribute to its configuration.
+func watchedFiles(ctx *dock.RqContext, request, env string) ([]string, error) {
+	requestPath := resolveRequestPath(ctx.Dock, request)
+	if requestPath == "" {
+		return nil, fmt.Errorf("request file not found: %s", request)
+	}
+
+	return append([]string{requestPath}, ctx.ConfigFiles(filepath.Dir(request), env)...), nil
+}
+
+// Watch runs the request and then reruns it every time the request file or
+// one of its .env files is saved, until interrupted.
+func Watch(ctx *dock.RqContext, request, env string, clearScreen bool, run func() error) error {
+	files, err := watchedFiles(ctx, request, env)
+	if err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	return watchFiles(files, clearScreen, run, interrupt)
+}
+
+// watchFiles runs run once and again after every debounced change to files,
+// returning when stop receives.
+func watchFiles(files []string, clearScreen bool, run func() error, stop <-chan os.Signal) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Directories are watched instead of files so editors that save by
+	// renaming a temporary file are still detected.
+	watched := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		watched[filepath.Clean(file)] = true
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		dirs[dir] = true
+	}
+
+	execute := func() {
+		if clearScreen {
+			fmt.Print("\033[H\033[2J")
+		}
+		if err := run(); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Printf("\nWatching %d files for changes (Ctrl-C to stop)...\n", len(watched))
+	}
+
+	execute()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !watched[filepath.Clean(event.Name)] {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			debounce = time.After(watchDebounce)
+
+		case <-debounce:
+			debounce = nil
+			execute()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("Watch error: %v\n", err)
+
+		case <-stop:
+			fmt.Println("\nStopped watching")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"os"
+	"path/filepath"
+	"rq/dock"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeDock creates a dock at a temporary root holding files, keyed by
+// slash-separated relative path.
+func writeDock(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	files[".dock"] = "test"
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestWatchedFiles(t *testing.T) {
+	root := writeDock(t, map[string]string{"users/list.http": "GET http://localhost\n"})
+	ctx, err := dock.ContextAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := watchedFiles(ctx, "users/list", "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(root, "users", "list.http"),
+		filepath.Join(root, ".env"),
+		filepath.Join(root, "users", ".env.staging"),
+		filepath.Join(root, "users", ".env.staging.local"),
+	}
+	for _, file := range want {
+		if !slices.Contains(files, file) {
+			t.Errorf("watchedFiles() = %v, missing %s", files, file)
+		}
+	}
+
+	if _, err := watchedFiles(ctx, "missing", ""); err == nil {
+		t.Error("watchedFiles() of a missing request succeeded, want error")
+	}
+}
+
+func TestWatchFilesReruns(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, dir string)
+		reruns int
+	}{
+		{
+			name: "saving the request",
+			change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "list.http"), "GET http://localhost/v2\n")
+			},
+			reruns: 1,
+		},
+		{
+			name: "rapid saves are debounced",
+			change: func(t *testing.T, dir string) {
+				for range 3 {
+					writeFile(t, filepath.Join(dir, ".env"), "BASE_URL=x\n")
+				}
+			},
+			reruns: 1,
+		},
+		{
+			name: "save by rename",
+			change: func(t *testing.T, dir string) {
+				tmp := filepath.Join(dir, "list.http.swp")
+				writeFile(t, tmp, "GET http://localhost/v3\n")
+				if err := os.Rename(tmp, filepath.Join(dir, "list.http")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			reruns: 1,
+		},
+		{
+			name: "unwatched file",
+			change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "notes.txt"), "todo\n")
+			},
+			reruns: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "list.http"), "GET http://localhost\n")
+			files := []string{filepath.Join(dir, "list.http"), filepath.Join(dir, ".env")}
+
+			runs := make(chan struct{}, 10)
+			stop := make(chan os.Signal, 1)
+			done := make(chan error, 1)
+			go func() {
+				done <- watchFiles(files, false, func() error {
+					runs <- struct{}{}
+					return nil
+				}, stop)
+			}()
+
+			select {
+			case <-runs:
+			case <-time.After(2 * time.Second):
+				t.Fatal("request did not run on start")
+			}
+
+			tt.change(t, dir)
+
+			reruns := 0
+			timeout := time.After(4 * watchDebounce)
+		wait:
+			for {
+				select {
+				case <-runs:
+					reruns++
+				case <-timeout:
+					break wait
+				}
+			}
+			if reruns != tt.reruns {
+				t.Errorf("reran %d times, want %d", reruns, tt.reruns)
+			}
+
+			stop <- os.Interrupt
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("watch did not stop")
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
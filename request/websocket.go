@@ -0,0 +1,413 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"rq/request/http"
+	"rq/request/network"
+	"rq/version"
+	"strings"
+	"sync"
+	"time"
+)
+
+var EMPTY_WS_MESSAGE = fmt.Errorf("The request should contain at least one line (the ws:// or wss:// url)")
+
+// WebSocket opcodes, RFC 6455 section 5.2.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+const (
+	wsAcceptGUID    = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxMessage    = 64 << 20
+	wsPingInterval  = 30 * time.Second
+	wsCloseWait     = 2 * time.Second
+	wsNormalClosure = 1000
+)
+
+type wsMessage struct {
+	opcode  byte
+	payload []byte
+	at      time.Time
+}
+
+// wsConn is the client side of a WebSocket connection. Writes are locked so
+// pongs sent by the reader do not interleave with other frames.
+type wsConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	mu     sync.Mutex
+}
+
+// parseWebSocketRequest returns the URL, the first line that is not blank or
+// a comment, and the messages, one per following line. A line starting with
+// `\#` sends a literal '#'.
+func parseWebSocketRequest(content string) (string, []string) {
+	lines := strings.Split(content, "\n")
+
+	start := 0
+	for start < len(lines) && isCommentOrBlank(lines[start]) {
+		start++
+	}
+	if start == len(lines) {
+		return "", nil
+	}
+
+	var messages []string
+	for _, line := range lines[start+1:] {
+		if isCommentOrBlank(line) {
+			continue
+		}
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(strings.TrimLeft(line, " \t"), `\#`) {
+			line = strings.Replace(line, `\#`, "#", 1)
+		}
+		messages = append(messages, line)
+	}
+	return strings.TrimSpace(lines[start]), messages
+}
+
+// executeWebSocketRequest connects to the URL on the first line, sends the
+// remaining lines as text messages and prints the messages the server sends
+// until options.Timeout elapses, the server closes or the run is canceled.
+// Pings are answered and sent on their own to keep the connection open.
+func executeWebSocketRequest(content string, options http.ExecuteOptions) error {
+	rawURL, messages := parseWebSocketRequest(content)
+	if rawURL == "" {
+		return EMPTY_WS_MESSAGE
+	}
+
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	w := options.Output()
+
+	ws, err := dialWebSocket(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	defer ws.conn.Close()
+
+	fmt.Fprintf(w, "Connected to %s\n", rawURL)
+
+	for _, message := range messages {
+		if err := ws.writeFrame(wsText, []byte(message)); err != nil {
+			return network.FormatError(err, tcpDialTimeout)
+		}
+		network.PrintMessage(w, timestamp(time.Now())+" Sent", []byte(message))
+	}
+
+	incoming := make(chan wsMessage)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		readErr <- ws.readMessages(incoming, done)
+	}()
+
+	listen := time.NewTimer(options.Timeout)
+	defer listen.Stop()
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case message := <-incoming:
+			if message.opcode == wsClose {
+				code, reason := closeStatus(message.payload)
+				fmt.Fprintf(w, "%s Closed by server: %d %s\n", timestamp(message.at), code, reason)
+				ws.writeFrame(wsClose, message.payload[:min(len(message.payload), 2)])
+				return nil
+			}
+			label := timestamp(message.at) + " Received"
+			if message.opcode == wsBinary {
+				label += " binary"
+			}
+			network.PrintMessage(w, label, message.payload)
+		case err := <-readErr:
+			if err == io.EOF {
+				fmt.Fprintln(w, "Connection closed by server")
+				return nil
+			}
+			return network.FormatError(err, options.Timeout)
+		case <-ping.C:
+			if err := ws.writeFrame(wsPing, nil); err != nil {
+				return network.FormatError(err, tcpDialTimeout)
+			}
+		case <-listen.C:
+			fmt.Fprintf(w, "Stopped listening after %v\n", options.Timeout)
+			return ws.close(incoming, readErr)
+		case <-ctx.Done():
+			ws.close(incoming, readErr)
+			return network.ErrCanceled
+		}
+	}
+}
+
+func timestamp(t time.Time) string {
+	return "[" + t.Format("15:04:05.000") + "]"
+}
+
+func dialWebSocket(ctx context.Context, rawURL string) (*wsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		return nil, fmt.Errorf("WebSocket URL must start with ws:// or wss://, got %s", rawURL)
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "wss" {
+			port = "443"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: tcpDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, network.FormatError(err, tcpDialTimeout)
+	}
+
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		handshakeCtx, cancel := context.WithTimeout(ctx, tcpDialTimeout)
+		defer cancel()
+		if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+			conn.Close()
+			return nil, network.FormatError(err, tcpDialTimeout)
+		}
+		conn = tlsConn
+	}
+
+	ws := &wsConn{conn: conn, reader: bufio.NewReader(conn)}
+	if err := ws.handshake(u); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
+
+// handshake sends the opening handshake and checks the server accepted the
+// upgrade, RFC 6455 section 4.
+func (ws *wsConn) handshake(u *url.URL) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate WebSocket key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	var request strings.Builder
+	fmt.Fprintf(&request, "GET %s HTTP/1.1\r\n", u.RequestURI())
+	fmt.Fprintf(&request, "Host: %s\r\n", u.Host)
+	fmt.Fprintf(&request, "User-Agent: %s\r\n", version.UserAgent())
+	request.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	fmt.Fprintf(&request, "Sec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n", key)
+
+	ws.conn.SetDeadline(time.Now().Add(tcpDialTimeout))
+	defer ws.conn.SetDeadline(time.Time{})
+
+	if _, err := io.WriteString(ws.conn, request.String()); err != nil {
+		return network.FormatError(err, tcpDialTimeout)
+	}
+
+	reader := textproto.NewReader(ws.reader)
+	status, err := reader.ReadLine()
+	if err != nil {
+		return network.FormatError(err, tcpDialTimeout)
+	}
+	headers, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return fmt.Errorf("invalid WebSocket handshake response: %w", err)
+	}
+
+	if fields := strings.Fields(status); len(fields) < 2 || fields[1] != "101" {
+		return fmt.Errorf("server refused the WebSocket upgrade: %s", status)
+	}
+
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	if headers.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return fmt.Errorf("server sent an invalid Sec-WebSocket-Accept")
+	}
+	return nil
+}
+
+// writeFrame sends a single masked frame, as clients must.
+func (ws *wsConn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = binary.BigEndian.AppendUint16(append(frame, 0x80|126), uint16(n))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, 0x80|127), uint64(n))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.conn.SetWriteDeadline(time.Now().Add(tcpDialTimeout))
+	_, err := ws.conn.Write(frame)
+	return err
+}
+
+// readFrame reads one frame, unmasking it if the server masked it.
+func (ws *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(ws.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > wsMaxMessage {
+		return false, 0, nil, fmt.Errorf("WebSocket frame of %s is too large", network.FormatBytes(int64(length)))
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// readMessages reassembles fragmented messages and passes them to incoming,
+// answering pings on the way. It returns after a close frame, an error or
+// once done is closed.
+func (ws *wsConn) readMessages(incoming chan<- wsMessage, done <-chan struct{}) error {
+	deliver := func(message wsMessage) bool {
+		select {
+		case incoming <- message:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	var message *wsMessage
+	for {
+		fin, opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return err
+		}
+
+		switch opcode {
+		case wsPing:
+			if err := ws.writeFrame(wsPong, payload); err != nil {
+				return err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			deliver(wsMessage{opcode: wsClose, payload: payload, at: time.Now()})
+			return nil
+		case wsText, wsBinary:
+			message = &wsMessage{opcode: opcode, at: time.Now()}
+		case wsContinuation:
+			if message == nil {
+				return fmt.Errorf("WebSocket continuation frame without a message")
+			}
+		default:
+			return fmt.Errorf("unknown WebSocket opcode %#x", opcode)
+		}
+
+		message.payload = append(message.payload, payload...)
+		if len(message.payload) > wsMaxMessage {
+			return fmt.Errorf("WebSocket message larger than %s", network.FormatBytes(wsMaxMessage))
+		}
+		if fin {
+			if !deliver(*message) {
+				return nil
+			}
+			message = nil
+		}
+	}
+}
+
+// close sends a normal closure and waits briefly for the server to answer
+// with its own close frame, RFC 6455 section 7.
+func (ws *wsConn) close(incoming <-chan wsMessage, readErr <-chan error) error {
+	payload := binary.BigEndian.AppendUint16(nil, wsNormalClosure)
+	if err := ws.writeFrame(wsClose, payload); err != nil {
+		return network.FormatError(err, tcpDialTimeout)
+	}
+
+	timeout := time.After(wsCloseWait)
+	for {
+		select {
+		case message := <-incoming:
+			if message.opcode == wsClose {
+				return nil
+			}
+		case <-readErr:
+			return nil
+		case <-timeout:
+			return nil
+		}
+	}
+}
+
+func closeStatus(payload []byte) (int, string) {
+	if len(payload) < 2 {
+		return 1005, "(no status)"
+	}
+	return int(binary.BigEndian.Uint16(payload)), string(payload[2:])
+}
@@ -0,0 +1,395 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"rq/request/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// serveWebSocket accepts one connection, answers the handshake with the
+// response built by respond and hands the connection to handle.
+func serveWebSocket(t *testing.T, respond func(key string) string, handle func(ws *wsConn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		tp := textproto.NewReader(reader)
+		if _, err := tp.ReadLine(); err != nil {
+			return
+		}
+		headers, err := tp.ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		fmt.Fprint(conn, respond(headers.Get("Sec-WebSocket-Key")))
+		if handle != nil {
+			handle(&wsConn{conn: conn, reader: reader})
+		}
+	}()
+
+	return "ws://" + ln.Addr().String() + "/socket"
+}
+
+func switching(key string) string {
+	return "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+}
+
+// writeServerFrame sends an unmasked frame, as servers do.
+func writeServerFrame(t *testing.T, ws *wsConn, fin bool, opcode byte, payload []byte) {
+	t.Helper()
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first, byte(len(payload))}
+	if _, err := ws.conn.Write(append(frame, payload...)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(key string) string
+		wantErr string
+	}{
+		{"accepted", switching, ""},
+		{"wrong accept key", func(key string) string {
+			return switching("other")
+		}, "invalid Sec-WebSocket-Accept"},
+		{"refused", func(key string) string {
+			return "HTTP/1.1 400 Bad Request\r\nContent-Length: 0\r\n\r\n"
+		}, "refused the WebSocket upgrade"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawURL := serveWebSocket(t, tt.respond, nil)
+
+			ws, err := dialWebSocket(t.Context(), rawURL)
+			if ws != nil {
+				ws.conn.Close()
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebSocketDialRejectsOtherSchemes(t *testing.T) {
+	for _, rawURL := range []string{"http://example.com", "ws://", "example.com"} {
+		if _, err := dialWebSocket(t.Context(), rawURL); err == nil {
+			t.Errorf("dialWebSocket(%q) succeeded", rawURL)
+		}
+	}
+}
+
+func TestWebSocketFrameEncoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		lengthByte byte
+		headerSize int
+	}{
+		{"empty", 0, 0, 2},
+		{"short", 125, 125, 2},
+		{"16-bit length", 126, 126, 4},
+		{"16-bit max", 0xFFFF, 126, 4},
+		{"64-bit length", 0x10000, 127, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			payload := bytes.Repeat([]byte("x"), tt.size)
+			go (&wsConn{conn: client}).writeFrame(wsBinary, payload)
+
+			raw := make([]byte, tt.headerSize+4+tt.size)
+			if _, err := io.ReadFull(server, raw); err != nil {
+				t.Fatal(err)
+			}
+
+			if raw[0] != 0x80|wsBinary {
+				t.Errorf("first byte = %#x, want FIN and binary opcode", raw[0])
+			}
+			if raw[1]&0x80 == 0 {
+				t.Error("client frame is not masked")
+			}
+			if got := raw[1] & 0x7F; got != tt.lengthByte {
+				t.Errorf("length byte = %d, want %d", got, tt.lengthByte)
+			}
+			switch tt.headerSize {
+			case 4:
+				if got := binary.BigEndian.Uint16(raw[2:4]); int(got) != tt.size {
+					t.Errorf("extended length = %d, want %d", got, tt.size)
+				}
+			case 10:
+				if got := binary.BigEndian.Uint64(raw[2:10]); int(got) != tt.size {
+					t.Errorf("extended length = %d, want %d", got, tt.size)
+				}
+			}
+
+			ws := &wsConn{reader: bufio.NewReader(bytes.NewReader(raw))}
+			fin, opcode, got, err := ws.readFrame()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !fin || opcode != wsBinary || !bytes.Equal(got, payload) {
+				t.Errorf("readFrame = %v, %#x, %d bytes, want the unmasked payload", fin, opcode, len(got))
+			}
+		})
+	}
+}
+
+func TestWebSocketReadMessages(t *testing.T) {
+	tests := []struct {
+		name   string
+		send   func(t *testing.T, ws *wsConn)
+		want   []wsMessage
+		wantFn func(t *testing.T, ws *wsConn)
+	}{
+		{
+			name: "single text frame",
+			send: func(t *testing.T, ws *wsConn) {
+				writeServerFrame(t, ws, true, wsText, []byte("hello"))
+			},
+			want: []wsMessage{{opcode: wsText, payload: []byte("hello")}},
+		},
+		{
+			name: "fragmented message",
+			send: func(t *testing.T, ws *wsConn) {
+				writeServerFrame(t, ws, false, wsText, []byte("hel"))
+				writeServerFrame(t, ws, false, wsContinuation, []byte("lo "))
+				writeServerFrame(t, ws, true, wsContinuation, []byte("world"))
+			},
+			want: []wsMessage{{opcode: wsText, payload: []byte("hello world")}},
+		},
+		{
+			name: "ping between fragments is answered",
+			send: func(t *testing.T, ws *wsConn) {
+				writeServerFrame(t, ws, false, wsBinary, []byte{1})
+				writeServerFrame(t, ws, true, wsPing, []byte("are you there"))
+				writeServerFrame(t, ws, true, wsContinuation, []byte{2})
+			},
+			want: []wsMessage{{opcode: wsBinary, payload: []byte{1, 2}}},
+			wantFn: func(t *testing.T, ws *wsConn) {
+				fin, opcode, payload, err := ws.readFrame()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !fin || opcode != wsPong || string(payload) != "are you there" {
+					t.Errorf("got %#x %q, want a pong echoing the ping", opcode, payload)
+				}
+			},
+		},
+		{
+			name: "close",
+			send: func(t *testing.T, ws *wsConn) {
+				writeServerFrame(t, ws, true, wsClose, append(binary.BigEndian.AppendUint16(nil, 1001), "going away"...))
+			},
+			want: []wsMessage{{opcode: wsClose, payload: append(binary.BigEndian.AppendUint16(nil, 1001), "going away"...)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			ws := &wsConn{conn: client, reader: bufio.NewReader(client)}
+			peer := &wsConn{conn: server, reader: bufio.NewReader(server)}
+
+			incoming := make(chan wsMessage)
+			done := make(chan struct{})
+			defer close(done)
+			go ws.readMessages(incoming, done)
+
+			sent := make(chan struct{})
+			go func() {
+				defer close(sent)
+				tt.send(t, peer)
+			}()
+			if tt.wantFn != nil {
+				tt.wantFn(t, peer)
+			}
+
+			for _, want := range tt.want {
+				select {
+				case got := <-incoming:
+					if got.opcode != want.opcode || !bytes.Equal(got.payload, want.payload) {
+						t.Errorf("got %#x %q, want %#x %q", got.opcode, got.payload, want.opcode, want.payload)
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatal("no message received")
+				}
+			}
+			<-sent
+		})
+	}
+}
+
+func TestWebSocketReadMessagesRejectsStrayContinuation(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	ws := &wsConn{conn: client, reader: bufio.NewReader(client)}
+	go writeServerFrame(t, &wsConn{conn: server}, true, wsContinuation, []byte("x"))
+
+	if err := ws.readMessages(make(chan wsMessage), make(chan struct{})); err == nil {
+		t.Fatal("expected an error for a continuation frame without a message")
+	}
+}
+
+func TestCloseStatus(t *testing.T) {
+	tests := []struct {
+		payload []byte
+		code    int
+		reason  string
+	}{
+		{nil, 1005, "(no status)"},
+		{binary.BigEndian.AppendUint16(nil, 1000), 1000, ""},
+		{append(binary.BigEndian.AppendUint16(nil, 1011), "oops"...), 1011, "oops"},
+	}
+	for _, tt := range tests {
+		code, reason := closeStatus(tt.payload)
+		if code != tt.code || reason != tt.reason {
+			t.Errorf("closeStatus(%v) = %d %q, want %d %q", tt.payload, code, reason, tt.code, tt.reason)
+		}
+	}
+}
+
+func TestParseWebSocketRequest(t *testing.T) {
+	rawURL, messages := parseWebSocketRequest("# echo\n\nws://localhost/echo\nhello\n# skipped\n\\#literal\n")
+	if rawURL != "ws://localhost/echo" {
+		t.Errorf("url = %q", rawURL)
+	}
+	if strings.Join(messages, "|") != "hello|#literal" {
+		t.Errorf("messages = %q", messages)
+	}
+}
+
+func TestExecuteWebSocketRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(ws *wsConn)
+		want   []string
+	}{
+		{
+			name: "server closes",
+			handle: func(ws *wsConn) {
+				_, _, payload, err := ws.readFrame()
+				if err != nil {
+					return
+				}
+				writeServerFrame(t, ws, true, wsText, payload)
+				writeServerFrame(t, ws, true, wsClose, append(binary.BigEndian.AppendUint16(nil, 1000), "bye"...))
+				ws.readFrame()
+			},
+			want: []string{"Connected to", "Sent", "Received", "Closed by server: 1000 bye"},
+		},
+		{
+			name: "client closes after the timeout",
+			handle: func(ws *wsConn) {
+				for {
+					_, opcode, payload, err := ws.readFrame()
+					if err != nil {
+						return
+					}
+					if opcode == wsClose {
+						writeServerFrame(t, ws, true, wsClose, payload)
+						return
+					}
+				}
+			},
+			want: []string{"Connected to", "Stopped listening after"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawURL := serveWebSocket(t, switching, tt.handle)
+
+			var out bytes.Buffer
+			options := http.ExecuteOptions{Writer: &out, Timeout: 200 * time.Millisecond}
+			if err := executeWebSocketRequest(rawURL+"\nping\n", options); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWebSocketHandshakeRequest(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	u, _ := url.Parse("ws://example.com/chat?room=1")
+	errs := make(chan error, 1)
+	go func() {
+		errs <- (&wsConn{conn: client, reader: bufio.NewReader(client)}).handshake(u)
+	}()
+
+	tp := textproto.NewReader(bufio.NewReader(server))
+	line, _ := tp.ReadLine()
+	headers, _ := tp.ReadMIMEHeader()
+	if line != "GET /chat?room=1 HTTP/1.1" {
+		t.Errorf("request line = %q", line)
+	}
+	for key, want := range map[string]string{"Host": "example.com", "Upgrade": "websocket", "Connection": "Upgrade", "Sec-WebSocket-Version": "13"} {
+		if got := headers.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if nonce, err := base64.StdEncoding.DecodeString(headers.Get("Sec-WebSocket-Key")); err != nil || len(nonce) != 16 {
+		t.Errorf("Sec-WebSocket-Key is not a base64 16-byte nonce: %q", headers.Get("Sec-WebSocket-Key"))
+	}
+
+	fmt.Fprint(server, switching(headers.Get("Sec-WebSocket-Key")))
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Snapshot is a recorded response stored inside the dock under .rq/snapshots.
+// The body is kept byte for byte, base64 encoded in the JSON file, so binary
+// and compressed responses replay unchanged.
+type Snapshot struct {
+	Name       string              `json:"name"`
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	Path       string              `json:"path"`
+	StatusCode int                 `json:"status_code"`
+	Status     string              `json:"status"`
+	Headers    map[string][]string `json:"headers"`
+	Body       []byte              `json:"body"`
+	RecordedAt time.Time           `json:"recorded_at"`
+}
+
+func New(name, method, rawURL string, statusCode int, status string, headers map[string][]string, body []byte) *Snapshot {
+	return &Snapshot{
+		Name:       name,
+		Method:     strings.ToUpper(method),
+		URL:        rawURL,
+		Path:       pathOf(rawURL),
+		StatusCode: statusCode,
+		Status:     status,
+		Headers:    headers,
+		Body:       body,
+		RecordedAt: time.Now(),
+	}
+}
+
+// Key identifies a snapshot by method and path, which is how the mock server looks them up.
+func (snap *Snapshot) Key() string {
+	return Key(snap.Method, snap.Path)
+}
+
+func Key(method, path string) string {
+	if path == "" {
+		path = "/"
+	}
+	return strings.ToUpper(method) + " " + path
+}
+
+func Dir(dockPath string) string {
+	return filepath.Join(dockPath, ".rq", "snapshots")
+}
+
+func Save(dockPath string, snap *Snapshot) error {
+	dir := Dir(dockPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, fileName(snap.Name)), content, 0644)
+}
+
+// Latest returns the last recorded snapshot for the named request, or nil if none exists.
+func Latest(dockPath, name string) (*Snapshot, error) {
+	content, err := os.ReadFile(filepath.Join(Dir(dockPath), fileName(name)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(content, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot for %s: %w", name, err)
+	}
+
+	return &snap, nil
+}
+
+// Load reads every snapshot in the dock, keyed by method and path.
+// When several requests hit the same endpoint the most recent one wins.
+func Load(dockPath string) (map[string]*Snapshot, error) {
+	res := make(map[string]*Snapshot)
+
+	entries, err := os.ReadDir(Dir(dockPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+		return res, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(Dir(dockPath), entry.Name()))
+		if err != nil {
+			return res, fmt.Errorf("failed to read snapshot %s: %w", entry.Name(), err)
+		}
+
+		var snap Snapshot
+		if err := json.Unmarshal(content, &snap); err != nil {
+			return res, fmt.Errorf("invalid snapshot %s: %w", entry.Name(), err)
+		}
+
+		if current, ok := res[snap.Key()]; ok && current.RecordedAt.After(snap.RecordedAt) {
+			continue
+		}
+		res[snap.Key()] = &snap
+	}
+
+	return res, nil
+}
+
+func fileName(name string) string {
+	name = filepath.ToSlash(name)
+	name = strings.Trim(name, "/")
+	replacer := strings.NewReplacer("/", "__", " ", "_", ":", "_")
+	return replacer.Replace(name) + ".json"
+}
+
+func pathOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Path == "" {
+		return "/"
+	}
+	return parsed.Path
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLatest(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"text", []byte(`{"id": 1}`)},
+		{"binary", []byte{0x1f, 0x8b, 0x00, 0xff, 0xfe}},
+		{"empty", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			snap := New("users/get", "get", "https://api.example.com/users?page=2", 200, "200 OK", map[string][]string{"Content-Type": {"application/json"}}, tt.body)
+			if err := Save(dir, snap); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Latest(dir, "users/get")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil {
+				t.Fatal("snapshot not found")
+			}
+			if !bytes.Equal(got.Body, tt.body) {
+				t.Errorf("body = %v, want %v", got.Body, tt.body)
+			}
+			if got.Method != "GET" || got.Path != "/users" || got.StatusCode != 200 {
+				t.Errorf("got %s %s %d", got.Method, got.Path, got.StatusCode)
+			}
+			if got.Key() != "GET /users" {
+				t.Errorf("key = %q", got.Key())
+			}
+		})
+	}
+}
+
+func TestLatestMissing(t *testing.T) {
+	snap, err := Latest(t.TempDir(), "nothing")
+	if err != nil || snap != nil {
+		t.Fatalf("Latest = %v, %v, want nil, nil", snap, err)
+	}
+}
+
+func TestUnmarshalRejectsTextBody(t *testing.T) {
+	text := `{"name":"old","method":"GET","path":"/","status_code":200,"body":"plain text"}`
+
+	var snap Snapshot
+	if err := json.Unmarshal([]byte(text), &snap); err == nil {
+		t.Errorf("decoding a plain text body succeeded with %q", snap.Body)
+	}
+}
+
+func TestLoadKeepsMostRecent(t *testing.T) {
+	dir := t.TempDir()
+
+	older := New("a", "GET", "http://x/items", 200, "200 OK", nil, []byte("old"))
+	older.RecordedAt = time.Now().Add(-time.Hour)
+	newer := New("b", "GET", "http://x/items", 201, "201 Created", nil, []byte("new"))
+
+	for _, snap := range []*Snapshot{newer, older} {
+		if err := Save(dir, snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(Dir(dir), "notes.txt"), []byte("ignored"), 0644)
+
+	snapshots, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("got %d snapshots, want 1", len(snapshots))
+	}
+	if got := snapshots["GET /items"]; got == nil || string(got.Body) != "new" {
+		t.Errorf("got %+v, want the newer snapshot", got)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"users", "users.json"},
+		{"users/get", "users__get.json"},
+		{"/auth/log in/", "auth__log_in.json"},
+		{"mock/get_%2Fa%3A", "mock__get_%2Fa%3A.json"},
+	}
+	for _, tt := range tests {
+		if got := fileName(tt.name); got != tt.want {
+			t.Errorf("fileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
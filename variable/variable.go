@@ -5,8 +5,11 @@ package variable
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"regexp"
+	"rq/directive"
+	"sort"
 	"strings"
 )
 
@@ -19,13 +22,15 @@ type VariableResolver struct {
 	env       map[string]string
 	functions map[string]func(...string) (string, error)
 	re        *regexp.Regexp
+	accessed  map[string]bool // Keys of env read while resolving
 }
 
 func NewVariableResolver(env map[string]string) *VariableResolver {
 	resolver := &VariableResolver{
 		env:       env,
-		re:        regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`),
+		re:        regexp.MustCompile(`\$\$\{|\$\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}|\{\{\s*(.*?)\s*\}\}`),
 		functions: make(map[string]func(...string) (string, error)),
+		accessed:  make(map[string]bool),
 	}
 
 	resolver.RegisterFunc("uuid", generateUUID)
@@ -39,39 +44,72 @@ func NewVariableResolver(env map[string]string) *VariableResolver {
 	return resolver
 }
 
-func (resolver *VariableResolver) Resolve(value string) (string, error) {
-	matches := resolver.re.FindAllStringSubmatch(value, -1)
+// placeholder returns the expression of a match. ${NAME} is a plain
+// variable lookup, {{ }} also allows function calls, and $${ is an escaped
+// literal ${ with no expression.
+func (resolver *VariableResolver) placeholder(match string) (string, bool) {
+	submatches := resolver.re.FindStringSubmatch(match)
+	switch {
+	case submatches[1] != "":
+		return submatches[1], true
+	case strings.HasPrefix(match, "{{"):
+		return strings.TrimSpace(submatches[2]), true
+	}
+	return "", false
+}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			expression := strings.TrimSpace(match[1])
-			if expression == "" {
-				return "", fmt.Errorf("empty variable expression")
-			}
+func (resolver *VariableResolver) Resolve(value string) (string, error) {
+	for _, match := range resolver.re.FindAllString(value, -1) {
+		expression, ok := resolver.placeholder(match)
+		if !ok {
+			continue
+		}
+		if expression == "" {
+			return "", fmt.Errorf("empty variable expression")
+		}
 
-			_, err := resolver.evaluateExpression(expression)
-			if err != nil {
-				return "", fmt.Errorf("error in expression '{{%s}}': %w", expression, err)
-			}
+		_, err := resolver.evaluateExpression(expression)
+		if err != nil {
+			return "", fmt.Errorf("error in expression '%s': %w", match, err)
 		}
 	}
 
 	result := resolver.re.ReplaceAllStringFunc(value, func(match string) string {
-		submatches := resolver.re.FindStringSubmatch(match)
-		if len(submatches) > 1 {
-			expression := strings.TrimSpace(submatches[1])
-			val, err := resolver.evaluateExpression(expression)
-			if err != nil {
-				return match
-			}
-			return val
+		expression, ok := resolver.placeholder(match)
+		if !ok {
+			return "${"
+		}
+		val, err := resolver.evaluateExpression(expression)
+		if err != nil {
+			return match
 		}
-		return match
+		return val
 	})
 
 	return result, nil
 }
 
+// ResolvePartial resolves every placeholder it can and leaves the others in
+// place, returning their expressions instead of failing on the first one.
+func (resolver *VariableResolver) ResolvePartial(value string) (string, []string) {
+	var unresolved []string
+
+	result := resolver.re.ReplaceAllStringFunc(value, func(match string) string {
+		expression, ok := resolver.placeholder(match)
+		if !ok {
+			return "${"
+		}
+		val, err := resolver.evaluateExpression(expression)
+		if err != nil {
+			unresolved = append(unresolved, expression)
+			return match
+		}
+		return val
+	})
+
+	return result, unresolved
+}
+
 func (resolver *VariableResolver) ResolveFile(path string) (string, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return "", fmt.Errorf("file not found: %s", path)
@@ -82,7 +120,40 @@ func (resolver *VariableResolver) ResolveFile(path string) (string, error) {
 		return "", fmt.Errorf("failed to read file %s: %w", path, err)
 	}
 
-	return resolver.Resolve(string(file))
+	content, err := resolver.DefineLocals(string(file))
+	if err != nil {
+		return "", err
+	}
+
+	return resolver.Resolve(content)
+}
+
+var setPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// DefineLocals evaluates the "@set NAME = EXPR" lines before the request
+// line once, in order, and makes NAME available to the rest of the file. The
+// directive lines are blanked in the returned content.
+func (resolver *VariableResolver) DefineLocals(content string) (string, error) {
+	content, directives := directive.Extract(content, "set")
+
+	for i, d := range directives {
+		match := setPattern.FindStringSubmatch(d.Args)
+		if match == nil {
+			return "", fmt.Errorf("invalid @set at line %d, expected NAME = EXPR", d.Line)
+		}
+
+		value, err := resolver.Resolve(match[2])
+		if err != nil {
+			return "", fmt.Errorf("@set %s at line %d: %w", match[1], d.Line, err)
+		}
+
+		if i == 0 {
+			resolver.env = maps.Clone(resolver.env)
+		}
+		resolver.env[match[1]] = value
+	}
+
+	return content, nil
 }
 
 func (resolver *VariableResolver) evaluateExpression(expression string) (string, error) {
@@ -112,6 +183,7 @@ func (resolver *VariableResolver) evaluateExpression(expression string) (string,
 	}
 
 	if variable, ok := resolver.env[expression]; ok {
+		resolver.accessed[expression] = true
 		return variable, nil
 	} else if isString(expression) {
 		return expression[1 : len(expression)-1], nil
@@ -120,6 +192,17 @@ func (resolver *VariableResolver) evaluateExpression(expression string) (string,
 	return "", fmt.Errorf("variable '%s' not found", expression)
 }
 
+// AccessedKeys lists, sorted, the variables read so far by Resolve,
+// ResolvePartial and DefineLocals.
+func (resolver *VariableResolver) AccessedKeys() []string {
+	keys := make([]string, 0, len(resolver.accessed))
+	for key := range resolver.accessed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func isString(expression string) bool {
 	re := regexp.MustCompile(`^'[^']*'$|^"[^"]*"$`)
 	return re.MatchString(expression)
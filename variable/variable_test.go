@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package variable
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefineLocals(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "used in url and body",
+			content: "@set team = core\nPOST {{BASE_URL}}/teams/{{team}}\n\n{\"team\": \"${team}\"}\n",
+			want:    "\nPOST http://localhost/teams/core\n\n{\"team\": \"core\"}\n",
+		},
+		{
+			name:    "later sets see earlier ones",
+			content: "@set id = 42\n@set path = /users/{{id}}\nGET {{BASE_URL}}{{path}}\n",
+			want:    "\n\nGET http://localhost/users/42\n",
+		},
+		{
+			name:    "shadows the config",
+			content: "@set BASE_URL = https://other.test\nGET {{BASE_URL}}/\n",
+			want:    "\nGET https://other.test/\n",
+		},
+		{
+			name:    "only before the request line",
+			content: "GET {{BASE_URL}}/\n\n@set id = 1\n",
+			want:    "GET http://localhost/\n\n@set id = 1\n",
+		},
+		{name: "missing equals", content: "@set id 42\nGET /\n", wantErr: "invalid @set at line 1, expected NAME = EXPR"},
+		{name: "invalid name", content: "@set 2id = 1\nGET /\n", wantErr: "invalid @set at line 1"},
+		{name: "unknown variable", content: "\n@set id = {{MISSING}}\nGET /\n", wantErr: "@set id at line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"BASE_URL": "http://localhost"}
+			resolver := NewVariableResolver(env)
+
+			content, err := resolver.DefineLocals(tt.content)
+			if err == nil {
+				content, err = resolver.Resolve(content)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content != tt.want {
+				t.Errorf("content = %q, want %q", content, tt.want)
+			}
+			if env["BASE_URL"] != "http://localhost" || len(env) != 1 {
+				t.Errorf("DefineLocals changed the caller's env: %v", env)
+			}
+		})
+	}
+}
+
+func TestDefineLocalsEvaluatesOnce(t *testing.T) {
+	resolver := NewVariableResolver(map[string]string{})
+	content, err := resolver.DefineLocals("@set id = {{uuid()}}\nPUT /users/{{id}}\n\n{\"id\": \"{{id}}\"}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err = resolver.Resolve(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`).FindAllString(content, -1)
+	if len(ids) != 2 || ids[0] != ids[1] {
+		t.Errorf("@set id should be one uuid used twice, got %q", content)
+	}
+}
+
+func TestResolveSyntaxes(t *testing.T) {
+	env := map[string]string{"BASE_URL": "http://localhost", "TOKEN": "s3cr3t", "USER": "ada"}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "braces", value: "{{ BASE_URL }}/users", want: "http://localhost/users"},
+		{name: "dollar", value: "${BASE_URL}/users", want: "http://localhost/users"},
+		{name: "dollar with spaces", value: "${ TOKEN }", want: "s3cr3t"},
+		{
+			name:  "both in one file",
+			value: "GET ${BASE_URL}/users/{{USER}}\nAuthorization: Bearer ${TOKEN}\n\n{\"user\": \"{{ join(USER, TOKEN, '-') }}\"}\n",
+			want:  "GET http://localhost/users/ada\nAuthorization: Bearer s3cr3t\n\n{\"user\": \"ada-s3cr3t\"}\n",
+		},
+		{name: "escaped dollar", value: "echo $${HOME} ${USER}", want: "echo ${HOME} ada"},
+		{name: "escape without a name", value: "cost: $${", want: "cost: ${"},
+		{name: "lone dollar", value: "price: $5 {USER}", want: "price: $5 {USER}"},
+		{name: "dollar takes no function calls", value: "${uuid()}", want: "${uuid()}"},
+		{name: "missing dollar variable", value: "${MISSING}", wantErr: "error in expression '${MISSING}': variable 'MISSING' not found"},
+		{name: "missing braces variable", value: "{{MISSING}}", wantErr: "error in expression '{{MISSING}}': variable 'MISSING' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewVariableResolver(env).Resolve(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Resolve() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePartialSyntaxes(t *testing.T) {
+	resolver := NewVariableResolver(map[string]string{"USER": "ada"})
+
+	got, unresolved := resolver.ResolvePartial("${USER} ${HOST} {{PORT}} $${USER}")
+	if want := "ada ${HOST} {{PORT}} ${USER}"; got != want {
+		t.Errorf("ResolvePartial() = %q, want %q", got, want)
+	}
+	if want := []string{"HOST", "PORT"}; !slices.Equal(unresolved, want) {
+		t.Errorf("ResolvePartial() unresolved = %q, want %q", unresolved, want)
+	}
+}
+
+func TestAccessedKeys(t *testing.T) {
+	env := map[string]string{"HOST": "api.test", "USER": "ada", "TOKEN": "secret", "UNUSED": "x", "SEP": "-"}
+
+	tests := []struct {
+		name    string
+		content string
+		partial bool
+		want    []string
+	}{
+		{name: "nothing read", content: "GET http://localhost/users\n", want: []string{}},
+		{name: "both syntaxes", content: "GET http://{{ HOST }}/users/${USER}\n", want: []string{"HOST", "USER"}},
+		{name: "read twice", content: "{{USER}} {{USER}}", want: []string{"USER"}},
+		{name: "function arguments", content: "{{ base64(join(USER, TOKEN, SEP)) }}", want: []string{"SEP", "TOKEN", "USER"}},
+		{name: "string literals", content: "{{ base64('HOST') }} $${USER}", want: []string{}},
+		{name: "@set", content: "@set AUTH = {{ base64(TOKEN) }}\nGET http://localhost\nAuthorization: {{AUTH}}\n", want: []string{"AUTH", "TOKEN"}},
+		{name: "partial", content: "{{HOST}} {{MISSING}}", partial: true, want: []string{"HOST"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewVariableResolver(env)
+			if tt.partial {
+				resolver.ResolvePartial(tt.content)
+			} else {
+				content, err := resolver.DefineLocals(tt.content)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := resolver.Resolve(content); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := resolver.AccessedKeys(); !slices.Equal(got, tt.want) {
+				t.Errorf("AccessedKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package version
+
+import (
+	"fmt"
+
+	"github.com/marcomit/args"
+)
+
+// Version is overridden at build time with -ldflags "-X rq/version.Version=...".
+var Version = "1.0.0"
+
+func UserAgent() string {
+	return "rq/" + Version
+}
+
+func Print() {
+	fmt.Printf("rq %s\n", Version)
+}
+
+func Setup(app *args.Parser) {
+	app.Command("version", "Prints the rq version").
+		Action(func(r *args.Result) error {
+			Print()
+			return nil
+		})
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2025 Marco Menegazzi
+// Licensed under the BSD 3-Clause License.
+// See the LICENSE file in the project root for full license information.
+package version
+
+import "testing"
+
+func TestUserAgent(t *testing.T) {
+	defer func(original string) { Version = original }(Version)
+
+	for _, v := range []string{"1.0.0", "2.3.1-rc.1", "dev"} {
+		Version = v
+		if got := UserAgent(); got != "rq/"+v {
+			t.Errorf("UserAgent() with Version %q = %q", v, got)
+		}
+	}
+}